@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"maps"
+	"net"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -34,8 +38,14 @@ type ClickHouseClusterSpec struct {
 	Shards *int32 `json:"shards"`
 
 	// Reference to the KeeperCluster that is used for ClickHouse coordination.
+	// Either keeperClusterRef or coordination.external must be specified.
+	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keeper Cluster Reference"
-	KeeperClusterRef *corev1.LocalObjectReference `json:"keeperClusterRef"`
+	KeeperClusterRef *KeeperClusterReference `json:"keeperClusterRef,omitempty"`
+
+	// Coordination configures a coordination service not managed by the operator.
+	// +optional
+	Coordination CoordinationSpec `json:"coordination,omitempty"`
 
 	// Parameters passed to the ClickHouse pod spec.
 	// +optional
@@ -66,6 +76,18 @@ type ClickHouseClusterSpec struct {
 	// +optional
 	// +kubebuilder:default:="cluster.local"
 	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// Monitoring configures Prometheus Operator resources created for the ClickHouse cluster.
+	// +optional
+	Monitoring ClickHouseMonitoringSpec `json:"monitoring,omitempty"`
+
+	// Client-facing Services load balancing connections across Ready replicas.
+	// +optional
+	Services ClickHouseServicesSpec `json:"services,omitempty"`
+
+	// NetworkPolicy restricts access to the interserver and management ports to the cluster replicas and the operator.
+	// +optional
+	NetworkPolicy NetworkPolicySpec `json:"networkPolicy,omitempty"`
 }
 
 // WithDefaults sets default values for ClickHouseClusterSpec fields.
@@ -90,7 +112,32 @@ func (s *ClickHouseClusterSpec) WithDefaults() {
 				},
 			},
 		},
+		Services: ClickHouseServicesSpec{
+			Cluster: ServiceTemplateSpec{
+				Enabled: ptr.To(true),
+				Type:    corev1.ServiceTypeClusterIP,
+			},
+			Shard: ServiceTemplateSpec{
+				Enabled: ptr.To(false),
+				Type:    corev1.ServiceTypeClusterIP,
+			},
+			Gateway: GatewayRouteSpec{
+				TLSMode: GatewayTLSModeTerminate,
+			},
+		},
 		Settings: ClickHouseSettings{
+			ShutdownDrainTimeoutSeconds: ptr.To[int32](DefaultClickHouseShutdownDrainTimeoutSeconds),
+			DefaultDatabaseMigration:    DefaultDatabaseMigrationBlock,
+			DistributedDDL: DistributedDDLSpec{
+				StaleTaskThresholdSeconds: DefaultDistributedDDLStaleTaskThresholdSeconds,
+			},
+			ReplicaHealth: ReplicaHealthSpec{
+				MaxReplicationDelaySeconds: DefaultMaxReplicationDelaySeconds,
+				CatchUpMaxDelaySeconds:     DefaultCatchUpMaxDelaySeconds,
+				CatchUpMaxQueueSize:        DefaultCatchUpMaxQueueSize,
+				CatchUpTimeoutSeconds:      DefaultCatchUpTimeoutSeconds,
+				MaxDistributedDDLBacklog:   DefaultMaxDistributedDDLBacklog,
+			},
 			Logger: LoggerConfig{
 				LogToFile: new(true),
 				Level:     "trace",
@@ -98,6 +145,13 @@ func (s *ClickHouseClusterSpec) WithDefaults() {
 				Count:     DefaultMaxLogFiles,
 			},
 		},
+		Monitoring: ClickHouseMonitoringSpec{
+			Alerts: ClickHouseAlertsSpec{
+				AlertsSpec:              AlertsSpec{For: DefaultAlertFor},
+				ReplicationDelaySeconds: DefaultAlertReplicationDelaySeconds,
+				PartsPerPartition:       DefaultAlertPartsPerPartition,
+			},
+		},
 	}
 
 	if err := controllerutil.ApplyDefault(s, defaultSpec); err != nil {
@@ -113,6 +167,327 @@ func (s *ClickHouseClusterSpec) WithDefaults() {
 	}
 }
 
+// KeeperClusterReference identifies the KeeperCluster used for ClickHouse coordination.
+type KeeperClusterReference struct {
+	// Name of the KeeperCluster.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the KeeperCluster. Defaults to the namespace of the ClickHouseCluster.
+	// KeeperCluster in another namespace must list the ClickHouseCluster namespace in its allowedNamespaces.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CoordinationSpec defines the coordination service used for replication and distributed DDL.
+type CoordinationSpec struct {
+	// External configures an existing ZooKeeper or ClickHouse Keeper ensemble not managed by the operator.
+	// Mutually exclusive with keeperClusterRef.
+	// +optional
+	External *ExternalKeeperSpec `json:"external,omitempty"`
+
+	// Root is the chroot path of the cluster in the coordination service, isolating it from other clusters.
+	// Defaults to "/clusters/<namespace>/<name>" for KeeperCluster from another namespace, and to the
+	// coordination service root otherwise. The operator creates the root in the referenced KeeperCluster,
+	// the root in the external coordination service must exist before ClickHouse is started.
+	// Changing the root of an existing cluster loses access to its replication metadata.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(/[^/]+)+$`
+	Root string `json:"root,omitempty"`
+}
+
+// Validate validates the CoordinationSpec configuration.
+func (s *CoordinationSpec) Validate() error {
+	if s.Root != "" && (!strings.HasPrefix(s.Root, "/") || strings.HasSuffix(s.Root, "/") || strings.Contains(s.Root, "//")) {
+		return fmt.Errorf("root %q must start with '/' and must not contain empty path segments", s.Root)
+	}
+
+	if s.External != nil {
+		if err := s.External.Validate(); err != nil {
+			return fmt.Errorf("external: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ExternalKeeperSpec describes a ZooKeeper or ClickHouse Keeper ensemble not managed by the operator.
+type ExternalKeeperSpec struct {
+	// Nodes of the ensemble in host:port format.
+	// +kubebuilder:validation:MinItems=1
+	Nodes []string `json:"nodes"`
+
+	// Secure enables TLS for connections to the nodes.
+	// Server certificates are verified using settings.tls.caBundle if specified, the CA bundle of
+	// settings.tls.serverCertSecret if TLS is enabled, or the system trusted CA bundle otherwise.
+	// +optional
+	Secure bool `json:"secure,omitempty"`
+
+	// AuthSecret selects a Secret key holding the digest authentication identity in "user:password" format.
+	// Operator generated identity is used if not specified.
+	// +optional
+	AuthSecret *SecretKeySelector `json:"authSecret,omitempty"`
+}
+
+// Validate validates the ExternalKeeperSpec configuration.
+func (s *ExternalKeeperSpec) Validate() error {
+	if len(s.Nodes) == 0 {
+		return errors.New("at least one node must be specified")
+	}
+
+	for _, node := range s.Nodes {
+		if _, _, err := SplitKeeperNode(node); err != nil {
+			return err
+		}
+	}
+
+	if s.AuthSecret != nil && (s.AuthSecret.Name == "" || s.AuthSecret.Key == "") {
+		return errors.New("authSecret name and key must not be empty")
+	}
+
+	return nil
+}
+
+// SplitKeeperNode splits the external Keeper node address in host:port format into host and port.
+func SplitKeeperNode(node string) (string, int32, error) {
+	host, portStr, err := net.SplitHostPort(node)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid node %q: %w", node, err)
+	}
+
+	if host == "" {
+		return "", 0, fmt.Errorf("invalid node %q: host must not be empty", node)
+	}
+
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid node %q: port must be a number between 1 and 65535", node)
+	}
+
+	return host, int32(port), nil
+}
+
+// ClickHouseServicesSpec defines client-facing Services of the ClickHouse cluster.
+type ClickHouseServicesSpec struct {
+	// Cluster configures the Service load balancing connections across Ready replicas of all shards.
+	// Enabled by default.
+	// +optional
+	Cluster ServiceTemplateSpec `json:"cluster,omitempty"`
+
+	// Shard configures per-shard Services load balancing connections across Ready replicas of a single shard.
+	// Disabled by default.
+	// +optional
+	Shard ServiceTemplateSpec `json:"shard,omitempty"`
+
+	// Ingress exposes the HTTP interface of the cluster Service outside of the Kubernetes cluster.
+	// +optional
+	Ingress IngressSpec `json:"ingress,omitempty"`
+
+	// Gateway exposes the HTTP interface of the cluster Service with a Gateway API route.
+	// +optional
+	Gateway GatewayRouteSpec `json:"gateway,omitempty"`
+}
+
+// IngressSpec describes an Ingress routing HTTP requests to the ClickHouse cluster Service.
+type IngressSpec struct {
+	// Enabled indicates whether the operator should create the Ingress.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ClassName is the name of the IngressClass. Cluster default IngressClass is used if not set.
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
+	// Host is the fully qualified domain name routed to ClickHouse. All hosts are routed if empty.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// TLSSecret is a reference to a TLS Secret used by the Ingress controller to terminate TLS.
+	// +optional
+	TLSSecret *corev1.LocalObjectReference `json:"tlsSecret,omitempty"`
+
+	// Additional labels added to the Ingress.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Additional annotations added to the Ingress. Use them to configure the Ingress controller.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayTLSMode defines where TLS connections routed by the Gateway are terminated.
+// +kubebuilder:validation:Enum:=Terminate;Passthrough
+type GatewayTLSMode string
+
+const (
+	// GatewayTLSModeTerminate routes HTTP requests with HTTPRoute. TLS is terminated by the Gateway listener.
+	GatewayTLSModeTerminate GatewayTLSMode = "Terminate"
+	// GatewayTLSModePassthrough routes TLS connections to the ClickHouse HTTPS port with TLSRoute.
+	GatewayTLSModePassthrough GatewayTLSMode = "Passthrough"
+)
+
+// GatewayRouteSpec describes a Gateway API route to the ClickHouse cluster Service.
+type GatewayRouteSpec struct {
+	// Enabled indicates whether the operator should create the route.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// ParentRefs are the Gateways the route is attached to.
+	// +optional
+	ParentRefs []GatewayParentReference `json:"parentRefs,omitempty"`
+
+	// Hostnames matched by the route.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// TLSMode defines whether TLS is terminated by the Gateway (HTTPRoute) or passed through to ClickHouse (TLSRoute).
+	// Passthrough requires the httpSecure protocol to be enabled.
+	// +optional
+	// +kubebuilder:default:=Terminate
+	TLSMode GatewayTLSMode `json:"tlsMode,omitempty"`
+
+	// BackendHostname is the hostname the Gateway uses to verify the ClickHouse server certificate
+	// if HTTPRoute routes requests to the httpSecure port. It happens if the plain http protocol is disabled.
+	// Defaults to the DNS name of the cluster Service.
+	// +optional
+	BackendHostname string `json:"backendHostname,omitempty"`
+
+	// Additional labels added to the route.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Additional annotations added to the route.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayParentReference identifies a Gateway the route is attached to.
+type GatewayParentReference struct {
+	// Name of the Gateway.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. Defaults to the namespace of the cluster.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the Gateway listener.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// Validate validates the GatewayRouteSpec configuration.
+func (s *GatewayRouteSpec) Validate() error {
+	if s.Enabled && len(s.ParentRefs) == 0 {
+		return errors.New("at least one parentRef must be specified when gateway route is enabled")
+	}
+
+	return nil
+}
+
+// DefaultDatabaseMigrationPolicy defines how the existing non-Replicated `default` database is migrated.
+// +kubebuilder:validation:Enum:=Block;Rename;Drop
+type DefaultDatabaseMigrationPolicy string
+
+const (
+	// DefaultDatabaseMigrationBlock keeps the database with tables untouched and reports the SchemaInSync condition.
+	DefaultDatabaseMigrationBlock DefaultDatabaseMigrationPolicy = "Block"
+	// DefaultDatabaseMigrationRename renames the Atomic database with its tables and creates a new Replicated one.
+	DefaultDatabaseMigrationRename DefaultDatabaseMigrationPolicy = "Rename"
+	// DefaultDatabaseMigrationDrop drops the database with its tables and creates a new Replicated one.
+	DefaultDatabaseMigrationDrop DefaultDatabaseMigrationPolicy = "Drop"
+)
+
+// ClickHouseMonitoringSpec defines integration of the ClickHouse cluster with Prometheus Operator.
+type ClickHouseMonitoringSpec struct {
+	// PodMonitor configures the PodMonitor that scrapes metrics of all cluster replicas.
+	// Ignored if Prometheus Operator CRDs are not installed in the Kubernetes cluster.
+	// +optional
+	PodMonitor PodMonitorSpec `json:"podMonitor,omitempty"`
+
+	// Alerts configures the PrometheusRule with the recommended alerts for the cluster.
+	// Ignored if Prometheus Operator CRDs are not installed in the Kubernetes cluster.
+	// +optional
+	Alerts ClickHouseAlertsSpec `json:"alerts,omitempty"`
+}
+
+// ClickHouseAlertsSpec defines the PrometheusRule with ClickHouse cluster alerts managed by the operator.
+type ClickHouseAlertsSpec struct {
+	AlertsSpec `json:",inline"`
+
+	// ReplicationDelaySeconds is the replication lag of a ClickHouse replica that triggers the alert.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=300
+	ReplicationDelaySeconds int32 `json:"replicationDelaySeconds,omitempty"`
+
+	// PartsPerPartition is the number of active parts in a single partition that triggers the alert.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=1000
+	PartsPerPartition int32 `json:"partsPerPartition,omitempty"`
+}
+
+// DistributedDDLSpec configures the distributed DDL queue of ON CLUSTER queries.
+type DistributedDDLSpec struct {
+	// TaskMaxLifetimeSeconds is the retention of the queue entries.
+	// ClickHouse removes older entries regardless of their status. ClickHouse default of 7 days is used if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TaskMaxLifetimeSeconds int64 `json:"taskMaxLifetimeSeconds,omitempty"`
+
+	// MaxTasksInQueue is the number of the newest entries kept in the queue.
+	// ClickHouse default of 1000 entries is used if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxTasksInQueue int64 `json:"maxTasksInQueue,omitempty"`
+
+	// StaleTaskThresholdSeconds is the age of the entry not finished by all replicas that is reported as stale.
+	// Stale entries of removed replicas are deleted from the queue of the operator managed KeeperCluster.
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:default:=3600
+	StaleTaskThresholdSeconds int64 `json:"staleTaskThresholdSeconds,omitempty"`
+}
+
+// ReplicaHealthSpec configures the replication health checks of the replicas.
+// Replicas failing the checks are reported not ready.
+type ReplicaHealthSpec struct {
+	// MaxReplicationDelaySeconds is the replication delay after which the replica is considered not ready.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=300
+	MaxReplicationDelaySeconds int64 `json:"maxReplicationDelaySeconds,omitempty"`
+
+	// CatchUpMaxDelaySeconds is the replication delay under which the replica restarted by the rolling update is
+	// considered caught up. The rolling update proceeds to the next replica once the restarted one has caught up.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=10
+	CatchUpMaxDelaySeconds int64 `json:"catchUpMaxDelaySeconds,omitempty"`
+
+	// CatchUpMaxQueueSize is the replication queue size under which the replica restarted by the rolling update is
+	// considered caught up. Raise it for clusters with the heavy background merges load.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=20
+	CatchUpMaxQueueSize int64 `json:"catchUpMaxQueueSize,omitempty"`
+
+	// CatchUpTimeoutSeconds is the time the rolling update waits for the restarted replica to catch up.
+	// Once it passes, a warning event is emitted and the rolling update proceeds to the next replica.
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:default:=1800
+	CatchUpTimeoutSeconds int64 `json:"catchUpTimeoutSeconds,omitempty"`
+
+	// MaxDistributedDDLBacklog is the number of distributed DDL tasks not yet executed by the replica
+	// after which the replica is considered not ready.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=100
+	MaxDistributedDDLBacklog int64 `json:"maxDistributedDDLBacklog,omitempty"`
+}
+
 // ClickHouseSettings defines ClickHouse server settings options.
 type ClickHouseSettings struct {
 	// Specifies source and type of the password for `default` ClickHouse user.
@@ -127,13 +502,46 @@ type ClickHouseSettings struct {
 	// +optional
 	TLS ClusterTLSSpec `json:"tls,omitempty"`
 
+	// Client protocols served by ClickHouse server. Allows to enable additional protocols and override default ports.
+	// +optional
+	Protocols ClickHouseProtocolsSpec `json:"protocols,omitempty"`
+
 	// Enables synchronization of ClickHouse databases to the newly created replicas and cleanup of stale replicas
 	// after scale down.
-	// Supports only Replicated and integration databases.
+	// Supports Replicated and integration databases, and replicated tables of Atomic databases.
 	// +optional
 	// +kubebuilder:default:=true
 	EnableDatabaseSync bool `json:"enableDatabaseSync,omitempty"`
 
+	// EnableSchemaResources allows ClickHouseSchema resources in the cluster namespace to create schema objects.
+	// Schema queries are executed with the operator management user privileges, so anyone allowed to create
+	// ClickHouseSchema in the namespace is able to create any database, table, view or dictionary in the cluster.
+	// +optional
+	EnableSchemaResources bool `json:"enableSchemaResources,omitempty"`
+
+	// DefaultDatabaseMigration defines how the non-Replicated `default` database containing tables is migrated to the
+	// Replicated engine. Empty `default` database is always recreated with the Replicated engine.
+	// +optional
+	// +kubebuilder:default:=Block
+	DefaultDatabaseMigration DefaultDatabaseMigrationPolicy `json:"defaultDatabaseMigration,omitempty"`
+
+	// Maximum time in seconds to wait for running queries to finish before the ClickHouse server is stopped.
+	// Pending Distributed tables data is flushed after draining.
+	// Pod termination grace period is extended to fit the timeout, unless it is set explicitly.
+	// Set to 0 to disable draining.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=60
+	ShutdownDrainTimeoutSeconds *int32 `json:"shutdownDrainTimeoutSeconds,omitempty"`
+
+	// DistributedDDL configures the retention and monitoring of the ON CLUSTER queries queue.
+	// +optional
+	DistributedDDL DistributedDDLSpec `json:"distributedDDL,omitempty"`
+
+	// ReplicaHealth configures the replication health checks of the replicas.
+	// +optional
+	ReplicaHealth ReplicaHealthSpec `json:"replicaHealth,omitempty"`
+
 	// Additional ClickHouse configuration that will be merged with the default one.
 	// +nullable
 	// +optional
@@ -147,6 +555,114 @@ type ClickHouseSettings struct {
 	ExtraUsersConfig runtime.RawExtension `json:"extraUsersConfig,omitempty"`
 }
 
+// ClickHouseProtocolsSpec defines client protocols served by ClickHouse server.
+type ClickHouseProtocolsSpec struct {
+	// HTTP interface. Enabled by default, unless TLS is required.
+	// +optional
+	HTTP ProtocolSpec `json:"http,omitempty"`
+
+	// Native TCP protocol. Enabled by default, unless TLS is required.
+	// +optional
+	TCP ProtocolSpec `json:"tcp,omitempty"`
+
+	// HTTPS interface. Enabled by default if TLS is enabled.
+	// +optional
+	HTTPSecure ProtocolSpec `json:"httpSecure,omitempty"`
+
+	// Native TCP protocol over TLS. Enabled by default if TLS is enabled.
+	// +optional
+	TCPSecure ProtocolSpec `json:"tcpSecure,omitempty"`
+
+	// Native TCP protocol behind a proxy sending PROXYv1 header. Disabled by default.
+	// +optional
+	TCPProxy ProtocolSpec `json:"tcpProxy,omitempty"`
+
+	// MySQL wire protocol. Disabled by default.
+	// +optional
+	MySQL ProtocolSpec `json:"mysql,omitempty"`
+
+	// PostgreSQL wire protocol. Disabled by default.
+	// +optional
+	PostgreSQL ProtocolSpec `json:"postgresql,omitempty"`
+
+	// gRPC interface. Disabled by default.
+	// +optional
+	GRPC ProtocolSpec `json:"grpc,omitempty"`
+}
+
+// EnabledPorts returns ports of the enabled protocols keyed by the protocol field name.
+func (s *ClickHouseProtocolsSpec) EnabledPorts(tls ClusterTLSSpec) map[string]int32 {
+	insecureEnabled := !tls.Enabled || !tls.Required
+	protocols := []struct {
+		name           string
+		spec           ProtocolSpec
+		defaultEnabled bool
+		defaultPort    int32
+	}{
+		{"http", s.HTTP, insecureEnabled, DefaultClickHouseHTTPPort},
+		{"tcp", s.TCP, insecureEnabled, DefaultClickHouseNativePort},
+		{"httpSecure", s.HTTPSecure, tls.Enabled, DefaultClickHouseHTTPSecurePort},
+		{"tcpSecure", s.TCPSecure, tls.Enabled, DefaultClickHouseNativeSecurePort},
+		{"tcpProxy", s.TCPProxy, false, DefaultClickHouseNativeProxyPort},
+		{"mysql", s.MySQL, false, DefaultClickHouseMySQLPort},
+		{"postgresql", s.PostgreSQL, false, DefaultClickHousePostgreSQLPort},
+		{"grpc", s.GRPC, false, DefaultClickHouseGRPCPort},
+	}
+
+	ports := map[string]int32{}
+	for _, protocol := range protocols {
+		if !ptr.Deref(protocol.spec.Enabled, protocol.defaultEnabled) {
+			continue
+		}
+
+		ports[protocol.name] = ptr.Deref(protocol.spec.Port, protocol.defaultPort)
+	}
+
+	return ports
+}
+
+// Validate validates the ClickHouseProtocolsSpec configuration against the cluster TLS settings.
+func (s *ClickHouseProtocolsSpec) Validate(tls ClusterTLSSpec) error {
+	if tls.Required && (ptr.Deref(s.HTTP.Enabled, false) || ptr.Deref(s.TCP.Enabled, false)) {
+		return errors.New("http and tcp protocols cannot be enabled if TLS is required")
+	}
+
+	if !tls.Enabled && (ptr.Deref(s.HTTPSecure.Enabled, false) || ptr.Deref(s.TCPSecure.Enabled, false)) {
+		return errors.New("httpSecure and tcpSecure protocols cannot be enabled if TLS is not enabled")
+	}
+
+	ports := s.EnabledPorts(tls)
+	if _, ok := ports["http"]; !ok {
+		if _, ok := ports["httpSecure"]; !ok {
+			return errors.New("at least one of http or httpSecure protocols must be enabled, it is used for health checks")
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(ports))
+	for i, name := range names {
+		for _, other := range names[i+1:] {
+			if ports[name] == ports[other] {
+				return fmt.Errorf("protocols %s and %s use the same port %d", name, other, ports[name])
+			}
+		}
+	}
+
+	return nil
+}
+
+// ProtocolSpec configures a single ClickHouse protocol endpoint.
+type ProtocolSpec struct {
+	// Enabled indicates whether the protocol is served. Default depends on the protocol.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Port overrides the default protocol port.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
 // ClickHouseClusterStatus defines the observed state of ClickHouseCluster.
 type ClickHouseClusterStatus struct {
 	// +listType=map
@@ -176,6 +692,25 @@ type ClickHouseClusterStatus struct {
 	// ObservedGeneration indicates latest generation observed by controller.
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DistributedDDLQueue reports the backlog of the ON CLUSTER queries queue.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	DistributedDDLQueue *DistributedDDLQueueStatus `json:"distributedDDLQueue,omitempty"`
+}
+
+// DistributedDDLQueueStatus defines the observed state of the distributed DDL queue.
+type DistributedDDLQueueStatus struct {
+	// PendingTasks is the number of entries not finished by some of the cluster replicas.
+	PendingTasks int64 `json:"pendingTasks"`
+	// StaleTasks is the number of pending entries older than the stale threshold.
+	StaleTasks int64 `json:"staleTasks"`
+	// FailedTasks is the number of entries failed on some of the cluster replicas.
+	FailedTasks int64 `json:"failedTasks"`
+	// OldestPendingTaskTime is the creation time of the oldest pending entry.
+	// +optional
+	OldestPendingTaskTime *metav1.Time `json:"oldestPendingTaskTime,omitempty"`
+	// LastCheckTime is the time the queue was inspected.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
 }
 
 // ClickHouseCluster is the Schema for the `clickhouseclusters` API.
@@ -315,6 +850,58 @@ func (v *ClickHouseCluster) SpecificName() string {
 	return v.specificName
 }
 
+// KeeperClusterNamespacedName returns NamespacedName of the referenced KeeperCluster.
+func (v *ClickHouseCluster) KeeperClusterNamespacedName() types.NamespacedName {
+	if v.Spec.KeeperClusterRef == nil {
+		return types.NamespacedName{}
+	}
+
+	namespace := v.Spec.KeeperClusterRef.Namespace
+	if namespace == "" {
+		namespace = v.Namespace
+	}
+
+	return types.NamespacedName{
+		Namespace: namespace,
+		Name:      v.Spec.KeeperClusterRef.Name,
+	}
+}
+
+// KeeperRootNone is the pinned Keeper root of the clusters using the KeeperCluster root without chroot.
+const KeeperRootNone = "/"
+
+// KeeperRoot returns the chroot path isolating the cluster data from other clusters sharing the coordination service.
+// Clusters referencing KeeperCluster get their own root by default. The root used by the cluster is pinned in the
+// keeper root annotation, so clusters created before the default roots were introduced keep using the KeeperCluster
+// root.
+func (v *ClickHouseCluster) KeeperRoot() string {
+	if v.Spec.Coordination.Root != "" {
+		return v.Spec.Coordination.Root
+	}
+
+	if v.Spec.KeeperClusterRef == nil {
+		return ""
+	}
+
+	switch pinned := v.Annotations[controllerutil.AnnotationKeeperRoot]; pinned {
+	case "":
+		return fmt.Sprintf("/clusters/%s/%s", v.Namespace, v.Name)
+	case KeeperRootNone:
+		return ""
+	default:
+		return pinned
+	}
+}
+
+// ShutdownDrainTimeout returns the maximum time to wait for running queries before the server is stopped.
+func (v *ClickHouseCluster) ShutdownDrainTimeout() time.Duration {
+	if v.Spec.Settings.ShutdownDrainTimeoutSeconds == nil {
+		return DefaultClickHouseShutdownDrainTimeoutSeconds * time.Second
+	}
+
+	return time.Duration(*v.Spec.Settings.ShutdownDrainTimeoutSeconds) * time.Second
+}
+
 // Shards returns requested number of shards in the ClickHouseCluster.
 func (v *ClickHouseCluster) Shards() int32 {
 	if v.Spec.Shards == nil {
@@ -346,6 +933,16 @@ func (v *ClickHouseCluster) ReplicaIDs() iter.Seq[ClickHouseReplicaID] {
 	}
 }
 
+// ClusterServiceName returns name of the client-facing Service for the whole ClickHouseCluster.
+func (v *ClickHouseCluster) ClusterServiceName() string {
+	return v.SpecificName()
+}
+
+// ShardServiceName returns name of the client-facing Service for the given shard.
+func (v *ClickHouseCluster) ShardServiceName(shardID int32) string {
+	return fmt.Sprintf("%s-shard-%d", v.SpecificName(), shardID)
+}
+
 // HeadlessServiceName returns name of the headless service for the ClickHouseCluster.
 func (v *ClickHouseCluster) HeadlessServiceName() string {
 	return v.SpecificName() + "-headless"
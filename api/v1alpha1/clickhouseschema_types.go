@@ -0,0 +1,268 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ClickHouseSchemaSpec defines the desired state of ClickHouseSchema.
+type ClickHouseSchemaSpec struct {
+	// ClusterRef references the ClickHouseCluster in the same namespace the schema is applied to.
+	ClusterRef corev1.LocalObjectReference `json:"clusterRef"`
+
+	// Objects lists the declared databases, tables, materialized views and dictionaries in the creation order.
+	// +listType=atomic
+	Objects []ClickHouseSchemaObject `json:"objects"`
+
+	// DryRun only reports the drift between the declared and actual schema without creating missing objects.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Validate validates the ClickHouseSchemaSpec configuration.
+func (s *ClickHouseSchemaSpec) Validate() error {
+	if s.ClusterRef.Name == "" {
+		return errors.New("clusterRef.name must be specified")
+	}
+
+	var errs []error
+
+	seen := map[string]struct{}{}
+	for _, object := range s.Objects {
+		if _, ok := seen[object.FullName()]; ok {
+			errs = append(errs, fmt.Errorf("object %s is declared more than once", object.FullName()))
+		}
+
+		seen[object.FullName()] = struct{}{}
+
+		if err := object.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("object %s: %w", object.FullName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ClickHouseSchemaObjectKind is the kind of the declared schema object.
+// +kubebuilder:validation:Enum=Database;Table;MaterializedView;Dictionary
+type ClickHouseSchemaObjectKind string
+
+const (
+	SchemaObjectKindDatabase         ClickHouseSchemaObjectKind = "Database"
+	SchemaObjectKindTable            ClickHouseSchemaObjectKind = "Table"
+	SchemaObjectKindMaterializedView ClickHouseSchemaObjectKind = "MaterializedView"
+	SchemaObjectKindDictionary       ClickHouseSchemaObjectKind = "Dictionary"
+)
+
+// schemaObjectKeywords maps object kinds to the object keyword of the CREATE statement.
+var schemaObjectKeywords = map[ClickHouseSchemaObjectKind]string{
+	SchemaObjectKindDatabase:         "DATABASE",
+	SchemaObjectKindTable:            "TABLE",
+	SchemaObjectKindMaterializedView: "MATERIALIZED VIEW",
+	SchemaObjectKindDictionary:       "DICTIONARY",
+}
+
+const schemaIdentifierPattern = "(?:`[^`]+`|\"[^\"]+\"|[A-Za-z_][A-Za-z0-9_]*)"
+
+var (
+	// schemaQueryHeaderPattern matches the CREATE statement up to the object name and optional UUID.
+	// Submatches are the object keyword, IF NOT EXISTS clause, database and object name.
+	schemaQueryHeaderPattern = regexp.MustCompile(`(?is)^\s*CREATE\s+(DATABASE|TABLE|MATERIALIZED\s+VIEW|DICTIONARY)\s+` +
+		`(IF\s+NOT\s+EXISTS\s+)?(?:(` + schemaIdentifierPattern + `)\s*\.\s*)?(` + schemaIdentifierPattern + `)` +
+		`(?:\s+UUID\s+'[^']*')?`)
+	onClusterPattern  = regexp.MustCompile(`(?i)\bON\s+CLUSTER\b`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// unquoteIdentifier removes backticks or double quotes around the identifier.
+func unquoteIdentifier(identifier string) string {
+	if len(identifier) >= 2 && (identifier[0] == '`' || identifier[0] == '"') {
+		return identifier[1 : len(identifier)-1]
+	}
+
+	return identifier
+}
+
+// ClickHouseSchemaObject defines a single schema object with its DDL.
+type ClickHouseSchemaObject struct {
+	// Kind of the object.
+	Kind ClickHouseSchemaObjectKind `json:"kind"`
+
+	// Database containing the object, or the name of the declared database.
+	// +kubebuilder:validation:MinLength=1
+	Database string `json:"database"`
+
+	// Name of the object. Must be empty for databases.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Query is the CREATE statement of the object, without the ON CLUSTER clause.
+	// +kubebuilder:validation:MinLength=1
+	Query string `json:"query"`
+}
+
+// FullName returns the object name qualified with the database.
+func (o *ClickHouseSchemaObject) FullName() string {
+	if o.Kind == SchemaObjectKindDatabase {
+		return fmt.Sprintf("`%s`", o.Database)
+	}
+
+	return fmt.Sprintf("`%s`.`%s`", o.Database, o.Name)
+}
+
+// Validate validates the ClickHouseSchemaObject configuration.
+func (o *ClickHouseSchemaObject) Validate() error {
+	keyword, ok := schemaObjectKeywords[o.Kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %q", o.Kind)
+	}
+
+	if (o.Kind == SchemaObjectKindDatabase) != (o.Name == "") {
+		return errors.New("name must be set for all objects except databases")
+	}
+
+	match := schemaQueryHeaderPattern.FindStringSubmatch(o.Query)
+	if match == nil || whitespacePattern.ReplaceAllString(strings.ToUpper(match[1]), " ") != keyword {
+		return fmt.Errorf("query must be a CREATE %s statement", keyword)
+	}
+
+	database, name := unquoteIdentifier(match[3]), unquoteIdentifier(match[4])
+	if o.Kind == SchemaObjectKindDatabase {
+		database, name = name, ""
+	}
+
+	if database != o.Database || name != o.Name {
+		return fmt.Errorf("query must create %s with the database qualified name", o.FullName())
+	}
+
+	if onClusterPattern.MatchString(o.Query) {
+		return errors.New("query must not contain the ON CLUSTER clause")
+	}
+
+	return nil
+}
+
+// QueryOnCluster returns the object query executed on all hosts of the given cluster.
+func (o *ClickHouseSchemaObject) QueryOnCluster(cluster string) string {
+	loc := schemaQueryHeaderPattern.FindStringIndex(o.Query)
+	if loc == nil {
+		return o.Query
+	}
+
+	return o.Query[:loc[1]] + fmt.Sprintf(" ON CLUSTER `%s`", cluster) + o.Query[loc[1]:]
+}
+
+// QueryWithoutIfNotExists returns the object query without the IF NOT EXISTS clause, as printed by SHOW CREATE.
+func (o *ClickHouseSchemaObject) QueryWithoutIfNotExists() string {
+	loc := schemaQueryHeaderPattern.FindStringSubmatchIndex(o.Query)
+	if loc == nil || loc[4] < 0 {
+		return o.Query
+	}
+
+	return o.Query[:loc[4]] + o.Query[loc[5]:]
+}
+
+// ClickHouseSchemaObjectState is the observed state of the declared schema object.
+type ClickHouseSchemaObjectState string
+
+const (
+	// SchemaObjectStateInSync means the object exists and matches the declared query.
+	SchemaObjectStateInSync ClickHouseSchemaObjectState = "InSync"
+	// SchemaObjectStateMissing means the object does not exist.
+	SchemaObjectStateMissing ClickHouseSchemaObjectState = "Missing"
+	// SchemaObjectStateDrifted means the object exists, but its definition differs from the declared query.
+	SchemaObjectStateDrifted ClickHouseSchemaObjectState = "Drifted"
+	// SchemaObjectStateFailed means the object state could not be checked or the object could not be created.
+	SchemaObjectStateFailed ClickHouseSchemaObjectState = "Failed"
+)
+
+// ClickHouseSchemaObjectStatus defines the observed state of a single declared object.
+type ClickHouseSchemaObjectStatus struct {
+	// Kind of the object.
+	Kind ClickHouseSchemaObjectKind `json:"kind"`
+	// Database containing the object, or the name of the declared database.
+	Database string `json:"database"`
+	// Name of the object. Empty for databases.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// State of the object.
+	State ClickHouseSchemaObjectState `json:"state"`
+	// ActualQuery is the SHOW CREATE output of the drifted object.
+	// +optional
+	ActualQuery string `json:"actualQuery,omitempty"`
+	// Message describes the failure of the object check or creation.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClickHouseSchemaStatus defines the observed state of ClickHouseSchema.
+type ClickHouseSchemaStatus struct {
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Objects reports the state of every declared object.
+	// +optional
+	// +listType=atomic
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Objects []ClickHouseSchemaObjectStatus `json:"objects,omitempty"`
+	// ObservedGeneration indicates latest generation observed by controller.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ClickHouseSchema is the Schema for the `clickhouseschemas` API.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=chs
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterRef.name"
+// +kubebuilder:printcolumn:name="InSync",type="string",JSONPath=".status.conditions[?(@.type==\"InSync\")].status"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"InSync\")].message"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +operator-sdk:csv:customresourcedefinitions:displayName="ClickHouse Schema"
+type ClickHouseSchema struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClickHouseSchemaSpec   `json:"spec,omitempty"`
+	Status ClickHouseSchemaStatus `json:"status,omitempty"`
+}
+
+// NamespacedName returns NamespacedName for the ClickHouseSchema.
+func (v *ClickHouseSchema) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: v.Namespace,
+		Name:      v.Name,
+	}
+}
+
+// ClusterNamespacedName returns NamespacedName of the referenced ClickHouseCluster.
+func (v *ClickHouseSchema) ClusterNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: v.Namespace,
+		Name:      v.Spec.ClusterRef.Name,
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// ClickHouseSchemaList contains a list of ClickHouseSchema.
+type ClickHouseSchemaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClickHouseSchema `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClickHouseSchema{}, &ClickHouseSchemaList{})
+}
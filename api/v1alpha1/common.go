@@ -307,3 +307,112 @@ func formatPodHostname(stsName, serviceName, namespace, domain string) string {
 
 	return fmt.Sprintf("%s-0.%s.%s.svc.%s", stsName, serviceName, namespace, domain)
 }
+
+// NetworkPolicySpec defines NetworkPolicies restricting access to the cluster internal ports.
+type NetworkPolicySpec struct {
+	// Enabled indicates whether the operator should create NetworkPolicies for the cluster.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// PodMonitorSpec defines the PodMonitor managed by the operator.
+type PodMonitorSpec struct {
+	// Enabled indicates whether the operator should create the PodMonitor for the cluster.
+	// +optional
+	// +kubebuilder:default:=false
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Additional labels added to the PodMonitor. Use them to match the Prometheus podMonitorSelector.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Interval at which metrics should be scraped. Prometheus global scrape interval is used if empty.
+	// Example: 30s
+	// +optional
+	// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	Interval string `json:"interval,omitempty"`
+
+	// Timeout after which the scrape is ended. Must not be greater than the scrape interval.
+	// +optional
+	// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// TLS configuration used by Prometheus to scrape the metrics endpoint.
+	// If set, metrics are scraped over HTTPS.
+	// +optional
+	TLS *MonitoringTLSSpec `json:"tls,omitempty"`
+}
+
+// MonitoringTLSSpec defines TLS settings used to scrape the metrics endpoint.
+type MonitoringTLSSpec struct {
+	// CABundle is a reference to a Secret key containing the CA bundle used to verify the endpoint certificate.
+	// +optional
+	CABundle *SecretKeySelector `json:"caBundle,omitempty"`
+
+	// ServerName is used to verify the hostname of the scraped endpoint.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables the endpoint certificate verification.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// AlertsSpec defines the PrometheusRule with cluster alerts managed by the operator.
+// Thresholds of the alerts are defined by the cluster specific alerts spec.
+type AlertsSpec struct {
+	// Enabled indicates whether the operator should create the PrometheusRule for the cluster.
+	// +optional
+	// +kubebuilder:default:=false
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Additional labels added to the PrometheusRule. Use them to match the Prometheus ruleSelector.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Additional labels added to every alert. Use them to route alerts in Alertmanager.
+	// The `severity` label is always set by the operator.
+	// +optional
+	AlertLabels map[string]string `json:"alertLabels,omitempty"`
+
+	// For is the duration the condition must hold before the alert fires.
+	// +optional
+	// +kubebuilder:default:="5m"
+	// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	For string `json:"for,omitempty"`
+}
+
+// ServiceTemplateSpec describes a client-facing Service managed by the operator.
+type ServiceTemplateSpec struct {
+	// Enabled indicates whether the operator should create the Service.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Type of the Service.
+	// +optional
+	// +kubebuilder:validation:Enum:=ClusterIP;NodePort;LoadBalancer
+	// +kubebuilder:default:=ClusterIP
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// ExternalTrafficPolicy of the Service. Applicable only to NodePort and LoadBalancer Services.
+	// +optional
+	// +kubebuilder:validation:Enum:=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// Additional labels added to the Service.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Additional annotations added to the Service. Use them to configure cloud provider load balancers.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Validate validates the ServiceTemplateSpec configuration.
+func (s *ServiceTemplateSpec) Validate() error {
+	if s.ExternalTrafficPolicy != "" && (s.Type == "" || s.Type == corev1.ServiceTypeClusterIP) {
+		return errors.New("externalTrafficPolicy can be set only for NodePort and LoadBalancer Services")
+	}
+
+	return nil
+}
@@ -60,6 +60,21 @@ const (
 	ClickHouseConditionReplicasInSync       ConditionReason = "ReplicasInSync"
 	ClickHouseConditionDatabasesNotCreated  ConditionReason = "DatabasesNotCreated"
 	ClickHouseConditionReplicasNotCleanedUp ConditionReason = "ReplicasNotCleanedUp"
+	// ClickHouseConditionDefaultDatabaseNotReplicated blocks the schema sync until the non-Replicated `default`
+	// database with tables is migrated manually or the migration policy allows the operator to do it.
+	ClickHouseConditionDefaultDatabaseNotReplicated ConditionReason = "DefaultDatabaseNotReplicated"
+	// ClickHouseConditionDefaultDatabaseRenamed is reported until the tables of the `default` database renamed by
+	// the migration are moved to the Replicated `default` database manually and the renamed database is dropped.
+	ClickHouseConditionDefaultDatabaseRenamed ConditionReason = "DefaultDatabaseRenamed"
+
+	// ClickHouseConditionTypeDistributedDDLQueueHealthy indicates that the distributed DDL queue has no stale
+	// or failed ON CLUSTER queries.
+	ClickHouseConditionTypeDistributedDDLQueueHealthy ConditionType = "DistributedDDLQueueHealthy"
+
+	ClickHouseConditionDDLQueueHealthy ConditionReason = "QueueHealthy"
+	ClickHouseConditionDDLTasksStale   ConditionReason = "TasksStale"
+	ClickHouseConditionDDLTasksFailed  ConditionReason = "TasksFailed"
+	ClickHouseConditionDDLQueueUnknown ConditionReason = "QueueStateUnknown"
 )
 
 // KeeperCluster specific condition types and reasons.
@@ -72,6 +87,37 @@ const (
 	KeeperConditionReasonNoQuorum                 ConditionReason = "NoQuorum"
 	KeeperConditionReasonWaitingFollowers         ConditionReason = "WaitingFollowers"
 	KeeperConditionReasonReadyToScale             ConditionReason = "ReadyToScale"
+
+	// KeeperConditionTypeStorageHealthy indicates that Keeper snapshots and logs usage of the data volume
+	// is below the threshold.
+	KeeperConditionTypeStorageHealthy ConditionType = "StorageHealthy"
+
+	KeeperConditionReasonStorageUsageNormal  ConditionReason = "StorageUsageNormal"
+	KeeperConditionReasonStorageUsageHigh    ConditionReason = "StorageUsageHigh"
+	KeeperConditionReasonStorageUsageUnknown ConditionReason = "StorageUsageUnknown"
+
+	// KeeperConditionTypeLeaderInPreferredZone indicates that the quorum leader runs in the preferred zone.
+	KeeperConditionTypeLeaderInPreferredZone ConditionType = "LeaderInPreferredZone"
+
+	KeeperConditionReasonLeaderInPreferredZone    ConditionReason = "LeaderInPreferredZone"
+	KeeperConditionReasonLeaderOutOfPreferredZone ConditionReason = "LeaderOutOfPreferredZone"
+	KeeperConditionReasonLeaderZoneUnknown        ConditionReason = "LeaderZoneUnknown"
+)
+
+// ClickHouseSchema specific condition types and reasons.
+const (
+	// SchemaConditionTypeInSync indicates that all declared schema objects exist and match the declared queries.
+	SchemaConditionTypeInSync ConditionType = "InSync"
+
+	SchemaConditionReasonObjectsInSync   ConditionReason = "ObjectsInSync"
+	SchemaConditionReasonObjectsMissing  ConditionReason = "ObjectsMissing"
+	SchemaConditionReasonObjectsDrifted  ConditionReason = "ObjectsDrifted"
+	SchemaConditionReasonObjectsFailed   ConditionReason = "ObjectsFailed"
+	SchemaConditionReasonClusterNotFound ConditionReason = "ClusterNotFound"
+	SchemaConditionReasonClusterNotReady ConditionReason = "ClusterNotReady"
+	// SchemaConditionReasonSchemaResourcesDisabled means the referenced cluster does not allow ClickHouseSchema
+	// resources to manage its schema.
+	SchemaConditionReasonSchemaResourcesDisabled ConditionReason = "SchemaResourcesDisabled"
 )
 
 var (
@@ -85,6 +131,7 @@ var (
 		ConditionTypeConfigurationInSync,
 		ConditionTypeReady,
 		ClickHouseConditionTypeSchemaInSync,
+		ClickHouseConditionTypeDistributedDDLQueueHealthy,
 	}
 
 	// AllKeeperConditionTypes lists all KeeperCluster condition types.
@@ -97,5 +144,7 @@ var (
 		ConditionTypeConfigurationInSync,
 		ConditionTypeReady,
 		KeeperConditionTypeScaleAllowed,
+		KeeperConditionTypeStorageHealthy,
+		KeeperConditionTypeLeaderInPreferredZone,
 	}
 )
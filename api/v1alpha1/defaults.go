@@ -28,8 +28,43 @@ const (
 	DefaultClickHouseShardCount   = 1
 	DefaultClickHouseReplicaCount = 3
 
+	DefaultClickHouseShutdownDrainTimeoutSeconds = 60
+
+	DefaultDistributedDDLStaleTaskThresholdSeconds = 60 * 60
+
+	DefaultMaxReplicationDelaySeconds = 300
+	DefaultCatchUpMaxDelaySeconds     = 10
+	DefaultCatchUpMaxQueueSize        = 20
+	DefaultCatchUpTimeoutSeconds      = 30 * 60
+	DefaultMaxDistributedDDLBacklog   = 100
+
+	DefaultClickHouseHTTPPort         = 8123
+	DefaultClickHouseNativePort       = 9000
+	DefaultClickHouseHTTPSecurePort   = 8443
+	DefaultClickHouseNativeSecurePort = 9440
+	DefaultClickHouseNativeProxyPort  = 9011
+	DefaultClickHouseMySQLPort        = 9004
+	DefaultClickHousePostgreSQLPort   = 9005
+	DefaultClickHouseGRPCPort         = 9100
+
 	DefaultMaxLogFiles = 50
 
+	DefaultKeeperSessionTimeoutMs         = 30000
+	DefaultKeeperSnapshotDistance         = 100000
+	DefaultKeeperSnapshotsToKeep          = 3
+	DefaultKeeperRotateLogStorageInterval = 100000
+
+	DefaultKeeperStorageUsageThresholdPercent = 80
+
+	DefaultKeeperBootstrapDataPath           = "version-2"
+	DefaultKeeperBootstrapDownloadRepository = "docker.io/amazon/aws-cli"
+	DefaultKeeperBootstrapDownloadTag        = "2.17.0"
+
+	DefaultAlertFor                       = "5m"
+	DefaultAlertReplicationDelaySeconds   = 300
+	DefaultAlertPartsPerPartition         = 1000
+	DefaultAlertKeeperLatencyMilliseconds = 100
+
 	// DefaultClusterDomain is the default Kubernetes cluster domain suffix for DNS resolution.
 	DefaultClusterDomain = "cluster.local"
 	DefaultAccessMode    = corev1.ReadWriteOnce
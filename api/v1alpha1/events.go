@@ -17,6 +17,8 @@ const (
 	EventReasonHorizontalScaleBlocked   EventReason = "HorizontalScaleBlocked"
 	EventReasonHorizontalScaleStarted   EventReason = "HorizontalScaleStarted"
 	EventReasonHorizontalScaleCompleted EventReason = "HorizontalScaleCompleted"
+	EventReasonQuorumReconfigured       EventReason = "QuorumReconfigured"
+	EventReasonReplicaRoleChanged       EventReason = "ReplicaRoleChanged"
 )
 
 // Event reasons for cluster health transitions.
@@ -25,12 +27,74 @@ const (
 	EventReasonClusterNotReady EventReason = "ClusterNotReady"
 )
 
+// Event reasons for ClickHouse rolling updates.
+const (
+	EventReasonReplicaCatchUpTimedOut EventReason = "ReplicaCatchUpTimedOut"
+)
+
+// Event reasons for Keeper leadership transfer.
+const (
+	EventReasonLeadershipTransferRequested EventReason = "LeadershipTransferRequested"
+	EventReasonLeadershipTransferred       EventReason = "LeadershipTransferred"
+	EventReasonLeadershipTransferFailed    EventReason = "LeadershipTransferFailed"
+	EventReasonLeaderOutOfPreferredZone    EventReason = "LeaderOutOfPreferredZone"
+)
+
+// Event reasons for Keeper storage usage.
+const (
+	EventReasonStorageUsageHigh  EventReason = "StorageUsageHigh"
+	EventReasonSnapshotScheduled EventReason = "SnapshotScheduled"
+	EventReasonSnapshotFailed    EventReason = "SnapshotFailed"
+)
+
+// Event reasons for Keeper quorum recovery.
+const (
+	EventReasonQuorumRecoveryStarted EventReason = "QuorumRecoveryStarted"
+	EventReasonQuorumRecoverySkipped EventReason = "QuorumRecoverySkipped"
+	EventReasonQuorumRecoveryFailed  EventReason = "QuorumRecoveryFailed"
+	EventReasonQuorumRecovered       EventReason = "QuorumRecovered"
+)
+
+// Event reasons for Keeper bootstrap from ZooKeeper data.
+const (
+	EventReasonBootstrapStarted            EventReason = "BootstrapStarted"
+	EventReasonBootstrapCompleted          EventReason = "BootstrapCompleted"
+	EventReasonBootstrapVerificationFailed EventReason = "BootstrapVerificationFailed"
+)
+
+// Event reasons for the `default` database migration to the Replicated engine.
+const (
+	EventReasonDefaultDatabaseRenamed EventReason = "DefaultDatabaseRenamed"
+	EventReasonDefaultDatabaseDropped EventReason = "DefaultDatabaseDropped"
+)
+
+// Event reasons for the Keeper root of ClickHouse clusters.
+const (
+	EventReasonKeeperRootConflict      EventReason = "KeeperRootConflict"
+	EventReasonKeeperRootCleanupFailed EventReason = "KeeperRootCleanupFailed"
+)
+
+// Event reasons for the distributed DDL queue.
+const (
+	EventReasonDistributedDDLTasksStale     EventReason = "DistributedDDLTasksStale"
+	EventReasonDistributedDDLTasksCleanedUp EventReason = "DistributedDDLTasksCleanedUp"
+)
+
+// Event reasons for ClickHouseSchema objects.
+const (
+	EventReasonSchemaObjectCreated      EventReason = "SchemaObjectCreated"
+	EventReasonSchemaObjectCreateFailed EventReason = "SchemaObjectCreateFailed"
+)
+
 // EventAction represents the action associated with an event.
 type EventAction = string
 
 const (
 	EventActionReconciling    EventAction = "Reconciling"
 	EventActionScaling        EventAction = "Scaling"
+	EventActionUpdating       EventAction = "Updating"
+	EventActionRecovering     EventAction = "Recovering"
+	EventActionBootstrapping  EventAction = "Bootstrapping"
 	EventActionBecameReady    EventAction = "BecameReady"
 	EventActionBecameNotReady EventAction = "BecameNotReady"
 )
@@ -3,7 +3,10 @@ package v1alpha1
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -24,6 +27,14 @@ type KeeperClusterSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Replica count"
 	Replicas *int32 `json:"replicas"`
 
+	// Number of non-voting learner replicas in addition to the voting replicas.
+	// Learners replicate the data and serve client requests, but never become the leader and do not count in the quorum.
+	// Replicas with lower ids are voters, increase replicas and decrease learners by the same count to promote learners.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=15
+	Learners *int32 `json:"learners,omitempty"`
+
 	// Parameters passed to the Keeper pod spec.
 	// +optional
 	PodTemplate PodTemplateSpec `json:"podTemplate,omitempty"`
@@ -53,6 +64,34 @@ type KeeperClusterSpec struct {
 	// +optional
 	// +kubebuilder:default:="cluster.local"
 	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// Monitoring configures Prometheus Operator resources created for the ClickHouse Keeper cluster.
+	// +optional
+	Monitoring KeeperMonitoringSpec `json:"monitoring,omitempty"`
+
+	// NetworkPolicy restricts access to the Raft port to the cluster replicas
+	// and access to the client ports to the operator and ClickHouse clusters using this Keeper cluster.
+	// +optional
+	NetworkPolicy NetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// AllowedNamespaces lists namespaces of ClickHouseClusters allowed to use this KeeperCluster.
+	// ClickHouseClusters in the KeeperCluster namespace are always allowed. Use "*" to allow all namespaces.
+	// +optional
+	// +listType=set
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// Storage configures monitoring of the Keeper data volume usage.
+	// +optional
+	Storage KeeperStorageSpec `json:"storage,omitempty"`
+
+	// LeaderPlacement configures which replicas are preferred to be the quorum leader.
+	// +optional
+	LeaderPlacement KeeperLeaderPlacementSpec `json:"leaderPlacement,omitempty"`
+
+	// Bootstrap imports ZooKeeper data into the first replica before the cluster first starts.
+	// Can be set only on cluster creation.
+	// +optional
+	Bootstrap *KeeperBootstrapSpec `json:"bootstrap,omitempty"`
 }
 
 // WithDefaults sets default values for KeeperClusterSpec fields.
@@ -83,6 +122,22 @@ func (s *KeeperClusterSpec) WithDefaults() {
 				Size:      "1000M",
 				Count:     DefaultMaxLogFiles,
 			},
+			Coordination: KeeperCoordinationSettings{
+				SessionTimeoutMs:         DefaultKeeperSessionTimeoutMs,
+				SnapshotDistance:         DefaultKeeperSnapshotDistance,
+				SnapshotsToKeep:          DefaultKeeperSnapshotsToKeep,
+				RotateLogStorageInterval: DefaultKeeperRotateLogStorageInterval,
+				ForceSync:                new(true),
+			},
+		},
+		Storage: KeeperStorageSpec{
+			UsageThresholdPercent: DefaultKeeperStorageUsageThresholdPercent,
+		},
+		Monitoring: KeeperMonitoringSpec{
+			Alerts: KeeperAlertsSpec{
+				AlertsSpec:                AlertsSpec{For: DefaultAlertFor},
+				KeeperLatencyMilliseconds: DefaultAlertKeeperLatencyMilliseconds,
+			},
 		},
 	}
 
@@ -93,8 +148,15 @@ func (s *KeeperClusterSpec) WithDefaults() {
 	if s.DataVolumeClaimSpec != nil && len(s.DataVolumeClaimSpec.AccessModes) == 0 {
 		s.DataVolumeClaimSpec.AccessModes = []corev1.PersistentVolumeAccessMode{DefaultAccessMode}
 	}
+
+	if s.Bootstrap != nil {
+		s.Bootstrap.WithDefaults()
+	}
 }
 
+// AllNamespaces is the allowedNamespaces entry allowing ClickHouseClusters from any namespace.
+const AllNamespaces = "*"
+
 // KeeperSettings defines ClickHouse Keeper server configuration.
 type KeeperSettings struct {
 	// Configuration of ClickHouse Keeper server logging.
@@ -105,6 +167,24 @@ type KeeperSettings struct {
 	// +optional
 	TLS ClusterTLSSpec `json:"tls,omitempty"`
 
+	// DynamicReconfiguration enables quorum membership changes with the Keeper `reconfig` command.
+	// The quorum configuration is used only to bootstrap new replicas.
+	// Requires ClickHouse Keeper version supporting `keeper_server.enable_reconfiguration`.
+	// Changing this setting restarts all replicas.
+	// +optional
+	DynamicReconfiguration bool `json:"dynamicReconfiguration,omitempty"`
+
+	// Coordination contains the main ClickHouse Keeper coordination settings.
+	// +optional
+	Coordination KeeperCoordinationSettings `json:"coordination,omitempty"`
+
+	// FourLetterWordAllowList lists four letter word commands allowed by the Keeper.
+	// Must include `mntr`, used by the operator to check replica state. Use "*" to allow all commands.
+	// The Keeper default list is used if empty.
+	// +optional
+	// +listType=set
+	FourLetterWordAllowList []string `json:"fourLetterWordAllowList,omitempty"`
+
 	// Additional ClickHouse Keeper configuration that will be merged with the default one.
 	// +nullable
 	// +optional
@@ -112,6 +192,239 @@ type KeeperSettings struct {
 	ExtraConfig runtime.RawExtension `json:"extraConfig,omitempty"`
 }
 
+// Validate validates the KeeperSettings configuration.
+func (s *KeeperSettings) Validate() error {
+	if len(s.FourLetterWordAllowList) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, command := range s.FourLetterWordAllowList {
+		if command != AllFourLetterWords && !fourLetterWordPattern.MatchString(command) {
+			errs = append(errs, fmt.Errorf("fourLetterWordAllowList: invalid command %q", command))
+		}
+	}
+
+	if !slices.Contains(s.FourLetterWordAllowList, AllFourLetterWords) &&
+		!slices.Contains(s.FourLetterWordAllowList, FourLetterWordMonitor) {
+		errs = append(errs, fmt.Errorf("fourLetterWordAllowList: must include %q", FourLetterWordMonitor))
+	}
+
+	return errors.Join(errs...)
+}
+
+const (
+	// AllFourLetterWords is the fourLetterWordAllowList entry allowing all commands.
+	AllFourLetterWords = "*"
+	// FourLetterWordMonitor is the command used by the operator to check Keeper replica state.
+	FourLetterWordMonitor = "mntr"
+	// FourLetterWordRequestLeadership is the command used by the operator to move the leadership.
+	FourLetterWordRequestLeadership = "rqld"
+	// FourLetterWordDirectories is the command used by the operator to check Keeper storage usage.
+	FourLetterWordDirectories = "dirs"
+	// FourLetterWordCreateSnapshot is the command used by the operator to schedule a snapshot.
+	FourLetterWordCreateSnapshot = "csnp"
+	// FourLetterWordLogInfo is the command used by the operator to choose the quorum recovery source.
+	FourLetterWordLogInfo = "lgif"
+	// FourLetterWordRecovery is the command used by the operator to recover the lost quorum.
+	FourLetterWordRecovery = "rcvr"
+)
+
+var fourLetterWordPattern = regexp.MustCompile(`^[a-z]{4}$`)
+
+// KeeperCoordinationSettings defines the main ClickHouse Keeper coordination settings.
+// The defaults match the Keeper ones and are not rendered, so the configuration of existing clusters is unchanged.
+// Changing any of them restarts all replicas.
+type KeeperCoordinationSettings struct {
+	// SessionTimeoutMs is the maximum client session timeout in milliseconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=1000
+	// +kubebuilder:default:=30000
+	SessionTimeoutMs int64 `json:"sessionTimeoutMs,omitempty"`
+
+	// SnapshotDistance is the number of log entries between Raft snapshots.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=100000
+	SnapshotDistance int64 `json:"snapshotDistance,omitempty"`
+
+	// SnapshotsToKeep is the number of Raft snapshots kept on disk.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=3
+	SnapshotsToKeep int32 `json:"snapshotsToKeep,omitempty"`
+
+	// RotateLogStorageInterval is the number of log entries stored in a single Raft log file.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=100000
+	RotateLogStorageInterval int64 `json:"rotateLogStorageInterval,omitempty"`
+
+	// CompressLogs enables compression of Raft log files.
+	// +optional
+	// +kubebuilder:default:=false
+	CompressLogs bool `json:"compressLogs,omitempty"`
+
+	// ForceSync calls fsync on every write to the Raft log.
+	// +optional
+	// +kubebuilder:default:=true
+	ForceSync *bool `json:"forceSync,omitempty"`
+}
+
+// KeeperMonitoringSpec defines integration of the Keeper cluster with Prometheus Operator.
+type KeeperMonitoringSpec struct {
+	// PodMonitor configures the PodMonitor that scrapes metrics of all cluster replicas.
+	// Ignored if Prometheus Operator CRDs are not installed in the Kubernetes cluster.
+	// +optional
+	PodMonitor PodMonitorSpec `json:"podMonitor,omitempty"`
+
+	// Alerts configures the PrometheusRule with the recommended alerts for the cluster.
+	// Ignored if Prometheus Operator CRDs are not installed in the Kubernetes cluster.
+	// +optional
+	Alerts KeeperAlertsSpec `json:"alerts,omitempty"`
+}
+
+// KeeperAlertsSpec defines the PrometheusRule with Keeper cluster alerts managed by the operator.
+type KeeperAlertsSpec struct {
+	AlertsSpec `json:",inline"`
+
+	// KeeperLatencyMilliseconds is the average Keeper request latency that triggers the alert.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=100
+	KeeperLatencyMilliseconds int32 `json:"keeperLatencyMilliseconds,omitempty"`
+}
+
+// KeeperStorageSpec configures monitoring of the Keeper data volume usage.
+// Usage is the total size of Keeper snapshots and Raft logs relative to the data volume capacity.
+type KeeperStorageSpec struct {
+	// UsageThresholdPercent is the data volume usage that marks the cluster storage unhealthy.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=80
+	UsageThresholdPercent int32 `json:"usageThresholdPercent,omitempty"`
+
+	// CreateSnapshotOnThreshold schedules a snapshot on replicas exceeding the usage threshold.
+	// A new snapshot allows the Keeper to remove Raft log files covered by it.
+	// +optional
+	CreateSnapshotOnThreshold bool `json:"createSnapshotOnThreshold,omitempty"`
+}
+
+// KeeperLeaderPlacementSpec configures which replicas are preferred to be the quorum leader.
+type KeeperLeaderPlacementSpec struct {
+	// PreferredZone is the zone to keep the leader in, e.g. the zone of the ClickHouse writers.
+	// The value is matched against the node label set by PodTemplate.TopologyZoneKey.
+	// Replicas are scheduled to the zone first, and the leadership is moved back to it once the cluster is stable.
+	// +optional
+	PreferredZone string `json:"preferredZone,omitempty"`
+
+	// Priorities sets the leader election priority of the voting replicas.
+	// +optional
+	// +listType=map
+	// +listMapKey=replicaID
+	Priorities []KeeperReplicaPriority `json:"priorities,omitempty"`
+}
+
+// KeeperReplicaPriority defines the leader election priority of a single replica.
+type KeeperReplicaPriority struct {
+	// ReplicaID is the id of the replica.
+	// +kubebuilder:validation:Minimum=0
+	ReplicaID KeeperReplicaID `json:"replicaID"`
+
+	// Priority is the leader election priority of the replica. The Keeper default is 1.
+	// Replicas with higher priority are preferred by the leader election, replicas with zero priority never become the leader.
+	// +kubebuilder:validation:Minimum=0
+	Priority int32 `json:"priority"`
+}
+
+// KeeperBootstrapSpec configures the import of ZooKeeper snapshots and transaction logs with clickhouse-keeper-converter.
+// Exactly one of PersistentVolumeClaim and ObjectStorage must be set.
+type KeeperBootstrapSpec struct {
+	// PersistentVolumeClaim containing the ZooKeeper data. Mounted read-only into the first replica.
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+
+	// ObjectStorage to download the ZooKeeper data from.
+	// +optional
+	ObjectStorage *KeeperBootstrapObjectStorage `json:"objectStorage,omitempty"`
+
+	// SnapshotsPath is the path of the ZooKeeper snapshots directory relative to the source root.
+	// +optional
+	// +kubebuilder:default:="version-2"
+	SnapshotsPath string `json:"snapshotsPath,omitempty"`
+
+	// LogsPath is the path of the ZooKeeper transaction logs directory relative to the source root.
+	// +optional
+	// +kubebuilder:default:="version-2"
+	LogsPath string `json:"logsPath,omitempty"`
+
+	// ExpectedZnodeCount is the `zk_znode_count` reported by the ZooKeeper `mntr` command.
+	// Other replicas are created only after the first replica reports at least this number of znodes.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ExpectedZnodeCount *int64 `json:"expectedZnodeCount,omitempty"`
+}
+
+// WithDefaults sets default values for KeeperBootstrapSpec fields.
+func (s *KeeperBootstrapSpec) WithDefaults() {
+	if s.SnapshotsPath == "" {
+		s.SnapshotsPath = DefaultKeeperBootstrapDataPath
+	}
+
+	if s.LogsPath == "" {
+		s.LogsPath = DefaultKeeperBootstrapDataPath
+	}
+
+	if s.ObjectStorage != nil && s.ObjectStorage.Image.Repository == "" {
+		s.ObjectStorage.Image = ContainerImage{
+			Repository: DefaultKeeperBootstrapDownloadRepository,
+			Tag:        DefaultKeeperBootstrapDownloadTag,
+		}
+	}
+}
+
+// Validate validates the KeeperBootstrapSpec configuration.
+func (s *KeeperBootstrapSpec) Validate() error {
+	if (s.PersistentVolumeClaim == nil) == (s.ObjectStorage == nil) {
+		return errors.New("exactly one of persistentVolumeClaim or objectStorage must be specified")
+	}
+
+	if s.PersistentVolumeClaim != nil && s.PersistentVolumeClaim.ClaimName == "" {
+		return errors.New("persistentVolumeClaim.claimName must be specified")
+	}
+
+	if s.ObjectStorage != nil && !strings.HasPrefix(s.ObjectStorage.URL, "s3://") {
+		return fmt.Errorf("objectStorage.url must start with s3://, got %q", s.ObjectStorage.URL)
+	}
+
+	for _, path := range []string{s.SnapshotsPath, s.LogsPath} {
+		if strings.HasPrefix(path, "/") || slices.Contains(strings.Split(path, "/"), "..") {
+			return fmt.Errorf("path %q must be relative to the source root", path)
+		}
+	}
+
+	return nil
+}
+
+// KeeperBootstrapObjectStorage defines the S3 compatible storage containing the ZooKeeper data.
+type KeeperBootstrapObjectStorage struct {
+	// URL of the ZooKeeper data root, e.g. s3://bucket/zookeeper.
+	URL string `json:"url"`
+
+	// Endpoint of the S3 compatible storage. AWS S3 is used if empty.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// CredentialsSecret is a Secret with the AWS CLI environment variables, e.g. AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	// +optional
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+
+	// Image of the download container. Must contain the AWS CLI.
+	// +optional
+	Image ContainerImage `json:"image,omitempty"`
+}
+
 // KeeperClusterStatus defines the observed state of KeeperCluster.
 type KeeperClusterStatus struct {
 	// +listType=map
@@ -141,6 +454,16 @@ type KeeperClusterStatus struct {
 	// ObservedGeneration indicates latest generation observed by controller.
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastQuorumRecovery is the value of the latest completed quorum recovery request annotation.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	LastQuorumRecovery string `json:"lastQuorumRecovery,omitempty"`
+	// Bootstrapped indicates that the ZooKeeper data was imported into the first replica and verified.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Bootstrapped bool `json:"bootstrapped,omitempty"`
+	// ApproximateDataSize is the approximate size of the Keeper in-memory data in bytes reported by the leader.
+	// It is the `zk_approximate_data_size` value of the `mntr` command.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ApproximateDataSize int64 `json:"approximateDataSize,omitempty"`
 }
 
 // KeeperCluster is the Schema for the `keeperclusters` API.
@@ -220,6 +543,17 @@ func (v *KeeperCluster) SpecificName() string {
 	return v.specificName
 }
 
+// AllowsNamespace returns true if ClickHouseClusters from the given namespace may use the KeeperCluster.
+func (v *KeeperCluster) AllowsNamespace(namespace string) bool {
+	if namespace == v.Namespace {
+		return true
+	}
+
+	return slices.ContainsFunc(v.Spec.AllowedNamespaces, func(allowed string) bool {
+		return allowed == AllNamespaces || allowed == namespace
+	})
+}
+
 // Replicas returns requested number of replicas in the cluster.
 func (v *KeeperCluster) Replicas() int32 {
 	if v.Spec.Replicas == nil {
@@ -229,6 +563,31 @@ func (v *KeeperCluster) Replicas() int32 {
 	return *v.Spec.Replicas
 }
 
+// Learners returns requested number of non-voting learner replicas in the cluster.
+func (v *KeeperCluster) Learners() int32 {
+	if v.Spec.Learners == nil {
+		return 0
+	}
+
+	return *v.Spec.Learners
+}
+
+// ReplicaPriority returns the leader election priority requested for the replica.
+func (v *KeeperCluster) ReplicaPriority(id KeeperReplicaID) (int32, bool) {
+	for _, priority := range v.Spec.LeaderPlacement.Priorities {
+		if priority.ReplicaID == id {
+			return priority.Priority, true
+		}
+	}
+
+	return 0, false
+}
+
+// TotalReplicas returns requested number of voting and learner replicas in the cluster.
+func (v *KeeperCluster) TotalReplicas() int32 {
+	return v.Replicas() + v.Learners()
+}
+
 const (
 	KeeperConfigMapNameSuffix          = "configmap"
 	latestKeeperConfigMapVersion       = 1
@@ -267,8 +626,8 @@ func (v *KeeperCluster) HostnameByID(id KeeperReplicaID) string {
 
 // Hostnames returns list of domain names for all replicas to access within Kubernetes cluster.
 func (v *KeeperCluster) Hostnames() []string {
-	hostnames := make([]string, 0, v.Replicas())
-	for id := range KeeperReplicaID(v.Replicas()) {
+	hostnames := make([]string, 0, v.TotalReplicas())
+	for id := range KeeperReplicaID(v.TotalReplicas()) {
 		hostnames = append(hostnames, v.HostnameByID(id))
 	}
 
@@ -5,7 +5,12 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
 const testDefaultClusterDomain = "cluster.local"
@@ -54,6 +59,26 @@ var _ = Describe("KeeperCluster", func() {
 			Expect(hostname).To(Equal("test-keeper-2-0.test-keeper-headless.test-ns.svc.internal.corp.example.com"))
 		})
 	})
+
+	Describe("AllowsNamespace", func() {
+		It("should allow only own namespace by default", func() {
+			keeper := &KeeperCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"}}
+			Expect(keeper.AllowsNamespace("test-ns")).To(BeTrue())
+			Expect(keeper.AllowsNamespace("other")).To(BeFalse())
+		})
+
+		It("should allow listed namespaces", func() {
+			keeper := &KeeperCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"},
+				Spec:       KeeperClusterSpec{AllowedNamespaces: []string{"tenant"}},
+			}
+			Expect(keeper.AllowsNamespace("tenant")).To(BeTrue())
+			Expect(keeper.AllowsNamespace("other")).To(BeFalse())
+
+			keeper.Spec.AllowedNamespaces = []string{AllNamespaces}
+			Expect(keeper.AllowsNamespace("other")).To(BeTrue())
+		})
+	})
 })
 
 var _ = Describe("ClickHouseCluster", func() {
@@ -94,4 +119,196 @@ var _ = Describe("ClickHouseCluster", func() {
 			Expect(hostname).To(Equal("test-clickhouse-0-1-0.test-clickhouse-headless.test-ns.svc.internal.corp.example.com"))
 		})
 	})
+
+	Describe("KeeperClusterRef", func() {
+		It("should isolate cluster using KeeperCluster in the same namespace", func() {
+			cluster := &ClickHouseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"},
+				Spec: ClickHouseClusterSpec{
+					KeeperClusterRef: &KeeperClusterReference{Name: "keeper"},
+				},
+			}
+			Expect(cluster.KeeperClusterNamespacedName()).To(Equal(types.NamespacedName{Namespace: "test-ns", Name: "keeper"}))
+			Expect(cluster.KeeperRoot()).To(Equal("/clusters/test-ns/test"))
+		})
+
+		It("should keep the root used by the existing cluster", func() {
+			cluster := &ClickHouseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"},
+				Spec: ClickHouseClusterSpec{
+					KeeperClusterRef: &KeeperClusterReference{Name: "keeper"},
+				},
+			}
+			cluster.Annotations = map[string]string{controllerutil.AnnotationKeeperRoot: KeeperRootNone}
+			Expect(cluster.KeeperRoot()).To(BeEmpty())
+
+			cluster.Annotations[controllerutil.AnnotationKeeperRoot] = "/tenants/old"
+			Expect(cluster.KeeperRoot()).To(Equal("/tenants/old"))
+		})
+
+		It("should isolate cluster using KeeperCluster from another namespace", func() {
+			cluster := &ClickHouseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"},
+				Spec: ClickHouseClusterSpec{
+					KeeperClusterRef: &KeeperClusterReference{Name: "keeper", Namespace: "platform"},
+				},
+			}
+			Expect(cluster.KeeperClusterNamespacedName()).To(Equal(types.NamespacedName{Namespace: "platform", Name: "keeper"}))
+			Expect(cluster.KeeperRoot()).To(Equal("/clusters/test-ns/test"))
+		})
+
+		It("should prefer explicit root", func() {
+			cluster := &ClickHouseCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ns"},
+				Spec: ClickHouseClusterSpec{
+					KeeperClusterRef: &KeeperClusterReference{Name: "keeper"},
+					Coordination:     CoordinationSpec{Root: "/tenants/test"},
+				},
+			}
+			Expect(cluster.KeeperRoot()).To(Equal("/tenants/test"))
+		})
+	})
+})
+
+var _ = Describe("ClickHouseProtocolsSpec", func() {
+	It("should enable plain protocols by default", func() {
+		spec := ClickHouseProtocolsSpec{}
+		Expect(spec.EnabledPorts(ClusterTLSSpec{})).To(Equal(map[string]int32{
+			"http": DefaultClickHouseHTTPPort,
+			"tcp":  DefaultClickHouseNativePort,
+		}))
+		Expect(spec.Validate(ClusterTLSSpec{})).To(Succeed())
+	})
+
+	It("should enable only secure protocols if TLS is required", func() {
+		spec := ClickHouseProtocolsSpec{}
+		Expect(spec.EnabledPorts(ClusterTLSSpec{Enabled: true, Required: true})).To(Equal(map[string]int32{
+			"httpSecure": DefaultClickHouseHTTPSecurePort,
+			"tcpSecure":  DefaultClickHouseNativeSecurePort,
+		}))
+	})
+
+	It("should reject insecure protocols if TLS is required", func() {
+		spec := ClickHouseProtocolsSpec{TCP: ProtocolSpec{Enabled: ptr.To(true)}}
+		Expect(spec.Validate(ClusterTLSSpec{Enabled: true, Required: true})).ToNot(Succeed())
+	})
+
+	It("should require HTTP endpoint for health checks", func() {
+		spec := ClickHouseProtocolsSpec{HTTP: ProtocolSpec{Enabled: ptr.To(false)}}
+		Expect(spec.Validate(ClusterTLSSpec{})).ToNot(Succeed())
+	})
+
+	It("should reject conflicting ports", func() {
+		spec := ClickHouseProtocolsSpec{MySQL: ProtocolSpec{Enabled: ptr.To(true), Port: ptr.To[int32](DefaultClickHouseNativePort)}}
+		Expect(spec.Validate(ClusterTLSSpec{})).To(MatchError(ContainSubstring("same port")))
+	})
+})
+
+var _ = Describe("ExternalKeeperSpec", func() {
+	It("should accept host:port nodes", func() {
+		spec := ExternalKeeperSpec{Nodes: []string{"zk-0.zk:2181", "[::1]:2181"}}
+		Expect(spec.Validate()).To(Succeed())
+	})
+
+	It("should reject nodes without valid port", func() {
+		spec := ExternalKeeperSpec{Nodes: []string{"zk-0.zk"}}
+		Expect(spec.Validate()).To(MatchError(ContainSubstring("invalid node")))
+
+		spec.Nodes = []string{"zk-0.zk:99999"}
+		Expect(spec.Validate()).To(MatchError(ContainSubstring("port must be a number")))
+	})
+
+})
+
+var _ = Describe("CoordinationSpec", func() {
+	It("should accept absolute root", func() {
+		spec := CoordinationSpec{Root: "/clickhouse/prod"}
+		Expect(spec.Validate()).To(Succeed())
+	})
+
+	It("should reject root with empty path segments", func() {
+		for _, root := range []string{"clickhouse", "/clickhouse/", "/clickhouse//prod"} {
+			spec := CoordinationSpec{Root: root}
+			Expect(spec.Validate()).To(MatchError(ContainSubstring("root")), root)
+		}
+	})
+})
+
+var _ = Describe("KeeperSettings", func() {
+	It("should accept default and explicit four letter word lists", func() {
+		settings := KeeperSettings{}
+		Expect(settings.Validate()).To(Succeed())
+
+		settings.FourLetterWordAllowList = []string{"mntr", "rqld", "ruok"}
+		Expect(settings.Validate()).To(Succeed())
+
+		settings.FourLetterWordAllowList = []string{AllFourLetterWords}
+		Expect(settings.Validate()).To(Succeed())
+	})
+
+	It("should require mntr command", func() {
+		settings := KeeperSettings{FourLetterWordAllowList: []string{"ruok"}}
+		Expect(settings.Validate()).To(MatchError(ContainSubstring(`must include "mntr"`)))
+	})
+
+	It("should reject invalid commands", func() {
+		settings := KeeperSettings{FourLetterWordAllowList: []string{"mntr", "MNTR", "stats"}}
+		err := settings.Validate()
+		Expect(err).To(MatchError(ContainSubstring(`invalid command "MNTR"`)))
+		Expect(err).To(MatchError(ContainSubstring(`invalid command "stats"`)))
+	})
+})
+
+var _ = Describe("ClickHouseSchemaObject", func() {
+	table := ClickHouseSchemaObject{
+		Kind:     SchemaObjectKindTable,
+		Database: "analytics",
+		Name:     "events",
+		Query:    "CREATE TABLE IF NOT EXISTS analytics.`events` (id UInt64) ENGINE = ReplicatedMergeTree ORDER BY id",
+	}
+
+	It("should accept matching queries", func() {
+		Expect(table.Validate()).To(Succeed())
+
+		database := ClickHouseSchemaObject{
+			Kind:     SchemaObjectKindDatabase,
+			Database: "analytics",
+			Query:    "create database analytics ENGINE = Atomic",
+		}
+		Expect(database.Validate()).To(Succeed())
+
+		view := ClickHouseSchemaObject{
+			Kind:     SchemaObjectKindMaterializedView,
+			Database: "analytics",
+			Name:     "events_mv",
+			Query:    "CREATE MATERIALIZED  VIEW analytics.events_mv TO analytics.events AS SELECT id FROM analytics.raw",
+		}
+		Expect(view.Validate()).To(Succeed())
+	})
+
+	It("should reject mismatching queries", func() {
+		object := table
+		object.Kind = SchemaObjectKindDictionary
+		Expect(object.Validate()).To(MatchError(ContainSubstring("CREATE DICTIONARY")))
+
+		object = table
+		object.Query = "CREATE TABLE events (id UInt64) ENGINE = MergeTree ORDER BY id"
+		Expect(object.Validate()).To(MatchError(ContainSubstring("database qualified name")))
+
+		object = table
+		object.Query = "CREATE TABLE analytics.events ON CLUSTER default (id UInt64) ENGINE = MergeTree ORDER BY id"
+		Expect(object.Validate()).To(MatchError(ContainSubstring("ON CLUSTER")))
+	})
+
+	It("should rewrite the query header", func() {
+		Expect(table.QueryOnCluster("default")).To(Equal(
+			"CREATE TABLE IF NOT EXISTS analytics.`events` ON CLUSTER `default` (id UInt64) ENGINE = ReplicatedMergeTree ORDER BY id"))
+		Expect(table.QueryWithoutIfNotExists()).To(Equal(
+			"CREATE TABLE analytics.`events` (id UInt64) ENGINE = ReplicatedMergeTree ORDER BY id"))
+	})
+
+	It("should reject duplicate objects", func() {
+		spec := ClickHouseSchemaSpec{ClusterRef: corev1.LocalObjectReference{Name: "sample"}, Objects: []ClickHouseSchemaObject{table, table}}
+		Expect(spec.Validate()).To(MatchError(ContainSubstring("declared more than once")))
+	})
 })
@@ -26,6 +26,51 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertsSpec) DeepCopyInto(out *AlertsSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AlertLabels != nil {
+		in, out := &in.AlertLabels, &out.AlertLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertsSpec.
+func (in *AlertsSpec) DeepCopy() *AlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseAlertsSpec) DeepCopyInto(out *ClickHouseAlertsSpec) {
+	*out = *in
+	in.AlertsSpec.DeepCopyInto(&out.AlertsSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseAlertsSpec.
+func (in *ClickHouseAlertsSpec) DeepCopy() *ClickHouseAlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseAlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClickHouseCluster) DeepCopyInto(out *ClickHouseCluster) {
 	*out = *in
@@ -100,9 +145,10 @@ func (in *ClickHouseClusterSpec) DeepCopyInto(out *ClickHouseClusterSpec) {
 	}
 	if in.KeeperClusterRef != nil {
 		in, out := &in.KeeperClusterRef, &out.KeeperClusterRef
-		*out = new(v1.LocalObjectReference)
+		*out = new(KeeperClusterReference)
 		**out = **in
 	}
+	in.Coordination.DeepCopyInto(&out.Coordination)
 	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
 	in.ContainerTemplate.DeepCopyInto(&out.ContainerTemplate)
 	if in.DataVolumeClaimSpec != nil {
@@ -125,6 +171,9 @@ func (in *ClickHouseClusterSpec) DeepCopyInto(out *ClickHouseClusterSpec) {
 		}
 	}
 	in.Settings.DeepCopyInto(&out.Settings)
+	in.Monitoring.DeepCopyInto(&out.Monitoring)
+	in.Services.DeepCopyInto(&out.Services)
+	out.NetworkPolicy = in.NetworkPolicy
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseClusterSpec.
@@ -147,6 +196,11 @@ func (in *ClickHouseClusterStatus) DeepCopyInto(out *ClickHouseClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DistributedDDLQueue != nil {
+		in, out := &in.DistributedDDLQueue, &out.DistributedDDLQueue
+		*out = new(DistributedDDLQueueStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseClusterStatus.
@@ -159,6 +213,202 @@ func (in *ClickHouseClusterStatus) DeepCopy() *ClickHouseClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseMonitoringSpec) DeepCopyInto(out *ClickHouseMonitoringSpec) {
+	*out = *in
+	in.PodMonitor.DeepCopyInto(&out.PodMonitor)
+	in.Alerts.DeepCopyInto(&out.Alerts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseMonitoringSpec.
+func (in *ClickHouseMonitoringSpec) DeepCopy() *ClickHouseMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseProtocolsSpec) DeepCopyInto(out *ClickHouseProtocolsSpec) {
+	*out = *in
+	in.HTTP.DeepCopyInto(&out.HTTP)
+	in.TCP.DeepCopyInto(&out.TCP)
+	in.HTTPSecure.DeepCopyInto(&out.HTTPSecure)
+	in.TCPSecure.DeepCopyInto(&out.TCPSecure)
+	in.TCPProxy.DeepCopyInto(&out.TCPProxy)
+	in.MySQL.DeepCopyInto(&out.MySQL)
+	in.PostgreSQL.DeepCopyInto(&out.PostgreSQL)
+	in.GRPC.DeepCopyInto(&out.GRPC)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseProtocolsSpec.
+func (in *ClickHouseProtocolsSpec) DeepCopy() *ClickHouseProtocolsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseProtocolsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseSchema) DeepCopyInto(out *ClickHouseSchema) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseSchema.
+func (in *ClickHouseSchema) DeepCopy() *ClickHouseSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClickHouseSchema) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseSchemaList) DeepCopyInto(out *ClickHouseSchemaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClickHouseSchema, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseSchemaList.
+func (in *ClickHouseSchemaList) DeepCopy() *ClickHouseSchemaList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseSchemaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClickHouseSchemaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseSchemaObject) DeepCopyInto(out *ClickHouseSchemaObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseSchemaObject.
+func (in *ClickHouseSchemaObject) DeepCopy() *ClickHouseSchemaObject {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseSchemaObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseSchemaObjectStatus) DeepCopyInto(out *ClickHouseSchemaObjectStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseSchemaObjectStatus.
+func (in *ClickHouseSchemaObjectStatus) DeepCopy() *ClickHouseSchemaObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseSchemaObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseSchemaSpec) DeepCopyInto(out *ClickHouseSchemaSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ClickHouseSchemaObject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseSchemaSpec.
+func (in *ClickHouseSchemaSpec) DeepCopy() *ClickHouseSchemaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseSchemaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseSchemaStatus) DeepCopyInto(out *ClickHouseSchemaStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ClickHouseSchemaObjectStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseSchemaStatus.
+func (in *ClickHouseSchemaStatus) DeepCopy() *ClickHouseSchemaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseSchemaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseServicesSpec) DeepCopyInto(out *ClickHouseServicesSpec) {
+	*out = *in
+	in.Cluster.DeepCopyInto(&out.Cluster)
+	in.Shard.DeepCopyInto(&out.Shard)
+	in.Ingress.DeepCopyInto(&out.Ingress)
+	in.Gateway.DeepCopyInto(&out.Gateway)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseServicesSpec.
+func (in *ClickHouseServicesSpec) DeepCopy() *ClickHouseServicesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseServicesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClickHouseSettings) DeepCopyInto(out *ClickHouseSettings) {
 	*out = *in
@@ -169,6 +419,14 @@ func (in *ClickHouseSettings) DeepCopyInto(out *ClickHouseSettings) {
 	}
 	in.Logger.DeepCopyInto(&out.Logger)
 	in.TLS.DeepCopyInto(&out.TLS)
+	in.Protocols.DeepCopyInto(&out.Protocols)
+	if in.ShutdownDrainTimeoutSeconds != nil {
+		in, out := &in.ShutdownDrainTimeoutSeconds, &out.ShutdownDrainTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	out.DistributedDDL = in.DistributedDDL
+	out.ReplicaHealth = in.ReplicaHealth
 	in.ExtraConfig.DeepCopyInto(&out.ExtraConfig)
 	in.ExtraUsersConfig.DeepCopyInto(&out.ExtraUsersConfig)
 }
@@ -274,6 +532,26 @@ func (in *ContainerTemplateSpec) DeepCopy() *ContainerTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoordinationSpec) DeepCopyInto(out *CoordinationSpec) {
+	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalKeeperSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoordinationSpec.
+func (in *CoordinationSpec) DeepCopy() *CoordinationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CoordinationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultPasswordSelector) DeepCopyInto(out *DefaultPasswordSelector) {
 	*out = *in
@@ -300,78 +578,92 @@ func (in *DefaultPasswordSelector) DeepCopy() *DefaultPasswordSelector {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KeeperCluster) DeepCopyInto(out *KeeperCluster) {
+func (in *DistributedDDLQueueStatus) DeepCopyInto(out *DistributedDDLQueueStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.OldestPendingTaskTime != nil {
+		in, out := &in.OldestPendingTaskTime, &out.OldestPendingTaskTime
+		*out = (*in).DeepCopy()
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperCluster.
-func (in *KeeperCluster) DeepCopy() *KeeperCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistributedDDLQueueStatus.
+func (in *DistributedDDLQueueStatus) DeepCopy() *DistributedDDLQueueStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(KeeperCluster)
+	out := new(DistributedDDLQueueStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KeeperCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistributedDDLSpec) DeepCopyInto(out *DistributedDDLSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistributedDDLSpec.
+func (in *DistributedDDLSpec) DeepCopy() *DistributedDDLSpec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(DistributedDDLSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KeeperClusterList) DeepCopyInto(out *KeeperClusterList) {
+func (in *ExternalKeeperSpec) DeepCopyInto(out *ExternalKeeperSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KeeperCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuthSecret != nil {
+		in, out := &in.AuthSecret, &out.AuthSecret
+		*out = new(SecretKeySelector)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperClusterList.
-func (in *KeeperClusterList) DeepCopy() *KeeperClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalKeeperSpec.
+func (in *ExternalKeeperSpec) DeepCopy() *ExternalKeeperSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KeeperClusterList)
+	out := new(ExternalKeeperSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KeeperClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayParentReference) DeepCopyInto(out *GatewayParentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParentReference.
+func (in *GatewayParentReference) DeepCopy() *GatewayParentReference {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(GatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KeeperClusterSpec) DeepCopyInto(out *KeeperClusterSpec) {
+func (in *GatewayRouteSpec) DeepCopyInto(out *GatewayRouteSpec) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]GatewayParentReference, len(*in))
+		copy(*out, *in)
 	}
-	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
-	in.ContainerTemplate.DeepCopyInto(&out.ContainerTemplate)
-	if in.DataVolumeClaimSpec != nil {
-		in, out := &in.DataVolumeClaimSpec, &out.DataVolumeClaimSpec
-		*out = new(v1.PersistentVolumeClaimSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
@@ -387,11 +679,251 @@ func (in *KeeperClusterSpec) DeepCopyInto(out *KeeperClusterSpec) {
 			(*out)[key] = val
 		}
 	}
-	in.Settings.DeepCopyInto(&out.Settings)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperClusterSpec.
-func (in *KeeperClusterSpec) DeepCopy() *KeeperClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteSpec.
+func (in *GatewayRouteSpec) DeepCopy() *GatewayRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressSpec) DeepCopyInto(out *IngressSpec) {
+	*out = *in
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLSSecret != nil {
+		in, out := &in.TLSSecret, &out.TLSSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressSpec.
+func (in *IngressSpec) DeepCopy() *IngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperAlertsSpec) DeepCopyInto(out *KeeperAlertsSpec) {
+	*out = *in
+	in.AlertsSpec.DeepCopyInto(&out.AlertsSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperAlertsSpec.
+func (in *KeeperAlertsSpec) DeepCopy() *KeeperAlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperAlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperBootstrapObjectStorage) DeepCopyInto(out *KeeperBootstrapObjectStorage) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	out.Image = in.Image
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperBootstrapObjectStorage.
+func (in *KeeperBootstrapObjectStorage) DeepCopy() *KeeperBootstrapObjectStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperBootstrapObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperBootstrapSpec) DeepCopyInto(out *KeeperBootstrapSpec) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(v1.PersistentVolumeClaimVolumeSource)
+		**out = **in
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(KeeperBootstrapObjectStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpectedZnodeCount != nil {
+		in, out := &in.ExpectedZnodeCount, &out.ExpectedZnodeCount
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperBootstrapSpec.
+func (in *KeeperBootstrapSpec) DeepCopy() *KeeperBootstrapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperBootstrapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperCluster) DeepCopyInto(out *KeeperCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperCluster.
+func (in *KeeperCluster) DeepCopy() *KeeperCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeeperCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperClusterList) DeepCopyInto(out *KeeperClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KeeperCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperClusterList.
+func (in *KeeperClusterList) DeepCopy() *KeeperClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeeperClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperClusterReference) DeepCopyInto(out *KeeperClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperClusterReference.
+func (in *KeeperClusterReference) DeepCopy() *KeeperClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperClusterSpec) DeepCopyInto(out *KeeperClusterSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Learners != nil {
+		in, out := &in.Learners, &out.Learners
+		*out = new(int32)
+		**out = **in
+	}
+	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
+	in.ContainerTemplate.DeepCopyInto(&out.ContainerTemplate)
+	if in.DataVolumeClaimSpec != nil {
+		in, out := &in.DataVolumeClaimSpec, &out.DataVolumeClaimSpec
+		*out = new(v1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Settings.DeepCopyInto(&out.Settings)
+	in.Monitoring.DeepCopyInto(&out.Monitoring)
+	out.NetworkPolicy = in.NetworkPolicy
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Storage = in.Storage
+	in.LeaderPlacement.DeepCopyInto(&out.LeaderPlacement)
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(KeeperBootstrapSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperClusterSpec.
+func (in *KeeperClusterSpec) DeepCopy() *KeeperClusterSpec {
 	if in == nil {
 		return nil
 	}
@@ -422,11 +954,89 @@ func (in *KeeperClusterStatus) DeepCopy() *KeeperClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperCoordinationSettings) DeepCopyInto(out *KeeperCoordinationSettings) {
+	*out = *in
+	if in.ForceSync != nil {
+		in, out := &in.ForceSync, &out.ForceSync
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperCoordinationSettings.
+func (in *KeeperCoordinationSettings) DeepCopy() *KeeperCoordinationSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperCoordinationSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperLeaderPlacementSpec) DeepCopyInto(out *KeeperLeaderPlacementSpec) {
+	*out = *in
+	if in.Priorities != nil {
+		in, out := &in.Priorities, &out.Priorities
+		*out = make([]KeeperReplicaPriority, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperLeaderPlacementSpec.
+func (in *KeeperLeaderPlacementSpec) DeepCopy() *KeeperLeaderPlacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperLeaderPlacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperMonitoringSpec) DeepCopyInto(out *KeeperMonitoringSpec) {
+	*out = *in
+	in.PodMonitor.DeepCopyInto(&out.PodMonitor)
+	in.Alerts.DeepCopyInto(&out.Alerts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperMonitoringSpec.
+func (in *KeeperMonitoringSpec) DeepCopy() *KeeperMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperReplicaPriority) DeepCopyInto(out *KeeperReplicaPriority) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperReplicaPriority.
+func (in *KeeperReplicaPriority) DeepCopy() *KeeperReplicaPriority {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperReplicaPriority)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeeperSettings) DeepCopyInto(out *KeeperSettings) {
 	*out = *in
 	in.Logger.DeepCopyInto(&out.Logger)
 	in.TLS.DeepCopyInto(&out.TLS)
+	in.Coordination.DeepCopyInto(&out.Coordination)
+	if in.FourLetterWordAllowList != nil {
+		in, out := &in.FourLetterWordAllowList, &out.FourLetterWordAllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.ExtraConfig.DeepCopyInto(&out.ExtraConfig)
 }
 
@@ -440,6 +1050,21 @@ func (in *KeeperSettings) DeepCopy() *KeeperSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeeperStorageSpec) DeepCopyInto(out *KeeperStorageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeeperStorageSpec.
+func (in *KeeperStorageSpec) DeepCopy() *KeeperStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeeperStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoggerConfig) DeepCopyInto(out *LoggerConfig) {
 	*out = *in
@@ -460,6 +1085,68 @@ func (in *LoggerConfig) DeepCopy() *LoggerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringTLSSpec) DeepCopyInto(out *MonitoringTLSSpec) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringTLSSpec.
+func (in *MonitoringTLSSpec) DeepCopy() *MonitoringTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicySpec) DeepCopyInto(out *NetworkPolicySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicySpec.
+func (in *NetworkPolicySpec) DeepCopy() *NetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodMonitorSpec) DeepCopyInto(out *PodMonitorSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(MonitoringTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodMonitorSpec.
+func (in *PodMonitorSpec) DeepCopy() *PodMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodTemplateSpec) DeepCopyInto(out *PodTemplateSpec) {
 	*out = *in
@@ -533,6 +1220,46 @@ func (in *PodTemplateSpec) DeepCopy() *PodTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtocolSpec) DeepCopyInto(out *ProtocolSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtocolSpec.
+func (in *ProtocolSpec) DeepCopy() *ProtocolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtocolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaHealthSpec) DeepCopyInto(out *ReplicaHealthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaHealthSpec.
+func (in *ReplicaHealthSpec) DeepCopy() *ReplicaHealthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaHealthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -547,3 +1274,37 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceTemplateSpec) DeepCopyInto(out *ServiceTemplateSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceTemplateSpec.
+func (in *ServiceTemplateSpec) DeepCopy() *ServiceTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
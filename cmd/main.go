@@ -8,6 +8,7 @@ import (
 	"os"
 
 	clickhousecomv1alpha1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
 	"github.com/ClickHouse/clickhouse-operator/internal/controller/clickhouse"
 	"github.com/ClickHouse/clickhouse-operator/internal/controller/keeper"
 	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
@@ -20,6 +21,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/go-logr/zapr"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -27,9 +29,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 var (
@@ -40,6 +45,9 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(clickhousecomv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1alpha2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -59,6 +67,7 @@ func run() error {
 		probeAddr                                        string
 		secureMetrics                                    bool
 		enableHTTP2                                      bool
+		enableKubeletVolumeStats                         bool
 		tlsOpts                                          []func(*tls.Config)
 	)
 
@@ -79,6 +88,9 @@ func run() error {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableKubeletVolumeStats, "enable-kubelet-volume-stats", false,
+		"If set, the Keeper data volume usage is read from the kubelet stats summary. "+
+			"Requires the get permission on nodes/proxy.")
 
 	opts := zap.Options{
 		Development: true,
@@ -166,6 +178,10 @@ func run() error {
 		setupLog.Info("Watching all namespaces")
 	}
 
+	if env.OperatorNamespace == "" {
+		setupLog.Info("Operator namespace is not set, generated NetworkPolicies will not allow operator connections")
+	}
+
 	mgr, err := ctrl.NewManager(config, mgrOptions)
 	if err != nil {
 		return fmt.Errorf("unable to start manager: %w", err)
@@ -173,14 +189,22 @@ func run() error {
 
 	zapLogger := controllerutil.NewLogger(logger)
 
-	if err = keeper.SetupWithManager(mgr, zapLogger); err != nil {
+	if err = chctrl.RegisterMetrics(ctrlmetrics.Registry); err != nil {
+		return fmt.Errorf("unable to register operator metrics: %w", err)
+	}
+
+	if err = keeper.SetupWithManager(mgr, zapLogger, env.OperatorNamespace, enableKubeletVolumeStats); err != nil {
 		return fmt.Errorf("unable to setup KeeperCluster controller: %w", err)
 	}
 
-	if err = clickhouse.SetupWithManager(mgr, zapLogger); err != nil {
+	if err = clickhouse.SetupWithManager(mgr, zapLogger, env.OperatorNamespace); err != nil {
 		return fmt.Errorf("unable to setup ClickHouseCluster controller: %w", err)
 	}
 
+	if err = clickhouse.SetupSchemaWithManager(mgr, zapLogger); err != nil {
+		return fmt.Errorf("unable to setup ClickHouseSchema controller: %w", err)
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if env.EnableWebhooks {
@@ -191,6 +215,10 @@ func run() error {
 		if err = whchv1.SetupClickHouseWebhookWithManager(mgr, zapLogger); err != nil {
 			return fmt.Errorf("unable to setup ClickHouseCluster webhook: %w", err)
 		}
+
+		if err = whchv1.SetupClickHouseSchemaWebhookWithManager(mgr, zapLogger); err != nil {
+			return fmt.Errorf("unable to setup ClickHouseSchema webhook: %w", err)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
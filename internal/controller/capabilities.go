@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"fmt"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// Capabilities describes optional APIs installed in the Kubernetes cluster.
+type Capabilities struct {
+	// PodMonitor is true if the Prometheus Operator PodMonitor CRD is installed.
+	PodMonitor bool
+	// PrometheusRule is true if the Prometheus Operator PrometheusRule CRD is installed.
+	PrometheusRule bool
+	// HTTPRoute is true if the Gateway API HTTPRoute CRD is installed.
+	HTTPRoute bool
+	// TLSRoute is true if the Gateway API TLSRoute CRD is installed.
+	TLSRoute bool
+	// BackendTLSPolicy is true if the Gateway API BackendTLSPolicy CRD is installed.
+	BackendTLSPolicy bool
+}
+
+// DetectCapabilities checks which optional APIs are served by the Kubernetes API server.
+func DetectCapabilities(mapper meta.RESTMapper) (Capabilities, error) {
+	podMonitor, err := isKindServed(mapper, monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.PodMonitorsKind))
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	prometheusRule, err := isKindServed(mapper, monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.PrometheusRuleKind))
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	httpRoute, err := isKindServed(mapper, gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute"))
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	tlsRoute, err := isKindServed(mapper, gatewayv1alpha2.SchemeGroupVersion.WithKind("TLSRoute"))
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	backendTLSPolicy, err := isKindServed(mapper, gatewayv1.SchemeGroupVersion.WithKind("BackendTLSPolicy"))
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	return Capabilities{
+		PodMonitor:       podMonitor,
+		PrometheusRule:   prometheusRule,
+		HTTPRoute:        httpRoute,
+		TLSRoute:         tlsRoute,
+		BackendTLSPolicy: backendTLSPolicy,
+	}, nil
+}
+
+func isKindServed(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get REST mapping for %s: %w", gvk.String(), err)
+	}
+
+	return true, nil
+}
@@ -5,13 +5,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
 	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
@@ -45,8 +50,60 @@ HAVING
 	OR replica_id >= ?
 SETTINGS
 	skip_unavailable_shards=1`
+	// Tables of Replicated databases are synced by the database replication.
+	listReplicatedTablesQuery = `SELECT
+	t.database AS database,
+	t.name AS name,
+	t.create_table_query AS create_table_query,
+	d.uuid AS database_uuid,
+	d.engine_full AS database_engine
+FROM system.tables AS t
+INNER JOIN system.databases AS d ON t.database = d.name
+WHERE
+	d.engine = 'Atomic'
+	AND t.engine LIKE 'Replicated%'
+	AND t.database NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA')
+SETTINGS
+	show_table_uuid_in_table_create_query_if_not_nil=1,
+	format_display_secrets_in_show_and_select=1`
 	createDefaultDatabaseQuery = `CREATE DATABASE IF NOT EXISTS default UUID ? 
 		ENGINE=Replicated('/clickhouse/databases/default', '{shard}', '{replica}')`
+	// Only locally available columns are selected to avoid Keeper requests for every table.
+	replicatedTablesHealthQuery = `SELECT
+	toUInt64(countIf(is_readonly)) AS readonly_tables,
+	toUInt64(max(absolute_delay)) AS max_absolute_delay,
+	toUInt64(max(queue_size)) AS max_queue_size
+FROM system.replicas`
+	databaseEngineQuery          = `SELECT engine FROM system.databases WHERE name = ?`
+	renamedDefaultDatabasesQuery = `SELECT name FROM system.databases WHERE name LIKE ?`
+	renamedDefaultTablesQuery    = `SELECT DISTINCT database FROM system.tables WHERE database LIKE ? ORDER BY database`
+	tableExistsQuery             = `SELECT count() FROM system.tables WHERE database = ? AND name = ?`
+	formatSingleLineQuery        = `SELECT formatQuerySingleLine(?)`
+	// Older ClickHouse versions report only the established connections and have no is_expired column.
+	keeperSessionExpiryColumnQuery = `SELECT count() FROM system.columns
+WHERE
+	database = 'system'
+	AND table = 'zookeeper_connection'
+	AND name = 'is_expired'`
+	keeperSessionsQuery    = `SELECT count() FROM system.zookeeper_connection WHERE NOT is_expired`
+	keeperConnectionsQuery = `SELECT count() FROM system.zookeeper_connection`
+	// Entries finished by all hosts without errors are not interesting for the backlog.
+	ddlQueueEntriesQuery = `SELECT
+	entry,
+	min(query_create_time) AS create_time,
+	groupArrayIf(assumeNotNull(host), ifNull(toString(status), '') != 'Finished') AS pending_hosts,
+	toUInt64(countIf(status = 'Active')) AS active_hosts,
+	toUInt64(countIf(ifNull(exception_code, 0) != 0)) AS failed_hosts
+FROM system.distributed_ddl_queue
+GROUP BY entry
+HAVING
+	length(pending_hosts) > 0
+	OR failed_hosts > 0`
+	ddlQueueBacklogQuery = `SELECT count()
+FROM system.distributed_ddl_queue
+WHERE
+	host = ?
+	AND status IN ('Inactive', 'Active')`
 )
 
 type databaseDescriptor struct {
@@ -56,6 +113,75 @@ type databaseDescriptor struct {
 	IsReplicated bool   `ch:"is_replicated"`
 }
 
+// tableDescriptor describes a replicated table of a non-Replicated database.
+type tableDescriptor struct {
+	Database       string `ch:"database"`
+	Name           string `ch:"name"`
+	CreateQuery    string `ch:"create_table_query"`
+	DatabaseUUID   string `ch:"database_uuid"`
+	DatabaseEngine string `ch:"database_engine"`
+}
+
+// FullName returns the table name qualified with the database.
+func (t tableDescriptor) FullName() string {
+	return fmt.Sprintf("`%s`.`%s`", t.Database, t.Name)
+}
+
+// CreateIfNotExistsQuery returns the table create query that succeeds if the table already exists.
+func (t tableDescriptor) CreateIfNotExistsQuery() string {
+	query, found := strings.CutPrefix(t.CreateQuery, "CREATE TABLE ")
+	if !found {
+		return t.CreateQuery
+	}
+
+	return "CREATE TABLE IF NOT EXISTS " + query
+}
+
+// ddlQueueEntry describes a distributed DDL queue entry not finished by all hosts or failed on some of them.
+type ddlQueueEntry struct {
+	Entry        string    `ch:"entry"`
+	CreateTime   time.Time `ch:"create_time"`
+	PendingHosts []string  `ch:"pending_hosts"`
+	ActiveHosts  uint64    `ch:"active_hosts"`
+	FailedHosts  uint64    `ch:"failed_hosts"`
+}
+
+// replicaHealth describes the replication state reported by the replica.
+type replicaHealth struct {
+	ReadonlyTables     uint64 `ch:"readonly_tables"`
+	MaxAbsoluteDelay   uint64 `ch:"max_absolute_delay"`
+	MaxQueueSize       uint64 `ch:"max_queue_size"`
+	KeeperSessionAlive bool
+	DDLQueueBacklog    uint64
+}
+
+// Problems returns the list of failed health checks. Empty if the replica is healthy.
+func (h replicaHealth) Problems(spec v1.ReplicaHealthSpec) []string {
+	var problems []string
+	if !h.KeeperSessionAlive {
+		problems = append(problems, "no active Keeper session")
+	}
+
+	if h.ReadonlyTables > 0 {
+		problems = append(problems, fmt.Sprintf("%d read-only tables", h.ReadonlyTables))
+	}
+
+	if spec.MaxReplicationDelaySeconds > 0 && h.MaxAbsoluteDelay > uint64(spec.MaxReplicationDelaySeconds) {
+		problems = append(problems, fmt.Sprintf("replication delay %ds", h.MaxAbsoluteDelay))
+	}
+
+	if spec.MaxDistributedDDLBacklog > 0 && h.DDLQueueBacklog > uint64(spec.MaxDistributedDDLBacklog) {
+		problems = append(problems, fmt.Sprintf("%d pending distributed DDL tasks", h.DDLQueueBacklog))
+	}
+
+	return problems
+}
+
+// CaughtUp returns true if the replica has replicated almost all data from other replicas.
+func (h replicaHealth) CaughtUp(spec v1.ReplicaHealthSpec) bool {
+	return h.MaxQueueSize <= uint64(spec.CatchUpMaxQueueSize) && h.MaxAbsoluteDelay <= uint64(spec.CatchUpMaxDelaySeconds)
+}
+
 type commander struct {
 	log     controllerutil.Logger
 	cluster *v1.ClickHouseCluster
@@ -103,6 +229,42 @@ func (cmd *commander) Ping(ctx context.Context, id v1.ClickHouseReplicaID) error
 	return nil
 }
 
+// Health checks replicated tables state, Keeper session and distributed DDL queue of the replica.
+func (cmd *commander) Health(ctx context.Context, id v1.ClickHouseReplicaID) (replicaHealth, error) {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return replicaHealth{}, fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	var health replicaHealth
+	if err = conn.QueryRow(ctx, replicatedTablesHealthQuery).ScanStruct(&health); err != nil {
+		return replicaHealth{}, fmt.Errorf("query replicated tables state on replica %s: %w", id, err)
+	}
+
+	var expiryColumns uint64
+	if err = conn.QueryRow(ctx, keeperSessionExpiryColumnQuery).Scan(&expiryColumns); err != nil {
+		return replicaHealth{}, fmt.Errorf("query Keeper connection columns on replica %s: %w", id, err)
+	}
+
+	sessionsQuery := keeperConnectionsQuery
+	if expiryColumns > 0 {
+		sessionsQuery = keeperSessionsQuery
+	}
+
+	var sessions uint64
+	if err = conn.QueryRow(ctx, sessionsQuery).Scan(&sessions); err != nil {
+		return replicaHealth{}, fmt.Errorf("query Keeper sessions on replica %s: %w", id, err)
+	}
+
+	health.KeeperSessionAlive = sessions > 0
+
+	if err = conn.QueryRow(ctx, ddlQueueBacklogQuery, cmd.cluster.HostnameByID(id)).Scan(&health.DDLQueueBacklog); err != nil {
+		return replicaHealth{}, fmt.Errorf("query distributed DDL queue on replica %s: %w", id, err)
+	}
+
+	return health, nil
+}
+
 func (cmd *commander) Databases(ctx context.Context, id v1.ClickHouseReplicaID) (map[string]databaseDescriptor, error) {
 	conn, err := cmd.getConn(id)
 	if err != nil {
@@ -157,44 +319,302 @@ func (cmd *commander) CreateDatabases(ctx context.Context, id v1.ClickHouseRepli
 	return nil
 }
 
-// EnsureDefaultDatabaseEngine ensures that the default database engine is set to the Selected one.
-func (cmd *commander) EnsureDefaultDatabaseEngine(ctx context.Context, log controllerutil.Logger, cluster *v1.ClickHouseCluster, id v1.ClickHouseReplicaID) error {
-	log = log.With("replica_id", id)
+// ReplicatedTables returns replicated tables of non-Replicated databases keyed by the full table name.
+func (cmd *commander) ReplicatedTables(ctx context.Context, id v1.ClickHouseReplicaID) (map[string]tableDescriptor, error) {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	rows, err := conn.Query(ctx, listReplicatedTablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replicated tables on replica %s: %w", id, err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tables := map[string]tableDescriptor{}
+	for rows.Next() {
+		var table tableDescriptor
+		if err := rows.ScanStruct(&table); err != nil {
+			return nil, fmt.Errorf("failed to scan table row on replica %s: %w", id, err)
+		}
+
+		tables[table.FullName()] = table
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch all table rows on replica %s: %w", id, err)
+	}
+
+	return tables, nil
+}
+
+// CreateTables creates the replicated tables with their original UUIDs.
+// Missing databases of the tables are created with their original UUIDs as well.
+func (cmd *commander) CreateTables(ctx context.Context, id v1.ClickHouseReplicaID, tables map[string]tableDescriptor) error {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	createdDatabases := map[string]struct{}{}
+	for name, table := range tables {
+		if _, ok := createdDatabases[table.Database]; !ok {
+			query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` UUID '%s' ENGINE = %s",
+				table.Database, table.DatabaseUUID, table.DatabaseEngine)
+			if err = conn.Exec(ctx, query); err != nil {
+				return fmt.Errorf("failed to create database %s on replica %s: %w", table.Database, id, err)
+			}
+
+			createdDatabases[table.Database] = struct{}{}
+		}
+
+		if err = conn.Exec(ctx, table.CreateIfNotExistsQuery()); err != nil {
+			return fmt.Errorf("failed to create table %s on replica %s: %w", name, id, err)
+		}
+	}
+
+	return nil
+}
+
+// SyncTables waits for the replica to fetch the data of the given tables for at most TableSyncTimeout.
+// The fetches continue in the background after the timeout.
+func (cmd *commander) SyncTables(ctx context.Context, id v1.ClickHouseReplicaID, tables []string) error {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, TableSyncTimeout)
+	defer cancel()
+
+	for _, name := range tables {
+		if err = conn.Exec(ctx, fmt.Sprintf("SYSTEM SYNC REPLICA %s LIGHTWEIGHT", name)); err != nil {
+			return fmt.Errorf("sync replica %s on replica %s: %w", name, id, err)
+		}
+	}
+
+	return nil
+}
+
+// DistributedDDLQueue returns the unfinished and failed entries of the distributed DDL queue.
+// The queue is stored in Keeper, so any replica returns the same entries.
+func (cmd *commander) DistributedDDLQueue(ctx context.Context, id v1.ClickHouseReplicaID) ([]ddlQueueEntry, error) {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	rows, err := conn.Query(ctx, ddlQueueEntriesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query distributed DDL queue on replica %s: %w", id, err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []ddlQueueEntry
+	for rows.Next() {
+		var entry ddlQueueEntry
+		if err := rows.ScanStruct(&entry); err != nil {
+			return nil, fmt.Errorf("failed to scan distributed DDL queue row on replica %s: %w", id, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch all distributed DDL queue rows on replica %s: %w", id, err)
+	}
+
+	return entries, nil
+}
+
+// DatabaseEngine returns the engine of the database. Returns empty string if the database does not exist.
+func (cmd *commander) DatabaseEngine(ctx context.Context, id v1.ClickHouseReplicaID, database string) (string, error) {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	var engine string
+	if err = conn.QueryRow(ctx, databaseEngineQuery, database).Scan(&engine); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("query database %s engine on replica %s: %w", database, id, err)
+	}
+
+	return engine, nil
+}
 
+// ShowCreate returns the SHOW CREATE output of the schema object. Returns false if the object does not exist.
+func (cmd *commander) ShowCreate(ctx context.Context, id v1.ClickHouseReplicaID, object v1.ClickHouseSchemaObject) (string, bool, error) {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	engine, err := cmd.DatabaseEngine(ctx, id, object.Database)
+	if err != nil || engine == "" {
+		return "", false, err
+	}
+
+	keyword := "DATABASE"
+	if object.Kind != v1.SchemaObjectKindDatabase {
+		var count uint64
+		if err = conn.QueryRow(ctx, tableExistsQuery, object.Database, object.Name).Scan(&count); err != nil {
+			return "", false, fmt.Errorf("check %s exists on replica %s: %w", object.FullName(), id, err)
+		}
+
+		if count == 0 {
+			return "", false, nil
+		}
+
+		keyword = "TABLE"
+		if object.Kind == v1.SchemaObjectKindDictionary {
+			keyword = "DICTIONARY"
+		}
+	}
+
+	var query string
+	if err = conn.QueryRow(ctx, fmt.Sprintf("SHOW CREATE %s %s", keyword, object.FullName())).Scan(&query); err != nil {
+		return "", false, fmt.Errorf("show create %s on replica %s: %w", object.FullName(), id, err)
+	}
+
+	return query, true, nil
+}
+
+// FormatQuery returns the query formatted by the replica in a single line.
+func (cmd *commander) FormatQuery(ctx context.Context, id v1.ClickHouseReplicaID, query string) (string, error) {
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	var formatted string
+	if err = conn.QueryRow(ctx, formatSingleLineQuery, query).Scan(&formatted); err != nil {
+		return "", fmt.Errorf("format query on replica %s: %w", id, err)
+	}
+
+	return formatted, nil
+}
+
+// ExecSchemaQuery executes the schema change query on the replica.
+func (cmd *commander) ExecSchemaQuery(ctx context.Context, id v1.ClickHouseReplicaID, query string) error {
 	conn, err := cmd.getConn(id)
 	if err != nil {
 		return fmt.Errorf("failed to get connection for replica %s: %w", id, err)
 	}
 
+	if err = conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("execute schema query on replica %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// errDefaultDatabaseNotEmpty is returned when the non-Replicated `default` database has tables
+// and the migration policy does not allow to move them away.
+var errDefaultDatabaseNotEmpty = errors.New("database `default` is not Replicated and contains tables")
+
+// defaultDatabaseAction is the action taken on the non-Replicated `default` database.
+type defaultDatabaseAction string
+
+const (
+	defaultDatabaseUnchanged defaultDatabaseAction = ""
+	defaultDatabaseRenamed   defaultDatabaseAction = "renamed"
+	defaultDatabaseDropped   defaultDatabaseAction = "dropped"
+)
+
+// defaultDatabaseMigration describes the migration of the non-Replicated `default` database.
+type defaultDatabaseMigration struct {
+	Action defaultDatabaseAction
+	// RenamedTo is the new name of the renamed database.
+	RenamedTo string
+	// PendingRenamed are the databases renamed by the migrations which still contain tables.
+	// Their tables are not replicated until they are moved to the Replicated `default` database manually.
+	PendingRenamed []string
+}
+
+// EnsureDefaultDatabaseEngine ensures that the default database engine is set to the Replicated one.
+// The database with tables is migrated according to the cluster DefaultDatabaseMigration policy.
+func (cmd *commander) EnsureDefaultDatabaseEngine(
+	ctx context.Context,
+	log controllerutil.Logger,
+	cluster *v1.ClickHouseCluster,
+	id v1.ClickHouseReplicaID,
+) (defaultDatabaseMigration, error) {
+	log = log.With("replica_id", id)
+
+	conn, err := cmd.getConn(id)
+	if err != nil {
+		return defaultDatabaseMigration{}, fmt.Errorf("failed to get connection for replica %s: %w", id, err)
+	}
+
+	var migration defaultDatabaseMigration
+
 	var engine string
 
 	rows := conn.QueryRow(ctx, "SELECT engine FROM system.databases WHERE name='default' ")
 	if err = rows.Scan(&engine); err != nil {
 		if !errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("failed to scan default database engine for replica %s: %w", id, err)
+			return defaultDatabaseMigration{}, fmt.Errorf("failed to scan default database engine for replica %s: %w", id, err)
 		}
 
 		log.Debug("no default database found")
 	} else {
 		if engine == "Replicated" {
 			log.Debug("default database already has the Replicated engine")
-			return nil
+
+			if migration.PendingRenamed, err = pendingRenamedDefaultDatabases(ctx, conn); err != nil {
+				return defaultDatabaseMigration{}, fmt.Errorf("check renamed default databases on replica %s: %w", id, err)
+			}
+
+			return migration, nil
 		}
 
 		var count uint64
 		if err = conn.QueryRow(ctx, "SELECT COUNT() FROM system.tables WHERE database='default'").Scan(&count); err != nil {
 			log.Error(err, "error checking if database 'default' has tables")
-			return fmt.Errorf("check tables in  %s: %w", id, err)
+			return defaultDatabaseMigration{}, fmt.Errorf("check tables in  %s: %w", id, err)
 		}
 
-		if count > 0 {
-			log.Warn("database `default` has tables, but its engine is not Replicated, data loss is possible")
-		}
+		policy := cluster.Spec.Settings.DefaultDatabaseMigration
+		switch {
+		case count == 0 || policy == v1.DefaultDatabaseMigrationDrop:
+			if count > 0 {
+				log.Warn("dropping database `default` with tables", "engine", engine, "tables", count)
+				migration.Action = defaultDatabaseDropped
+			}
+
+			log.Debug("dropping default database")
+
+			if err := conn.Exec(ctx, "DROP DATABASE default SYNC"); err != nil {
+				return defaultDatabaseMigration{}, fmt.Errorf("failed to drop default database on replica %s: %w", id, err)
+			}
+		// Only Atomic databases support RENAME DATABASE.
+		case policy == v1.DefaultDatabaseMigrationRename && engine == "Atomic":
+			name, err := renamedDefaultDatabaseName(ctx, conn)
+			if err != nil {
+				return defaultDatabaseMigration{}, fmt.Errorf("choose renamed default database name on replica %s: %w", id, err)
+			}
 
-		log.Debug("dropping default database")
+			log.Info("renaming database `default` with tables", "tables", count, "name", name)
 
-		if err := conn.Exec(ctx, "DROP DATABASE default SYNC"); err != nil {
-			return fmt.Errorf("failed to drop default database on replica %s: %w", id, err)
+			if err := conn.Exec(ctx, fmt.Sprintf("RENAME DATABASE default TO `%s`", name)); err != nil {
+				return defaultDatabaseMigration{}, fmt.Errorf("failed to rename default database on replica %s: %w", id, err)
+			}
+
+			migration = defaultDatabaseMigration{Action: defaultDatabaseRenamed, RenamedTo: name}
+		default:
+			log.Info("database `default` has tables, skipping migration to the Replicated engine", "engine", engine, "tables", count)
+			return defaultDatabaseMigration{}, fmt.Errorf("%w: engine %s, %d tables", errDefaultDatabaseNotEmpty, engine, count)
 		}
 	}
 
@@ -202,10 +622,79 @@ func (cmd *commander) EnsureDefaultDatabaseEngine(ctx context.Context, log contr
 
 	defaultDatabaseUUID := uuid.NewSHA1(uuid.Nil, []byte(cluster.SpecificName())).String()
 	if err := conn.Exec(ctx, createDefaultDatabaseQuery, defaultDatabaseUUID); err != nil {
-		return fmt.Errorf("create default replicated database %s: %w", id, err)
+		return migration, fmt.Errorf("create default replicated database %s: %w", id, err)
 	}
 
-	return nil
+	if migration.PendingRenamed, err = pendingRenamedDefaultDatabases(ctx, conn); err != nil {
+		return migration, fmt.Errorf("check renamed default databases on replica %s: %w", id, err)
+	}
+
+	return migration, nil
+}
+
+// pendingRenamedDefaultDatabases returns the databases renamed by the `default` database migration
+// which still contain tables.
+func pendingRenamedDefaultDatabases(ctx context.Context, conn clickhouse.Conn) ([]string, error) {
+	rows, err := conn.Query(ctx, renamedDefaultTablesQuery, RenamedDefaultDatabaseName+"%")
+	if err != nil {
+		return nil, fmt.Errorf("query tables: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var databases []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan database name: %w", err)
+		}
+
+		databases = append(databases, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read tables: %w", err)
+	}
+
+	return databases, nil
+}
+
+// renamedDefaultDatabaseName returns the first of RenamedDefaultDatabaseName and its numbered variants not taken
+// by an existing database, so a database left by the previous migration does not block the next one.
+func renamedDefaultDatabaseName(ctx context.Context, conn clickhouse.Conn) (string, error) {
+	rows, err := conn.Query(ctx, renamedDefaultDatabasesQuery, RenamedDefaultDatabaseName+"%")
+	if err != nil {
+		return "", fmt.Errorf("query databases: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	taken := map[string]struct{}{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return "", fmt.Errorf("scan database name: %w", err)
+		}
+
+		taken[name] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("fetch database names: %w", err)
+	}
+
+	name := RenamedDefaultDatabaseName
+	for i := 1; ; i++ {
+		if _, ok := taken[name]; !ok {
+			return name, nil
+		}
+
+		name = fmt.Sprintf("%s_%d", RenamedDefaultDatabaseName, i)
+	}
 }
 
 func (cmd *commander) SyncShard(ctx context.Context, log controllerutil.Logger, shardID int32) error {
@@ -418,7 +907,80 @@ func (cmd *commander) getConn(id v1.ClickHouseReplicaID) (clickhouse.Conn, error
 		return nil, fmt.Errorf("open ClickHouse connection: %w", err)
 	}
 
-	cmd.conns[id] = conn
+	cmd.conns[id] = &instrumentedConn{Conn: conn, cluster: cmd.cluster.NamespacedName()}
+
+	return cmd.conns[id], nil
+}
+
+// instrumentedConn reports latency and errors of the operator management queries.
+// Contributors, ServerVersion, Stats and Close do not send queries and are passed through as is.
+type instrumentedConn struct {
+	clickhouse.Conn
+
+	cluster types.NamespacedName
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := c.Conn.Ping(ctx)
+	chctrl.ObserveClickHouseQuery(c.cluster, "ping", time.Since(start), err)
+
+	return err //nolint:wrapcheck // Transparent wrapper
+}
+
+func (c *instrumentedConn) Exec(ctx context.Context, query string, args ...any) error {
+	start := time.Now()
+	err := c.Conn.Exec(ctx, query, args...)
+	chctrl.ObserveClickHouseQuery(c.cluster, "exec", time.Since(start), err)
+
+	return err //nolint:wrapcheck // Transparent wrapper
+}
+
+func (c *instrumentedConn) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.Conn.Query(ctx, query, args...)
+	chctrl.ObserveClickHouseQuery(c.cluster, "query", time.Since(start), err)
+
+	return rows, err //nolint:wrapcheck // Transparent wrapper
+}
+
+func (c *instrumentedConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	start := time.Now()
+	err := c.Conn.Select(ctx, dest, query, args...)
+	chctrl.ObserveClickHouseQuery(c.cluster, "select", time.Since(start), err)
+
+	return err //nolint:wrapcheck // Transparent wrapper
+}
+
+// PrepareBatch observes only the batch preparation, the batch is sent later with its own Send call.
+func (c *instrumentedConn) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	start := time.Now()
+	batch, err := c.Conn.PrepareBatch(ctx, query, opts...)
+	chctrl.ObserveClickHouseQuery(c.cluster, "prepare_batch", time.Since(start), err)
+
+	return batch, err //nolint:wrapcheck // Transparent wrapper
+}
+
+//nolint:staticcheck // Deprecated method is a part of the wrapped interface.
+func (c *instrumentedConn) AsyncInsert(ctx context.Context, query string, wait bool, args ...any) error {
+	start := time.Now()
+	err := c.Conn.AsyncInsert(ctx, query, wait, args...)
+	chctrl.ObserveClickHouseQuery(c.cluster, "async_insert", time.Since(start), err)
+
+	return err //nolint:wrapcheck // Transparent wrapper
+}
+
+func (c *instrumentedConn) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	start := time.Now()
+	row := c.Conn.QueryRow(ctx, query, args...)
+
+	// Missing rows is a valid result, not a query failure.
+	err := row.Err()
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+
+	chctrl.ObserveClickHouseQuery(c.cluster, "query_row", time.Since(start), err)
 
-	return conn, nil
+	return row
 }
@@ -0,0 +1,326 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("ReplicaHealth", func() {
+	spec := v1.ReplicaHealthSpec{MaxReplicationDelaySeconds: 300, MaxDistributedDDLBacklog: 100}
+
+	It("should report no problems for healthy replica", func() {
+		health := replicaHealth{
+			KeeperSessionAlive: true,
+			MaxAbsoluteDelay:   300,
+			DDLQueueBacklog:    100,
+		}
+		Expect(health.Problems(spec)).To(BeEmpty())
+	})
+
+	It("should report every failed check", func() {
+		health := replicaHealth{
+			ReadonlyTables:   2,
+			MaxAbsoluteDelay: 301,
+			DDLQueueBacklog:  101,
+		}
+		Expect(health.Problems(spec)).To(HaveLen(4))
+	})
+
+	It("should use the configured replication delay threshold", func() {
+		health := replicaHealth{KeeperSessionAlive: true, MaxAbsoluteDelay: 600}
+		Expect(health.Problems(spec)).To(HaveLen(1))
+		Expect(health.Problems(v1.ReplicaHealthSpec{MaxReplicationDelaySeconds: 900})).To(BeEmpty())
+	})
+
+	It("should consider replica caught up only with small replication backlog", func() {
+		catchUp := v1.ReplicaHealthSpec{CatchUpMaxDelaySeconds: 10, CatchUpMaxQueueSize: 20}
+		health := replicaHealth{
+			KeeperSessionAlive: true,
+			MaxAbsoluteDelay:   10,
+			MaxQueueSize:       20,
+		}
+		Expect(health.CaughtUp(catchUp)).To(BeTrue())
+
+		health.MaxQueueSize++
+		Expect(health.CaughtUp(catchUp)).To(BeFalse())
+		Expect(health.Problems(spec)).To(BeEmpty())
+
+		catchUp.CatchUpMaxQueueSize = 500
+		Expect(health.CaughtUp(catchUp)).To(BeTrue())
+
+		health.MaxAbsoluteDelay++
+		Expect(health.CaughtUp(catchUp)).To(BeFalse())
+	})
+
+	It("should wait for catch up only on the restarted replica", func() {
+		state := replicaState{
+			StatefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1},
+			},
+			Pinged: true,
+		}
+		rec := &clickhouseReconciler{reconcilerBase: reconcilerBase{Cluster: &v1.ClickHouseCluster{}}}
+		Expect(state.CatchUpPending()).To(BeFalse())
+		Expect(state.UpdateStage(rec)).To(Equal(chctrl.StageUpToDate))
+
+		state.StatefulSet.Annotations = map[string]string{ctrlutil.AnnotationCatchUpPending: "2026-01-01T00:00:00Z"}
+		Expect(state.CatchUpPending()).To(BeTrue())
+		Expect(state.UpdateStage(rec)).To(Equal(chctrl.StageCatchingUp))
+
+		state.CaughtUp = true
+		Expect(state.UpdateStage(rec)).To(Equal(chctrl.StageUpToDate))
+	})
+
+	It("should stop waiting for catch up after the timeout", func() {
+		now := time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)
+		state := replicaState{StatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{ctrlutil.AnnotationCatchUpPending: "2026-01-01T00:00:00Z"},
+		}}}
+		Expect(state.CatchUpTimedOut(2*time.Hour, now)).To(BeFalse())
+		Expect(state.CatchUpTimedOut(30*time.Minute, now)).To(BeTrue())
+
+		state.CaughtUp = true
+		Expect(state.CatchUpTimedOut(30*time.Minute, now)).To(BeFalse())
+
+		state.CaughtUp = false
+		state.StatefulSet.Annotations[ctrlutil.AnnotationCatchUpPending] = "malformed"
+		Expect(state.CatchUpTimedOut(2*time.Hour, now)).To(BeTrue())
+	})
+
+	It("should not consider replica with health problems ready", func() {
+		state := replicaState{
+			StatefulSet: &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 1}},
+			Pinged:      true,
+		}
+		Expect(state.Ready()).To(BeTrue())
+
+		state.HealthProblems = []string{"no active Keeper session"}
+		Expect(state.Ready()).To(BeFalse())
+	})
+})
+
+var _ = Describe("commander.Health", func() {
+	id := v1.ClickHouseReplicaID{}
+
+	healthServer := func(hasExpiryColumn bool) *fakeConn {
+		return &fakeConn{handler: func(query string, _ ...any) ([][]any, error) {
+			switch query {
+			case replicatedTablesHealthQuery:
+				return [][]any{{replicaHealth{}}}, nil
+			case keeperSessionExpiryColumnQuery:
+				if hasExpiryColumn {
+					return [][]any{{uint64(1)}}, nil
+				}
+
+				return [][]any{{uint64(0)}}, nil
+			case keeperSessionsQuery:
+				if !hasExpiryColumn {
+					return nil, errors.New("missing columns: 'is_expired'")
+				}
+
+				return [][]any{{uint64(1)}}, nil
+			case keeperConnectionsQuery:
+				return [][]any{{uint64(1)}}, nil
+			case ddlQueueBacklogQuery:
+				return [][]any{{uint64(0)}}, nil
+			}
+
+			return nil, errors.New("unexpected query: " + query)
+		}}
+	}
+
+	for _, hasExpiryColumn := range []bool{true, false} {
+		It(fmt.Sprintf("should check Keeper session with is_expired column present: %v", hasExpiryColumn), func(ctx context.Context) {
+			cmd := newFakeCommander(&v1.ClickHouseCluster{}, map[v1.ClickHouseReplicaID]*fakeConn{id: healthServer(hasExpiryColumn)})
+
+			health, err := cmd.Health(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.KeeperSessionAlive).To(BeTrue())
+		})
+	}
+})
+
+var _ = Describe("TableDescriptor", func() {
+	It("should make create query idempotent", func() {
+		table := tableDescriptor{
+			Database:    "analytics",
+			Name:        "events",
+			CreateQuery: "CREATE TABLE analytics.events UUID '9c4e6b2d-4a8c-4a61-9d3e-2f5b8a7c1e00' (`id` UInt64) ENGINE = ReplicatedMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}') ORDER BY id",
+		}
+		Expect(table.FullName()).To(Equal("`analytics`.`events`"))
+		Expect(table.CreateIfNotExistsQuery()).To(HavePrefix("CREATE TABLE IF NOT EXISTS analytics.events UUID '9c4e6b2d-4a8c-4a61-9d3e-2f5b8a7c1e00' "))
+	})
+})
+
+var _ = Describe("instrumentedConn", func() {
+	var registry *prometheus.Registry
+
+	cluster := types.NamespacedName{Namespace: "instrumented", Name: "test"}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		Expect(chctrl.RegisterMetrics(registry)).To(Succeed())
+		DeferCleanup(chctrl.DeleteClusterMetrics, chctrl.MetricsControllerClickHouse, cluster)
+	})
+
+	queryErrors := func() int {
+		count, err := testutil.GatherAndCount(registry, "clickhouse_operator_clickhouse_query_errors_total")
+		Expect(err).NotTo(HaveOccurred())
+
+		return count
+	}
+
+	It("should not count missing rows as query error", func(ctx context.Context) {
+		conn := &instrumentedConn{Conn: &fakeConn{handler: func(string, ...any) ([][]any, error) {
+			return nil, nil
+		}}, cluster: cluster}
+
+		Expect(conn.QueryRow(ctx, "SELECT 1").Err()).To(MatchError(sql.ErrNoRows))
+		Expect(queryErrors()).To(BeZero())
+	})
+
+	It("should count failed queries by operation", func(ctx context.Context) {
+		conn := &instrumentedConn{Conn: &fakeConn{handler: func(string, ...any) ([][]any, error) {
+			return nil, errors.New("connection refused")
+		}}, cluster: cluster}
+
+		Expect(conn.Exec(ctx, "SYSTEM SYNC REPLICA")).NotTo(Succeed())
+		Expect(queryErrors()).To(Equal(1))
+
+		count, err := testutil.GatherAndCount(registry, "clickhouse_operator_clickhouse_query_duration_seconds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+	})
+})
+
+// fakeConn is a scripted ClickHouse connection. The handler returns the result rows of the query:
+// structs for ScanStruct or slices of column values for Scan.
+type fakeConn struct {
+	driver.Conn
+
+	handler func(query string, args ...any) ([][]any, error)
+
+	lock     sync.Mutex
+	executed []string
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Exec(_ context.Context, query string, args ...any) error {
+	c.lock.Lock()
+	c.executed = append(c.executed, query)
+	c.lock.Unlock()
+
+	_, err := c.handler(query, args...)
+	return err
+}
+
+func (c *fakeConn) Query(_ context.Context, query string, args ...any) (driver.Rows, error) {
+	rows, err := c.handler(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeRows{rows: rows, pos: -1}, nil
+}
+
+func (c *fakeConn) QueryRow(_ context.Context, query string, args ...any) driver.Row {
+	rows, err := c.handler(query, args...)
+	if err == nil && len(rows) == 0 {
+		err = sql.ErrNoRows
+	}
+
+	return &fakeRows{rows: rows, err: err}
+}
+
+// Executed returns the queries executed on the connection.
+func (c *fakeConn) Executed() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]string(nil), c.executed...)
+}
+
+type fakeRows struct {
+	driver.Rows
+
+	rows [][]any
+	pos  int
+	err  error
+}
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos < len(r.rows)
+}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	row := r.rows[max(r.pos, 0)]
+	for i, value := range row {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+
+	return nil
+}
+
+func (r *fakeRows) ScanStruct(dest any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	reflect.ValueOf(dest).Elem().Set(reflect.ValueOf(r.rows[max(r.pos, 0)][0]))
+	return nil
+}
+
+// newFakeCommander returns a commander using the given connections instead of the real replicas.
+func newFakeCommander(cluster *v1.ClickHouseCluster, conns map[v1.ClickHouseReplicaID]*fakeConn) *commander {
+	cmd := &commander{
+		log:     ctrlutil.NewLogger(zap.NewRaw(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true))),
+		cluster: cluster,
+		conns:   map[v1.ClickHouseReplicaID]clickhouse.Conn{},
+	}
+	for id, conn := range conns {
+		cmd.conns[id] = conn
+	}
+
+	return cmd
+}
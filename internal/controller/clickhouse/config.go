@@ -170,12 +170,15 @@ type baseConfigParams struct {
 
 	KeeperNodes       []keeperNode
 	KeeperIdentityEnv string
+	KeeperRoot        string
 
-	DistributedDDLPath        string
-	DistributedDDLProfileName string
-	UsersXMLPath              string
-	UsersZookeeperPath        string
-	UDFZookeeperPath          string
+	DistributedDDLPath            string
+	DistributedDDLProfileName     string
+	DistributedDDLTaskMaxLifetime int64
+	DistributedDDLMaxTasksInQueue int64
+	UsersXMLPath                  string
+	UsersZookeeperPath            string
+	UDFZookeeperPath              string
 
 	ClusterSecretEnv string
 	ManagementPort   uint16
@@ -194,7 +197,25 @@ type keeperNode struct {
 	Secure bool
 }
 
-func baseConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, id v1.ClickHouseReplicaID) (string, error) {
+func buildKeeperNodes(r *clickhouseReconciler) ([]keeperNode, error) {
+	if external := r.Cluster.Spec.Coordination.External; external != nil {
+		keeperNodes := make([]keeperNode, 0, len(external.Nodes))
+		for _, node := range external.Nodes {
+			host, port, err := v1.SplitKeeperNode(node)
+			if err != nil {
+				return nil, fmt.Errorf("parse external keeper node: %w", err)
+			}
+
+			keeperNodes = append(keeperNodes, keeperNode{
+				Host:   host,
+				Port:   port,
+				Secure: external.Secure,
+			})
+		}
+
+		return keeperNodes, nil
+	}
+
 	keeperNodes := make([]keeperNode, 0, r.keeper.Replicas())
 	for _, host := range r.keeper.Hostnames() {
 		if r.keeper.Spec.Settings.TLS.Enabled {
@@ -211,6 +232,15 @@ func baseConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, id v1
 		}
 	}
 
+	return keeperNodes, nil
+}
+
+func baseConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, id v1.ClickHouseReplicaID) (string, error) {
+	keeperNodes, err := buildKeeperNodes(r)
+	if err != nil {
+		return "", err
+	}
+
 	openSSL := controller.OpenSSLConfig{}
 	if r.Cluster.Spec.Settings.TLS.Enabled {
 		params := controller.OpenSSLParams{
@@ -235,6 +265,16 @@ func baseConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, id v1
 		openSSL.Client.PreferServerCiphers = true
 	}
 
+	// Secure external coordination service is verified with the system trusted CA bundle if no CA is configured.
+	if external := r.Cluster.Spec.Coordination.External; external != nil && external.Secure && openSSL.Client.CAConfig == "" {
+		openSSL.Client = controller.OpenSSLParams{
+			LoadDefaultCAFile:   true,
+			VerificationMode:    "relaxed",
+			DisableProtocols:    "sslv2,sslv3",
+			PreferServerCiphers: true,
+		}
+	}
+
 	clusterHosts := make([][]string, r.Cluster.Shards())
 	for shard := range r.Cluster.Shards() {
 		hosts := make([]string, r.Cluster.Replicas())
@@ -256,12 +296,15 @@ func baseConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, id v1
 
 		KeeperNodes:       keeperNodes,
 		KeeperIdentityEnv: EnvKeeperIdentity,
+		KeeperRoot:        r.Cluster.KeeperRoot(),
 
-		DistributedDDLPath:        KeeperPathDistributedDDL,
-		DistributedDDLProfileName: DefaultProfileName,
-		UsersXMLPath:              UsersFileName,
-		UsersZookeeperPath:        KeeperPathUsers,
-		UDFZookeeperPath:          KeeperPathUDF,
+		DistributedDDLPath:            KeeperPathDistributedDDL,
+		DistributedDDLProfileName:     DefaultProfileName,
+		DistributedDDLTaskMaxLifetime: r.Cluster.Spec.Settings.DistributedDDL.TaskMaxLifetimeSeconds,
+		DistributedDDLMaxTasksInQueue: r.Cluster.Spec.Settings.DistributedDDL.MaxTasksInQueue,
+		UsersXMLPath:                  UsersFileName,
+		UsersZookeeperPath:            KeeperPathUsers,
+		UDFZookeeperPath:              KeeperPathUDF,
 
 		ClusterSecretEnv: EnvClusterSecret,
 		ManagementPort:   PortManagement,
@@ -283,6 +326,7 @@ type networkConfigParams struct {
 	InterserverHTTPUser           string
 	InterserverHTTPPasswordEnvVar string
 	ManagementPort                uint16
+	GRPCPort                      uint16
 	Protocols                     []namedProtocol
 }
 
@@ -292,12 +336,22 @@ type namedProtocol struct {
 }
 
 func networkConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, _ v1.ClickHouseReplicaID) (string, error) {
-	var protocols []namedProtocol
+	var (
+		protocols []namedProtocol
+		grpcPort  uint16
+	)
+
 	for name, proto := range buildProtocols(r.Cluster) {
 		if name == "interserver" || name == "management" {
 			continue
 		}
 
+		// gRPC is not supported by composable protocols configuration.
+		if name == "grpc" {
+			grpcPort = proto.Port
+			continue
+		}
+
 		protocols = append(protocols, namedProtocol{
 			Name:     name,
 			Protocol: proto,
@@ -311,6 +365,7 @@ func networkConfigGenerator(tmpl *template.Template, r *clickhouseReconciler, _
 		InterserverHTTPUser:           InterserverUserName,
 		InterserverHTTPPasswordEnvVar: EnvInterserverPassword,
 		ManagementPort:                PortManagement,
+		GRPCPort:                      grpcPort,
 		Protocols:                     protocols,
 	}
 
@@ -1,6 +1,8 @@
 package clickhouse
 
 import (
+	"text/template"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v2"
@@ -9,6 +11,7 @@ import (
 	"k8s.io/utils/ptr"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
 var _ = Describe("ConfigGenerator", func() {
@@ -51,3 +54,164 @@ var _ = Describe("ConfigGenerator", func() {
 		})
 	}
 })
+
+var _ = Describe("NetworkConfig", func() {
+	It("should render enabled protocols with port overrides", func() {
+		ctx := clickhouseReconciler{
+			reconcilerBase: reconcilerBase{
+				Cluster: &v1.ClickHouseCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-cluster",
+					},
+					Spec: v1.ClickHouseClusterSpec{
+						Settings: v1.ClickHouseSettings{
+							Protocols: v1.ClickHouseProtocolsSpec{
+								HTTP:     v1.ProtocolSpec{Enabled: ptr.To(false)},
+								TCP:      v1.ProtocolSpec{Port: ptr.To[int32](19000)},
+								TCPProxy: v1.ProtocolSpec{Enabled: ptr.To(true)},
+								MySQL:    v1.ProtocolSpec{Enabled: ptr.To(true)},
+								GRPC:     v1.ProtocolSpec{Enabled: ptr.To(true)},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		tmpl, err := template.New("").Parse(networkConfigTemplateStr)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := networkConfigGenerator(tmpl, &ctx, v1.ClickHouseReplicaID{})
+		Expect(err).ToNot(HaveOccurred())
+
+		var config struct {
+			GRPCPort  uint16              `yaml:"grpc_port"`
+			Protocols map[string]protocol `yaml:"protocols"`
+		}
+		Expect(yaml.Unmarshal([]byte(data), &config)).To(Succeed())
+
+		Expect(config.GRPCPort).To(BeEquivalentTo(v1.DefaultClickHouseGRPCPort))
+		Expect(config.Protocols).ToNot(HaveKey("grpc"))
+		Expect(config.Protocols).To(HaveKeyWithValue("http", protocol{Type: "http"}))
+		Expect(config.Protocols["tcp"].Port).To(BeEquivalentTo(19000))
+		Expect(config.Protocols["tcp-proxy"]).To(Equal(protocol{
+			Type:        "proxy1",
+			Impl:        "tcp",
+			Port:        v1.DefaultClickHouseNativeProxyPort,
+			Description: "native protocol with PROXYv1",
+		}))
+		Expect(config.Protocols["mysql"].Port).To(BeEquivalentTo(v1.DefaultClickHouseMySQLPort))
+		Expect(config.Protocols).ToNot(HaveKey("postgresql"))
+	})
+})
+
+var _ = Describe("BaseConfig", func() {
+	generate := func(spec v1.ClickHouseClusterSpec, annotations ...map[string]string) map[string]any {
+		ctx := clickhouseReconciler{
+			reconcilerBase: reconcilerBase{
+				Cluster: &v1.ClickHouseCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-cluster",
+						Namespace: "test-namespace",
+					},
+					Spec: spec,
+				},
+			},
+		}
+		if len(annotations) > 0 {
+			ctx.Cluster.Annotations = annotations[0]
+		}
+
+		for _, generator := range generators {
+			if generator.Filename() != ConfigFileName {
+				continue
+			}
+
+			data, err := generator.Generate(&ctx, v1.ClickHouseReplicaID{})
+			Expect(err).ToNot(HaveOccurred())
+
+			config := map[string]any{}
+			Expect(yaml.Unmarshal([]byte(data), &config)).To(Succeed())
+
+			return config
+		}
+
+		Fail("base config generator not found")
+
+		return nil
+	}
+
+	It("should chroot cluster using KeeperCluster in the same namespace", func() {
+		config := generate(v1.ClickHouseClusterSpec{
+			KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper"},
+		})
+		Expect(config["zookeeper"]).To(HaveKeyWithValue("root", "/clusters/test-namespace/test-cluster"))
+		Expect(config).To(HaveKeyWithValue("user_defined_zookeeper_path", KeeperPathUDF))
+	})
+
+	It("should not chroot existing cluster using the KeeperCluster root", func() {
+		config := generate(v1.ClickHouseClusterSpec{
+			KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper"},
+		}, map[string]string{ctrlutil.AnnotationKeeperRoot: v1.KeeperRootNone})
+		Expect(config["zookeeper"]).ToNot(HaveKey("root"))
+	})
+
+	It("should chroot cluster using KeeperCluster in another namespace", func() {
+		config := generate(v1.ClickHouseClusterSpec{
+			KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper", Namespace: "platform"},
+		})
+		Expect(config["zookeeper"]).To(HaveKeyWithValue("root", "/clusters/test-namespace/test-cluster"))
+		Expect(config["distributed_ddl"]).To(HaveKeyWithValue("path", KeeperPathDistributedDDL))
+	})
+
+	It("should render distributed DDL queue retention", func() {
+		config := generate(v1.ClickHouseClusterSpec{
+			KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper"},
+			Settings: v1.ClickHouseSettings{
+				DistributedDDL: v1.DistributedDDLSpec{TaskMaxLifetimeSeconds: 86400, MaxTasksInQueue: 500},
+			},
+		})
+		Expect(config["distributed_ddl"]).To(HaveKeyWithValue("task_max_lifetime", 86400))
+		Expect(config["distributed_ddl"]).To(HaveKeyWithValue("max_tasks_in_queue", 500))
+	})
+
+	It("should render external coordination service nodes and root", func() {
+		config := generate(v1.ClickHouseClusterSpec{
+			Coordination: v1.CoordinationSpec{
+				External: &v1.ExternalKeeperSpec{
+					Nodes:  []string{"zk-0.zk:2281", "zk-1.zk:2281"},
+					Secure: true,
+				},
+				Root: "/clickhouse/prod",
+			},
+		})
+
+		zookeeper, ok := config["zookeeper"].(map[any]any)
+		Expect(ok).To(BeTrue())
+		Expect(zookeeper).To(HaveKeyWithValue("root", "/clickhouse/prod"))
+		Expect(zookeeper["nodes"]).To(ConsistOf(
+			map[any]any{"host": "zk-0.zk", "port": 2281, "secure": 1},
+			map[any]any{"host": "zk-1.zk", "port": 2281, "secure": 1},
+		))
+		Expect(config).To(HaveKeyWithValue("user_defined_zookeeper_path", KeeperPathUDF))
+		Expect(config["openSSL"]).To(HaveKeyWithValue("client", SatisfyAll(
+			HaveKeyWithValue("loadDefaultCAFile", true),
+			HaveKeyWithValue("verificationMode", "relaxed"),
+		)))
+	})
+
+	It("should verify secure external coordination service with the custom CA bundle", func() {
+		config := generate(v1.ClickHouseClusterSpec{
+			Coordination: v1.CoordinationSpec{
+				External: &v1.ExternalKeeperSpec{Nodes: []string{"zk-0.zk:2281"}, Secure: true},
+			},
+			Settings: v1.ClickHouseSettings{
+				TLS: v1.ClusterTLSSpec{CABundle: &v1.SecretKeySelector{Name: "zk-ca", Key: "ca.crt"}},
+			},
+		})
+
+		Expect(config["openSSL"]).To(HaveKeyWithValue("client", SatisfyAll(
+			HaveKeyWithValue("caConfig", TLSConfigPath+CustomCAFilename),
+			Not(HaveKey("loadDefaultCAFile")),
+		)))
+	})
+})
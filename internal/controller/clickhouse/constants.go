@@ -1,18 +1,23 @@
 package clickhouse
 
 import (
+	"time"
+
 	"github.com/blang/semver/v4"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal"
 )
 
 const (
-	PortManagement   = 9001
-	PortNative       = 9000
-	PortNativeSecure = 9440
-	PortHTTP         = 8123
-	PortHTTPSecure   = 8443
+	PortManagement   = internal.ClickHouseManagementPort
+	PortNative       = v1.DefaultClickHouseNativePort
+	PortNativeSecure = v1.DefaultClickHouseNativeSecurePort
+	PortHTTP         = v1.DefaultClickHouseHTTPPort
+	PortHTTPSecure   = v1.DefaultClickHouseHTTPSecurePort
 
-	PortPrometheusScrape = 9363
-	PortInterserver      = 9009
+	PortPrometheusScrape = internal.ClickHousePrometheusScrapePort
+	PortInterserver      = internal.ClickHouseInterserverPort
 
 	ConfigPath               = "/etc/clickhouse-server/"
 	ConfigDPath              = "config.d"
@@ -37,9 +42,17 @@ const (
 	KeeperPathUDF            = "/clickhouse/user_defined"
 	KeeperPathDistributedDDL = "/clickhouse/task_queue/ddl"
 
+	// RenamedDefaultDatabaseName is the name the non-Replicated `default` database is renamed to by the migration.
+	RenamedDefaultDatabaseName = "default_non_replicated"
+
 	ContainerName          = "clickhouse-server"
 	DefaultRevisionHistory = 10
 
+	// ShutdownGracePeriodMargin is added to the drain timeout to leave time for Distributed flush and server shutdown.
+	ShutdownGracePeriodMargin = 30 * time.Second
+	// TableSyncTimeout bounds the wait for the new replica to fetch the replicated tables data in a single reconcile.
+	TableSyncTimeout = 30 * time.Second
+
 	InterserverUserName        = "interserver"
 	OperatorManagementUsername = "operator"
 	DefaultProfileName         = "default"
@@ -53,6 +66,14 @@ const (
 	SecretKeyManagementPassword  = "management-password"
 	SecretKeyKeeperIdentity      = "keeper-identity"
 	SecretKeyClusterSecret       = "cluster-secret"
+
+	// FinalizerKeeperRoot removes the cluster root from the KeeperCluster once the cluster is deleted.
+	FinalizerKeeperRoot = "clickhouse.com/keeper-root"
+	// KeeperRootCleanupTimeout bounds the time the deleted cluster waits for its Keeper root removal.
+	KeeperRootCleanupTimeout = 5 * time.Minute
+
+	// IngressBackendProtocolAnnotation makes ingress-nginx connect to the httpSecure port with TLS.
+	IngressBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
 )
 
 var (
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -19,6 +21,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
 	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
@@ -33,7 +37,11 @@ type ClusterController struct {
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
 	Logger   controllerutil.Logger
-	Webhook  webhookv1.ClickHouseClusterWebhook
+	// Optional APIs available in the Kubernetes cluster.
+	Capabilities chctrl.Capabilities
+	// Namespace of the operator, allowed to access cluster internal ports by generated NetworkPolicies.
+	OperatorNamespace string
+	Webhook           webhookv1.ClickHouseClusterWebhook
 }
 
 // +kubebuilder:rbac:groups=clickhouse.com,resources=clickhouseclusters,verbs=get;list;watch;create;update;patch;delete
@@ -41,6 +49,8 @@ type ClusterController struct {
 // +kubebuilder:rbac:groups=clickhouse.com,resources=clickhouseclusters/finalizers,verbs=update
 
 // +kubebuilder:rbac:groups="",resources=secrets;persistentvolumeclaims,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;tlsroutes;backendtlspolicies,verbs=get;list;watch;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -51,6 +61,8 @@ func (cc *ClusterController) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if err != nil {
 		if errors.IsNotFound(err) {
 			cc.Logger.Info("clickhouse cluster not found")
+			chctrl.DeleteClusterMetrics(chctrl.MetricsControllerClickHouse, req.NamespacedName)
+
 			return ctrl.Result{}, nil
 		}
 
@@ -61,6 +73,10 @@ func (cc *ClusterController) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	logger := cc.Logger.WithContext(ctx, cluster)
 
+	if !cluster.DeletionTimestamp.IsZero() {
+		return cc.finalizeKeeperRoot(ctx, logger, cluster)
+	}
+
 	if err := cc.Webhook.Default(ctx, cluster); err != nil {
 		return ctrl.Result{}, fmt.Errorf("fill defaults before reconcile: %w", err)
 	}
@@ -95,6 +111,7 @@ func (cc *ClusterController) Reconcile(ctx context.Context, req ctrl.Request) (c
 			v1.ClickHouseReplicaID,
 			replicaState,
 		](cc, cluster),
+		operatorNamespace: cc.OperatorNamespace,
 	}
 
 	return reconciler.sync(ctx, logger)
@@ -115,19 +132,31 @@ func (cc *ClusterController) GetRecorder() events.EventRecorder {
 	return cc.Recorder
 }
 
+// GetCapabilities returns optional APIs available in the Kubernetes cluster.
+func (cc *ClusterController) GetCapabilities() chctrl.Capabilities {
+	return cc.Capabilities
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func SetupWithManager(mgr ctrl.Manager, log controllerutil.Logger) error {
+func SetupWithManager(mgr ctrl.Manager, log controllerutil.Logger, operatorNamespace string) error {
 	namedLogger := log.Named("clickhouse")
 
+	capabilities, err := chctrl.DetectCapabilities(mgr.GetRESTMapper())
+	if err != nil {
+		return fmt.Errorf("detect cluster capabilities: %w", err)
+	}
+
 	clickhouseController := &ClusterController{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorder("clickhouse-controller"),
-		Logger:   namedLogger,
-		Webhook:  webhookv1.ClickHouseClusterWebhook{Log: namedLogger},
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          mgr.GetEventRecorder("clickhouse-controller"),
+		Logger:            namedLogger,
+		Capabilities:      capabilities,
+		OperatorNamespace: operatorNamespace,
+		Webhook:           webhookv1.ClickHouseClusterWebhook{Log: namedLogger},
 	}
 
-	err := ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1.ClickHouseCluster{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
 		Watches(
 			&v1.KeeperCluster{},
@@ -138,10 +167,32 @@ func SetupWithManager(mgr ctrl.Manager, log controllerutil.Logger) error {
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.Service{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&networkingv1.Ingress{}).
 		Owns(&corev1.Pod{}).
-		WithEventFilter(predicate.ResourceVersionChangedPredicate{}).
-		Complete(clickhouseController)
-	if err != nil {
+		WithEventFilter(predicate.ResourceVersionChangedPredicate{})
+
+	if capabilities.PodMonitor {
+		controllerBuilder = controllerBuilder.Owns(&monitoringv1.PodMonitor{})
+	}
+
+	if capabilities.PrometheusRule {
+		controllerBuilder = controllerBuilder.Owns(&monitoringv1.PrometheusRule{})
+	}
+
+	if capabilities.HTTPRoute {
+		controllerBuilder = controllerBuilder.Owns(&gatewayv1.HTTPRoute{})
+	}
+
+	if capabilities.TLSRoute {
+		controllerBuilder = controllerBuilder.Owns(&gatewayv1alpha2.TLSRoute{})
+	}
+
+	if capabilities.BackendTLSPolicy {
+		controllerBuilder = controllerBuilder.Owns(&gatewayv1.BackendTLSPolicy{})
+	}
+
+	if err := controllerBuilder.Complete(clickhouseController); err != nil {
 		return fmt.Errorf("setup ClickHouse controller: %w", err)
 	}
 
@@ -154,15 +205,15 @@ func (cc *ClusterController) clickHouseClustersForKeeper(ctx context.Context, ob
 		panic(fmt.Errorf("expected v1.KeeperCluster but got a %T", obj))
 	}
 
-	// List all ClickHouseClusters that reference this KeeperCluster
+	// List all ClickHouseClusters that reference this KeeperCluster, they may be in other namespaces.
 	var chList v1.ClickHouseClusterList
-	if err := cc.List(ctx, &chList, client.InNamespace(zk.Namespace)); err != nil {
+	if err := cc.List(ctx, &chList); err != nil {
 		return nil
 	}
 
 	var requests []reconcile.Request
 	for _, ch := range chList.Items {
-		if ch.Spec.KeeperClusterRef.Name == zk.Name {
+		if ch.KeeperClusterNamespacedName() == zk.NamespacedName() {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      ch.Name,
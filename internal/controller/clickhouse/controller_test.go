@@ -49,7 +49,7 @@ var _ = When("reconciling ClickHouseCluster", Ordered, func() {
 			Spec: v1.ClickHouseClusterSpec{
 				Replicas:         ptr.To[int32](2),
 				Shards:           ptr.To[int32](2),
-				KeeperClusterRef: &corev1.LocalObjectReference{Name: keeperName},
+				KeeperClusterRef: &v1.KeeperClusterReference{Name: keeperName},
 				Labels: map[string]string{
 					"test-label": "test-val",
 				},
@@ -116,7 +116,7 @@ var _ = When("reconciling ClickHouseCluster", Ordered, func() {
 		listOpts := controllerutil.AppRequirements(cr.Namespace, cr.SpecificName())
 
 		Expect(suite.Client.List(ctx, &services, listOpts)).To(Succeed())
-		Expect(services.Items).To(HaveLen(1))
+		Expect(services.Items).To(HaveLen(2))
 
 		Expect(suite.Client.List(ctx, &pdbs, listOpts)).To(Succeed())
 		Expect(pdbs.Items).To(HaveLen(2))
@@ -0,0 +1,269 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controller/keeper"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// keeperRootAncestors returns parent paths of the root starting from the top level one.
+func keeperRootAncestors(root string) []string {
+	var ancestors []string
+	for i := 1; i < len(root); i++ {
+		if root[i] == '/' {
+			ancestors = append(ancestors, root[:i])
+		}
+	}
+
+	return ancestors
+}
+
+// keeperRootACL returns the ACL granting access to the root only to the ClickHouse cluster Keeper identity.
+func keeperRootACL(identity string) ([]zk.ACL, error) {
+	user, password, ok := strings.Cut(identity, ":")
+	if !ok || user == "" {
+		return nil, errors.New("keeper identity must be in user:password format")
+	}
+
+	return zk.DigestACL(zk.PermAll, user, password), nil
+}
+
+// findKeeperRootConflict returns the older ClickHouse cluster using the same root in the same KeeperCluster.
+// Clusters sharing the KeeperCluster root without chroot were created by the previous operator versions,
+// they are not reported.
+func findKeeperRootConflict(cluster *v1.ClickHouseCluster, clusters []v1.ClickHouseCluster) *v1.ClickHouseCluster {
+	root := cluster.KeeperRoot()
+	if cluster.Spec.KeeperClusterRef == nil || root == "" {
+		return nil
+	}
+
+	keeperName := cluster.KeeperClusterNamespacedName()
+
+	for i := range clusters {
+		other := &clusters[i]
+		if other.NamespacedName() == cluster.NamespacedName() || other.Spec.KeeperClusterRef == nil ||
+			other.KeeperClusterNamespacedName() != keeperName || other.KeeperRoot() != root {
+			continue
+		}
+
+		// The cluster created first keeps the root.
+		if other.CreationTimestamp.Before(&cluster.CreationTimestamp) ||
+			(other.CreationTimestamp.Equal(&cluster.CreationTimestamp) &&
+				other.NamespacedName().String() < cluster.NamespacedName().String()) {
+			return other
+		}
+	}
+
+	return nil
+}
+
+// ensureKeeperRoot creates the cluster root in the KeeperCluster. Parent paths are created readable by everyone,
+// the root itself is accessible only with the cluster Keeper identity. ClickHouse applies the same restriction
+// to all nodes it creates under the root.
+func ensureKeeperRoot(
+	ctx context.Context,
+	log controllerutil.Logger,
+	keeperCluster *v1.KeeperCluster,
+	root string,
+	identity string,
+) error {
+	rootACL, err := keeperRootACL(identity)
+	if err != nil {
+		return err
+	}
+
+	conn, err := keeper.Connect(ctx, log, keeperCluster)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err = conn.AddAuth("digest", []byte(identity)); err != nil {
+		return fmt.Errorf("authenticate in keeper: %w", err)
+	}
+
+	exists, _, err := conn.Exists(root)
+	if err != nil {
+		return fmt.Errorf("check keeper root %q: %w", root, err)
+	}
+
+	if exists {
+		return repairKeeperRootACL(log, conn, root, rootACL)
+	}
+
+	log.Info("creating cluster root in keeper", "root", root)
+
+	for _, path := range keeperRootAncestors(root) {
+		_, err := conn.Create(path, nil, 0, zk.WorldACL(zk.PermRead|zk.PermCreate))
+		if err != nil && !errors.Is(err, zk.ErrNodeExists) {
+			return fmt.Errorf("create keeper path %q: %w", path, err)
+		}
+	}
+
+	if _, err := conn.Create(root, nil, 0, rootACL); err != nil {
+		if errors.Is(err, zk.ErrNodeExists) {
+			return repairKeeperRootACL(log, conn, root, rootACL)
+		}
+
+		return fmt.Errorf("create keeper root %q: %w", root, err)
+	}
+
+	return nil
+}
+
+// repairKeeperRootACL restricts the existing root to the cluster identity. The root created with the identity
+// of another cluster, e.g. deleted and recreated with the same name before its root was removed, can't be repaired.
+func repairKeeperRootACL(log controllerutil.Logger, conn *zk.Conn, root string, rootACL []zk.ACL) error {
+	acl, _, err := conn.GetACL(root)
+	if err != nil {
+		return fmt.Errorf("get keeper root %q ACL: %w", root, err)
+	}
+
+	if slices.Equal(acl, rootACL) {
+		return nil
+	}
+
+	if _, err := conn.SetACL(root, rootACL, -1); err != nil {
+		if errors.Is(err, zk.ErrNoAuth) {
+			return fmt.Errorf("keeper root %q is owned by another Keeper identity, remove it from the keeper cluster "+
+				"or set coordination.root: %w", root, err)
+		}
+
+		return fmt.Errorf("set keeper root %q ACL: %w", root, err)
+	}
+
+	log.Info("restricted keeper root to the cluster identity", "root", root)
+
+	return nil
+}
+
+// removeKeeperRoot removes the cluster root with all the cluster data from the KeeperCluster.
+// The root is kept if it is not owned by the cluster identity.
+func removeKeeperRoot(
+	ctx context.Context,
+	log controllerutil.Logger,
+	keeperCluster *v1.KeeperCluster,
+	root string,
+	identity string,
+) error {
+	rootACL, err := keeperRootACL(identity)
+	if err != nil {
+		return err
+	}
+
+	conn, err := keeper.Connect(ctx, log, keeperCluster)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err = conn.AddAuth("digest", []byte(identity)); err != nil {
+		return fmt.Errorf("authenticate in keeper: %w", err)
+	}
+
+	acl, _, err := conn.GetACL(root)
+	if err != nil {
+		if errors.Is(err, zk.ErrNoNode) {
+			return nil
+		}
+
+		return fmt.Errorf("get keeper root %q ACL: %w", root, err)
+	}
+
+	if !slices.Equal(acl, rootACL) {
+		log.Info("keeper root is not owned by the cluster identity, keeping it", "root", root)
+		return nil
+	}
+
+	log.Info("removing cluster root from keeper", "root", root)
+
+	return deleteKeeperTree(conn, root)
+}
+
+// finalizeKeeperRoot removes the root of the deleted cluster from the KeeperCluster and releases the cluster.
+// The root is kept if the removal keeps failing for KeeperRootCleanupTimeout.
+func (cc *ClusterController) finalizeKeeperRoot(
+	ctx context.Context,
+	log controllerutil.Logger,
+	cluster *v1.ClickHouseCluster,
+) (ctrl.Result, error) {
+	if !slices.Contains(cluster.Finalizers, FinalizerKeeperRoot) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := cc.removeClusterKeeperRoot(ctx, log, cluster); err != nil {
+		if time.Since(cluster.DeletionTimestamp.Time) < KeeperRootCleanupTimeout {
+			return ctrl.Result{}, fmt.Errorf("remove keeper root %q: %w", cluster.KeeperRoot(), err)
+		}
+
+		log.Warn("failed to remove keeper root, keeping it", "root", cluster.KeeperRoot(), "error", err)
+		cc.Recorder.Eventf(cluster, nil, corev1.EventTypeWarning, v1.EventReasonKeeperRootCleanupFailed, v1.EventActionReconciling,
+			"Keeper root %q was not removed from KeeperCluster %s: %s",
+			cluster.KeeperRoot(), cluster.KeeperClusterNamespacedName(), err)
+	}
+
+	released := cluster.DeepCopy()
+	released.Finalizers = slices.DeleteFunc(released.Finalizers, func(finalizer string) bool {
+		return finalizer == FinalizerKeeperRoot
+	})
+
+	if err := cc.Patch(ctx, released, client.MergeFromWithOptions(cluster, client.MergeFromWithOptimisticLock{})); err != nil {
+		return ctrl.Result{}, fmt.Errorf("remove keeper root finalizer: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// removeClusterKeeperRoot removes the root of the deleted cluster. Nothing is removed if the KeeperCluster or
+// the cluster Keeper identity is already deleted.
+func (cc *ClusterController) removeClusterKeeperRoot(
+	ctx context.Context,
+	log controllerutil.Logger,
+	cluster *v1.ClickHouseCluster,
+) error {
+	root := cluster.KeeperRoot()
+	if cluster.Spec.KeeperClusterRef == nil || cluster.Spec.Coordination.External != nil || root == "" {
+		return nil
+	}
+
+	var keeperCluster v1.KeeperCluster
+	if err := cc.Get(ctx, cluster.KeeperClusterNamespacedName(), &keeperCluster); err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Info("keeper cluster is deleted, skipping keeper root removal", "root", root)
+			return nil
+		}
+
+		return fmt.Errorf("get keeper cluster: %w", err)
+	}
+
+	if !keeperCluster.DeletionTimestamp.IsZero() {
+		log.Info("keeper cluster is being deleted, skipping keeper root removal", "root", root)
+		return nil
+	}
+
+	var secret corev1.Secret
+	if err := cc.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.SecretName()}, &secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Info("cluster secret is deleted, skipping keeper root removal", "root", root)
+			return nil
+		}
+
+		return fmt.Errorf("get ClickHouse cluster secret %q: %w", cluster.SecretName(), err)
+	}
+
+	return removeKeeperRoot(ctx, log, &keeperCluster, root, string(secret.Data[SecretKeyKeeperIdentity]))
+}
@@ -0,0 +1,158 @@
+package clickhouse
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("KeeperRoot", func() {
+	It("should list root ancestors from the top level", func() {
+		Expect(keeperRootAncestors("/clusters/tenant/test")).To(HaveExactElements("/clusters", "/clusters/tenant"))
+		Expect(keeperRootAncestors("/test")).To(BeEmpty())
+	})
+
+	It("should restrict root to the cluster identity", func() {
+		acl, err := keeperRootACL("clickhouse:secret")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(acl).To(Equal(zk.DigestACL(zk.PermAll, "clickhouse", "secret")))
+
+		_, err = keeperRootACL("secret")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("pinKeeperRoot", func() {
+	newReconciler := func(status v1.ClickHouseClusterStatus) (ctrlutil.Logger, *clickhouseReconciler) {
+		cluster := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test"},
+			Spec: v1.ClickHouseClusterSpec{
+				Replicas:         ptr.To[int32](1),
+				KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper"},
+			},
+		}
+		log, rec := setupReconciler(cluster, nil)
+		Expect(rec.GetClient().Create(context.Background(), cluster)).To(Succeed())
+		cluster.Status = status
+
+		return log, rec
+	}
+
+	pinnedRoot := func(ctx context.Context, rec *clickhouseReconciler) string {
+		var stored v1.ClickHouseCluster
+		Expect(rec.GetClient().Get(ctx, rec.Cluster.NamespacedName(), &stored)).To(Succeed())
+
+		return stored.Annotations[ctrlutil.AnnotationKeeperRoot]
+	}
+
+	It("should pin the default root of the new cluster", func(ctx context.Context) {
+		log, rec := newReconciler(v1.ClickHouseClusterStatus{})
+		Expect(rec.pinKeeperRoot(ctx, log)).To(Succeed())
+		Expect(pinnedRoot(ctx, rec)).To(Equal("/clusters/test-ns/test"))
+		Expect(rec.Cluster.KeeperRoot()).To(Equal("/clusters/test-ns/test"))
+	})
+
+	It("should keep the KeeperCluster root of the existing cluster", func(ctx context.Context) {
+		log, rec := newReconciler(v1.ClickHouseClusterStatus{UpdateRevision: "spec-v1"})
+		Expect(rec.pinKeeperRoot(ctx, log)).To(Succeed())
+		Expect(pinnedRoot(ctx, rec)).To(Equal(v1.KeeperRootNone))
+		Expect(rec.Cluster.KeeperRoot()).To(BeEmpty())
+		Expect(rec.Cluster.Status.UpdateRevision).To(Equal("spec-v1"))
+	})
+
+	It("should keep the pinned root if the status is lost", func(ctx context.Context) {
+		log, rec := newReconciler(v1.ClickHouseClusterStatus{UpdateRevision: "spec-v1"})
+		Expect(rec.pinKeeperRoot(ctx, log)).To(Succeed())
+
+		rec.Cluster.Status = v1.ClickHouseClusterStatus{}
+		Expect(rec.pinKeeperRoot(ctx, log)).To(Succeed())
+		Expect(pinnedRoot(ctx, rec)).To(Equal(v1.KeeperRootNone))
+		Expect(rec.Cluster.KeeperRoot()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("findKeeperRootConflict", func() {
+	newCluster := func(name string, created time.Time, root string) v1.ClickHouseCluster {
+		return v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec: v1.ClickHouseClusterSpec{
+				KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper"},
+				Coordination:     v1.CoordinationSpec{Root: root},
+			},
+		}
+	}
+
+	now := time.Now()
+
+	It("should keep the root for the cluster created first", func() {
+		older := newCluster("older", now.Add(-time.Hour), "/shared")
+		newer := newCluster("newer", now, "/shared")
+		clusters := []v1.ClickHouseCluster{older, newer}
+
+		Expect(findKeeperRootConflict(&older, clusters)).To(BeNil())
+		Expect(findKeeperRootConflict(&newer, clusters)).To(HaveField("Name", "older"))
+	})
+
+	It("should not report clusters using distinct roots or the KeeperCluster root", func() {
+		first := newCluster("first", now.Add(-time.Hour), "")
+		second := newCluster("second", now, "")
+		Expect(findKeeperRootConflict(&second, []v1.ClickHouseCluster{first, second})).To(BeNil())
+
+		for _, cluster := range []*v1.ClickHouseCluster{&first, &second} {
+			cluster.Annotations = map[string]string{ctrlutil.AnnotationKeeperRoot: v1.KeeperRootNone}
+		}
+		Expect(findKeeperRootConflict(&second, []v1.ClickHouseCluster{first, second})).To(BeNil())
+	})
+})
+
+var _ = Describe("KeeperRootFinalizer", func() {
+	var cluster *v1.ClickHouseCluster
+
+	BeforeEach(func() {
+		cluster = &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test"},
+			Spec: v1.ClickHouseClusterSpec{
+				Replicas:         ptr.To[int32](1),
+				KeeperClusterRef: &v1.KeeperClusterReference{Name: "keeper"},
+			},
+		}
+	})
+
+	It("should protect the cluster with its own Keeper root", func(ctx context.Context) {
+		log, rec := setupReconciler(cluster, nil)
+		Expect(rec.GetClient().Create(ctx, cluster)).To(Succeed())
+		Expect(rec.GetClient().Create(ctx, &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "keeper"},
+		})).To(Succeed())
+
+		Expect(rec.reconcileKeeperCluster(ctx, log)).To(Succeed())
+		Expect(rec.Cluster.Finalizers).To(ConsistOf(FinalizerKeeperRoot))
+
+		var stored v1.ClickHouseCluster
+		Expect(rec.GetClient().Get(ctx, cluster.NamespacedName(), &stored)).To(Succeed())
+		Expect(stored.Finalizers).To(ConsistOf(FinalizerKeeperRoot))
+	})
+
+	It("should release the deleted cluster if its KeeperCluster is gone", func(ctx context.Context) {
+		log, rec := setupReconciler(cluster, nil)
+		cc := &ClusterController{Client: rec.GetClient(), Recorder: rec.GetRecorder(), Logger: log}
+
+		cluster.Finalizers = []string{FinalizerKeeperRoot}
+		Expect(cc.Create(ctx, cluster)).To(Succeed())
+		Expect(cc.Delete(ctx, cluster)).To(Succeed())
+		Expect(cc.Get(ctx, cluster.NamespacedName(), cluster)).To(Succeed())
+
+		_, err := cc.finalizeKeeperRoot(ctx, log, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8serrors.IsNotFound(cc.Get(ctx, cluster.NamespacedName(), cluster))).To(BeTrue())
+	})
+})
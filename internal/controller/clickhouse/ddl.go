@@ -0,0 +1,242 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controller/keeper"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// Interval between distributed DDL queue checks.
+const ddlQueueCheckInterval = 5 * time.Minute
+
+// ddlQueueSummary describes the distributed DDL queue backlog of the cluster.
+type ddlQueueSummary struct {
+	Status v1.DistributedDDLQueueStatus
+	// Entries younger than the stale threshold failed on some host.
+	RecentFailures int64
+	// Stale entries pending only on the removed replicas, they will never be finished.
+	Orphaned []string
+}
+
+// isRemovedReplicaHost reports whether the queue host is a replica of the cluster removed by the scale down.
+func isRemovedReplicaHost(cluster *v1.ClickHouseCluster, host string) bool {
+	podHostname, _, _ := strings.Cut(host, ".")
+
+	id, err := v1.IDFromHostname(cluster, podHostname)
+	if err != nil || cluster.HostnameByID(id) != host {
+		return false
+	}
+
+	return id.ShardID >= cluster.Shards() || id.Index >= cluster.Replicas()
+}
+
+// summarizeDDLQueue computes the queue backlog. Entries pending only on the removed replicas are not counted.
+func summarizeDDLQueue(
+	entries []ddlQueueEntry,
+	cluster *v1.ClickHouseCluster,
+	now time.Time,
+	staleThreshold time.Duration,
+) ddlQueueSummary {
+	summary := ddlQueueSummary{Status: v1.DistributedDDLQueueStatus{LastCheckTime: metav1.NewTime(now)}}
+
+	for _, entry := range entries {
+		stale := now.Sub(entry.CreateTime) > staleThreshold
+
+		if entry.FailedHosts > 0 {
+			summary.Status.FailedTasks++
+
+			if !stale {
+				summary.RecentFailures++
+			}
+		}
+
+		if len(entry.PendingHosts) == 0 {
+			continue
+		}
+
+		pendingOnRemovedOnly := !slices.ContainsFunc(entry.PendingHosts, func(host string) bool {
+			return !isRemovedReplicaHost(cluster, host)
+		})
+
+		if pendingOnRemovedOnly {
+			if entry.ActiveHosts == 0 && stale {
+				summary.Orphaned = append(summary.Orphaned, entry.Entry)
+			}
+
+			continue
+		}
+
+		summary.Status.PendingTasks++
+		if stale {
+			summary.Status.StaleTasks++
+		}
+
+		if oldest := summary.Status.OldestPendingTaskTime; oldest == nil || entry.CreateTime.Before(oldest.Time) {
+			summary.Status.OldestPendingTaskTime = new(metav1.NewTime(entry.CreateTime))
+		}
+	}
+
+	slices.Sort(summary.Orphaned)
+
+	return summary
+}
+
+// ddlQueueCheckDelay returns the time left until the next queue check. The queue is stored in Keeper,
+// so it is scanned once per ddlQueueCheckInterval, not on every reconcile.
+func ddlQueueCheckDelay(status *v1.DistributedDDLQueueStatus, now time.Time) time.Duration {
+	if status == nil {
+		return 0
+	}
+
+	return max(status.LastCheckTime.Add(ddlQueueCheckInterval).Sub(now), 0)
+}
+
+// ddlQueueKeeperAccess returns the Keeper path of the cluster distributed DDL queue and the digest identity
+// ClickHouse creates the queue entries with. The entries are accessible only with this identity.
+func ddlQueueKeeperAccess(cluster *v1.ClickHouseCluster, secret *corev1.Secret) (string, []byte) {
+	return path.Join("/", cluster.KeeperRoot(), KeeperPathDistributedDDL), secret.Data[SecretKeyKeeperIdentity]
+}
+
+// reconcileDistributedDDLQueue reports the distributed DDL queue backlog and removes entries of the removed replicas.
+func (r *clickhouseReconciler) reconcileDistributedDDLQueue(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	if delay := ddlQueueCheckDelay(r.Cluster.Status.DistributedDDLQueue, time.Now()); delay > 0 {
+		log.Debug("distributed DDL queue was checked recently, skipping", "next_check_in", delay)
+		return &ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	var checkReplica *v1.ClickHouseReplicaID
+
+	for id := range r.Cluster.ReplicaIDs() {
+		if r.Replica(id).Ready() {
+			checkReplica = &id
+			break
+		}
+	}
+
+	if checkReplica == nil {
+		r.SetCondition(log, r.NewCondition(v1.ClickHouseConditionTypeDistributedDDLQueueHealthy, metav1.ConditionUnknown,
+			v1.ClickHouseConditionDDLQueueUnknown, "No ready replica to check the distributed DDL queue"))
+
+		return nil, nil
+	}
+
+	entries, err := r.commander.DistributedDDLQueue(ctx, *checkReplica)
+	if err != nil {
+		log.Info("failed to check distributed DDL queue", "replica_id", *checkReplica, "error", err)
+		r.SetCondition(log, r.NewCondition(v1.ClickHouseConditionTypeDistributedDDLQueueHealthy, metav1.ConditionUnknown,
+			v1.ClickHouseConditionDDLQueueUnknown, "Failed to check the distributed DDL queue"))
+
+		return &ctrl.Result{RequeueAfter: ddlQueueCheckInterval}, nil
+	}
+
+	staleThreshold := time.Duration(r.Cluster.Spec.Settings.DistributedDDL.StaleTaskThresholdSeconds) * time.Second
+	if staleThreshold <= 0 {
+		staleThreshold = v1.DefaultDistributedDDLStaleTaskThresholdSeconds * time.Second
+	}
+
+	summary := summarizeDDLQueue(entries, r.Cluster, time.Now(), staleThreshold)
+	r.Cluster.Status.DistributedDDLQueue = &summary.Status
+
+	if len(summary.Orphaned) > 0 {
+		r.cleanupDDLQueue(ctx, log, summary.Orphaned)
+	}
+
+	status := summary.Status
+	switch {
+	case status.StaleTasks > 0:
+		message := fmt.Sprintf("%d ON CLUSTER queries are not finished for more than %s, oldest created at %s",
+			status.StaleTasks, staleThreshold, status.OldestPendingTaskTime.UTC().Format(time.RFC3339))
+		if _, err := r.UpsertConditionAndSendEvent(ctx, log,
+			r.NewCondition(v1.ClickHouseConditionTypeDistributedDDLQueueHealthy, metav1.ConditionFalse,
+				v1.ClickHouseConditionDDLTasksStale, message),
+			corev1.EventTypeWarning, v1.EventReasonDistributedDDLTasksStale, v1.EventActionReconciling, "%s", message,
+		); err != nil {
+			return nil, fmt.Errorf("update distributed DDL queue healthy condition: %w", err)
+		}
+	case summary.RecentFailures > 0:
+		r.SetCondition(log, r.NewCondition(v1.ClickHouseConditionTypeDistributedDDLQueueHealthy, metav1.ConditionFalse,
+			v1.ClickHouseConditionDDLTasksFailed,
+			fmt.Sprintf("%d ON CLUSTER queries failed on some replicas in the last %s", summary.RecentFailures, staleThreshold)))
+	default:
+		r.SetCondition(log, r.NewCondition(v1.ClickHouseConditionTypeDistributedDDLQueueHealthy, metav1.ConditionTrue,
+			v1.ClickHouseConditionDDLQueueHealthy, fmt.Sprintf("%d ON CLUSTER queries are pending", status.PendingTasks)))
+	}
+
+	return &ctrl.Result{RequeueAfter: ddlQueueCheckInterval}, nil
+}
+
+// cleanupDDLQueue removes the queue entries pending only on the removed replicas. Only the queue in the operator
+// managed KeeperCluster is cleaned up, other entries are removed by ClickHouse once they exceed the retention.
+func (r *clickhouseReconciler) cleanupDDLQueue(ctx context.Context, log ctrlutil.Logger, entries []string) {
+	if r.Cluster.Spec.KeeperClusterRef == nil || r.Cluster.Spec.Coordination.External != nil {
+		log.Debug("distributed DDL queue is not in the managed KeeperCluster, skipping cleanup", "entries", entries)
+		return
+	}
+
+	conn, err := keeper.Connect(ctx, log, &r.keeper)
+	if err != nil {
+		log.Info("failed to connect to keeper for distributed DDL queue cleanup", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	// ClickHouse creates the queue entries with the creator-only ACL of its identity.
+	queuePath, identity := ddlQueueKeeperAccess(r.Cluster, &r.secret)
+	if err = conn.AddAuth("digest", identity); err != nil {
+		log.Info("failed to authenticate in keeper for distributed DDL queue cleanup", "error", err)
+		return
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		if err := deleteKeeperTree(conn, path.Join(queuePath, entry)); err != nil {
+			log.Info("failed to remove distributed DDL queue entry", "entry", entry, "error", err)
+			continue
+		}
+
+		removed = append(removed, entry)
+	}
+
+	if len(removed) > 0 {
+		log.Info("removed distributed DDL queue entries of removed replicas", "entries", removed)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonDistributedDDLTasksCleanedUp,
+			v1.EventActionReconciling, "Removed %d ON CLUSTER queries pending only on removed replicas", len(removed))
+	}
+}
+
+// deleteKeeperTree removes the node with all its descendants.
+func deleteKeeperTree(conn *zk.Conn, nodePath string) error {
+	children, _, err := conn.Children(nodePath)
+	if err != nil {
+		if errors.Is(err, zk.ErrNoNode) {
+			return nil
+		}
+
+		return fmt.Errorf("list children of %q: %w", nodePath, err)
+	}
+
+	for _, child := range children {
+		if err := deleteKeeperTree(conn, path.Join(nodePath, child)); err != nil {
+			return err
+		}
+	}
+
+	if err := conn.Delete(nodePath, -1); err != nil && !errors.Is(err, zk.ErrNoNode) {
+		return fmt.Errorf("delete %q: %w", nodePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package clickhouse
+
+import (
+	"path"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+)
+
+var _ = Describe("DistributedDDLQueue", func() {
+	now := time.Unix(100000, 0)
+	cluster := &v1.ClickHouseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1.ClickHouseClusterSpec{Shards: ptr.To[int32](1), Replicas: ptr.To[int32](2)},
+	}
+	current := cluster.HostnameByID(v1.ClickHouseReplicaID{ShardID: 0, Index: 1})
+	removed := cluster.HostnameByID(v1.ClickHouseReplicaID{ShardID: 0, Index: 2})
+
+	It("should detect hosts of removed replicas", func() {
+		Expect(isRemovedReplicaHost(cluster, removed)).To(BeTrue())
+		Expect(isRemovedReplicaHost(cluster, current)).To(BeFalse())
+		Expect(isRemovedReplicaHost(cluster, "other-0-2-0.example.com")).To(BeFalse())
+	})
+
+	It("should summarize pending, stale and failed entries", func() {
+		summary := summarizeDDLQueue([]ddlQueueEntry{
+			{Entry: "query-0000000001", CreateTime: now.Add(-2 * time.Hour), PendingHosts: []string{current, removed}},
+			{Entry: "query-0000000002", CreateTime: now.Add(-time.Minute), PendingHosts: []string{current}},
+			{Entry: "query-0000000003", CreateTime: now.Add(-time.Minute), FailedHosts: 1},
+			{Entry: "query-0000000004", CreateTime: now.Add(-2 * time.Hour), FailedHosts: 1},
+		}, cluster, now, time.Hour)
+
+		Expect(summary.Status.PendingTasks).To(BeEquivalentTo(2))
+		Expect(summary.Status.StaleTasks).To(BeEquivalentTo(1))
+		Expect(summary.Status.FailedTasks).To(BeEquivalentTo(2))
+		Expect(summary.RecentFailures).To(BeEquivalentTo(1))
+		Expect(summary.Status.OldestPendingTaskTime.Time).To(Equal(now.Add(-2 * time.Hour)))
+		Expect(summary.Orphaned).To(BeEmpty())
+	})
+
+	It("should clean up only stale inactive entries of removed replicas", func() {
+		summary := summarizeDDLQueue([]ddlQueueEntry{
+			{Entry: "query-0000000001", CreateTime: now.Add(-2 * time.Hour), PendingHosts: []string{removed}},
+			{Entry: "query-0000000002", CreateTime: now.Add(-time.Minute), PendingHosts: []string{removed}},
+			{Entry: "query-0000000003", CreateTime: now.Add(-2 * time.Hour), PendingHosts: []string{removed}, ActiveHosts: 1},
+		}, cluster, now, time.Hour)
+
+		Expect(summary.Status.PendingTasks).To(BeZero())
+		Expect(summary.Orphaned).To(HaveExactElements("query-0000000001"))
+	})
+
+	It("should check the queue once per interval", func() {
+		Expect(ddlQueueCheckDelay(nil, now)).To(BeZero())
+
+		status := &v1.DistributedDDLQueueStatus{LastCheckTime: metav1.NewTime(now.Add(-time.Minute))}
+		Expect(ddlQueueCheckDelay(status, now)).To(Equal(ddlQueueCheckInterval - time.Minute))
+
+		status.LastCheckTime = metav1.NewTime(now.Add(-ddlQueueCheckInterval - time.Minute))
+		Expect(ddlQueueCheckDelay(status, now)).To(BeZero())
+	})
+
+	It("should always access the queue with the cluster identity", func() {
+		secret := &corev1.Secret{Data: map[string][]byte{SecretKeyKeeperIdentity: []byte("clickhouse:secret")}}
+
+		queuePath, identity := ddlQueueKeeperAccess(cluster, secret)
+		Expect(queuePath).To(Equal(path.Join("/", cluster.KeeperRoot(), KeeperPathDistributedDDL)))
+		Expect(identity).To(BeEquivalentTo("clickhouse:secret"))
+
+		rooted := cluster.DeepCopy()
+		rooted.Spec.Coordination.Root = "/tenants/test"
+		queuePath, identity = ddlQueueKeeperAccess(rooted, secret)
+		Expect(queuePath).To(Equal("/tenants/test/clickhouse/task_queue/ddl"))
+		Expect(identity).To(BeEquivalentTo("clickhouse:secret"))
+	})
+})
@@ -0,0 +1,526 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// Interval between checks of the declared schema drift.
+const schemaCheckInterval = 5 * time.Minute
+
+// SchemaController reconciles a ClickHouseSchema object.
+type SchemaController struct {
+	client.Client
+
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+	Logger   controllerutil.Logger
+}
+
+// +kubebuilder:rbac:groups=clickhouse.com,resources=clickhouseschemas,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=clickhouse.com,resources=clickhouseschemas/status,verbs=get;update;patch
+
+// Reconcile checks the declared schema objects on the referenced ClickHouseCluster and creates the missing ones.
+func (sc *SchemaController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	schema := &v1.ClickHouseSchema{}
+	if err := sc.Get(ctx, req.NamespacedName, schema); err != nil {
+		if errors.IsNotFound(err) {
+			sc.Logger.Info("clickhouse schema not found")
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("get ClickHouseSchema %s: %w", req.String(), err)
+	}
+
+	log := sc.Logger.WithContext(ctx, schema)
+	schema.Status.ObservedGeneration = schema.Generation
+
+	if err := schema.Spec.Validate(); err != nil {
+		sc.setCondition(schema, v1.ConditionTypeSpecValid, metav1.ConditionFalse, v1.ConditionReasonSpecInvalid, err.Error())
+		return ctrl.Result{}, sc.updateStatus(ctx, schema)
+	}
+
+	sc.setCondition(schema, v1.ConditionTypeSpecValid, metav1.ConditionTrue, v1.ConditionReasonSpecValid, "")
+
+	var cluster v1.ClickHouseCluster
+	if err := sc.Get(ctx, schema.ClusterNamespacedName(), &cluster); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("get ClickHouseCluster %s: %w", schema.ClusterNamespacedName(), err)
+		}
+
+		sc.setCondition(schema, v1.SchemaConditionTypeInSync, metav1.ConditionUnknown, v1.SchemaConditionReasonClusterNotFound,
+			fmt.Sprintf("ClickHouseCluster %q is not found", schema.Spec.ClusterRef.Name))
+
+		return ctrl.Result{RequeueAfter: schemaCheckInterval}, sc.updateStatus(ctx, schema)
+	}
+
+	// Schema queries run with the operator management user, the cluster owner must opt in explicitly.
+	if !cluster.Spec.Settings.EnableSchemaResources {
+		sc.setCondition(schema, v1.SchemaConditionTypeInSync, metav1.ConditionFalse, v1.SchemaConditionReasonSchemaResourcesDisabled,
+			fmt.Sprintf("ClickHouseCluster %q does not set settings.enableSchemaResources", cluster.Name))
+
+		return ctrl.Result{}, sc.updateStatus(ctx, schema)
+	}
+
+	if !meta.IsStatusConditionTrue(cluster.Status.Conditions, string(v1.ConditionTypeReady)) {
+		log.Info("waiting for the ClickHouse cluster to become ready")
+		sc.setCondition(schema, v1.SchemaConditionTypeInSync, metav1.ConditionUnknown, v1.SchemaConditionReasonClusterNotReady,
+			fmt.Sprintf("ClickHouseCluster %q is not ready", cluster.Name))
+
+		return ctrl.Result{RequeueAfter: schemaCheckInterval}, sc.updateStatus(ctx, schema)
+	}
+
+	var secret corev1.Secret
+	if err := sc.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.SecretName()}, &secret); err != nil {
+		return ctrl.Result{}, fmt.Errorf("get ClickHouse cluster secret %q: %w", cluster.SecretName(), err)
+	}
+
+	cmd := newCommander(log, &cluster, &secret)
+	defer cmd.Close()
+
+	id, found := sc.chooseReplica(ctx, log, cmd, &cluster)
+	if !found {
+		sc.setCondition(schema, v1.SchemaConditionTypeInSync, metav1.ConditionUnknown, v1.SchemaConditionReasonClusterNotReady,
+			fmt.Sprintf("No replica of ClickHouseCluster %q is reachable", cluster.Name))
+
+		return ctrl.Result{RequeueAfter: schemaCheckInterval}, sc.updateStatus(ctx, schema)
+	}
+
+	schema.Status.Objects = make([]v1.ClickHouseSchemaObjectStatus, 0, len(schema.Spec.Objects))
+	for _, object := range schema.Spec.Objects {
+		schema.Status.Objects = append(schema.Status.Objects,
+			sc.syncSchemaObject(ctx, log.With("object", object.FullName()), cmd, id, schema, object))
+	}
+
+	status, reason, message := schemaSyncSummary(schema.Status.Objects)
+	sc.setCondition(schema, v1.SchemaConditionTypeInSync, status, reason, message)
+
+	return ctrl.Result{RequeueAfter: schemaCheckInterval}, sc.updateStatus(ctx, schema)
+}
+
+// chooseReplica returns the first reachable replica of the cluster.
+func (sc *SchemaController) chooseReplica(
+	ctx context.Context,
+	log controllerutil.Logger,
+	cmd *commander,
+	cluster *v1.ClickHouseCluster,
+) (v1.ClickHouseReplicaID, bool) {
+	for id := range cluster.ReplicaIDs() {
+		if err := cmd.Ping(ctx, id); err != nil {
+			log.Info("replica is not reachable", "replica_id", id, "error", err)
+			continue
+		}
+
+		return id, true
+	}
+
+	return v1.ClickHouseReplicaID{}, false
+}
+
+// syncSchemaObject compares the declared object with the replica schema and creates the object if it is missing.
+func (sc *SchemaController) syncSchemaObject(
+	ctx context.Context,
+	log controllerutil.Logger,
+	cmd *commander,
+	id v1.ClickHouseReplicaID,
+	schema *v1.ClickHouseSchema,
+	object v1.ClickHouseSchemaObject,
+) v1.ClickHouseSchemaObjectStatus {
+	status := checkSchemaObject(ctx, cmd, id, object)
+	if status.State != v1.SchemaObjectStateMissing || schema.Spec.DryRun {
+		return status
+	}
+
+	query := object.QueryOnCluster(DefaultClusterName)
+	if object.Kind != v1.SchemaObjectKindDatabase {
+		engine, err := cmd.DatabaseEngine(ctx, id, object.Database)
+		if err != nil {
+			return failedSchemaObject(object, err)
+		}
+
+		// Replicated databases propagate DDL on their own and reject ON CLUSTER queries.
+		if engine == "Replicated" {
+			query = object.Query
+		}
+	}
+
+	log.Info("creating schema object", "replica_id", id)
+
+	if err := cmd.ExecSchemaQuery(ctx, id, query); err != nil {
+		log.Warn("failed to create schema object", "error", err)
+		sc.Recorder.Eventf(schema, nil, corev1.EventTypeWarning, v1.EventReasonSchemaObjectCreateFailed, v1.EventActionReconciling,
+			"Failed to create %s %s: %v", object.Kind, object.FullName(), err)
+
+		return failedSchemaObject(object, err)
+	}
+
+	sc.Recorder.Eventf(schema, nil, corev1.EventTypeNormal, v1.EventReasonSchemaObjectCreated, v1.EventActionReconciling,
+		"Created %s %s on ClickHouseCluster %q", object.Kind, object.FullName(), schema.Spec.ClusterRef.Name)
+
+	return checkSchemaObject(ctx, cmd, id, object)
+}
+
+// checkSchemaObject compares the declared object query with the SHOW CREATE output of the replica.
+// Both queries are formatted by the replica to ignore formatting differences, the parts added by the server
+// to the declared query are ignored.
+func checkSchemaObject(
+	ctx context.Context,
+	cmd *commander,
+	id v1.ClickHouseReplicaID,
+	object v1.ClickHouseSchemaObject,
+) v1.ClickHouseSchemaObjectStatus {
+	actual, exists, err := cmd.ShowCreate(ctx, id, object)
+	if err != nil {
+		return failedSchemaObject(object, err)
+	}
+
+	status := v1.ClickHouseSchemaObjectStatus{
+		Kind:     object.Kind,
+		Database: object.Database,
+		Name:     object.Name,
+		State:    v1.SchemaObjectStateMissing,
+	}
+	if !exists {
+		return status
+	}
+
+	declared, err := cmd.FormatQuery(ctx, id, object.QueryWithoutIfNotExists())
+	if err != nil {
+		return failedSchemaObject(object, err)
+	}
+
+	formattedActual, err := cmd.FormatQuery(ctx, id, actual)
+	if err != nil {
+		return failedSchemaObject(object, err)
+	}
+
+	status.State = v1.SchemaObjectStateInSync
+	if declared != normalizeShowCreate(object.Kind, declared, formattedActual) {
+		status.State = v1.SchemaObjectStateDrifted
+		status.ActualQuery = actual
+	}
+
+	return status
+}
+
+// normalizeShowCreate removes from the formatted SHOW CREATE output the parts the server adds to the declared query:
+// the engine omitted in the declared query, engine arguments expanded from the defaults, the Replicated engine
+// variant of tables in Replicated databases and the table settings filled with the defaults.
+// Both queries must be formatted in a single line.
+func normalizeShowCreate(kind v1.ClickHouseSchemaObjectKind, declared, actual string) string {
+	declaredEngine, declaredArgs, declaredFound := findEngine(declared)
+	if start, nameEnd, end, found := findEngineClause(actual); found {
+		switch {
+		case !declaredFound:
+			actual = actual[:start] + actual[end:]
+		case declaredArgs == "":
+			engine := actual[start+len(engineKeyword) : nameEnd]
+			if engine == declaredEngine || engine == "Replicated"+declaredEngine {
+				actual = actual[:start] + engineKeyword + declaredEngine + actual[end:]
+			}
+		}
+	}
+
+	if kind != v1.SchemaObjectKindTable {
+		return actual
+	}
+
+	declaredSettings := map[string]struct{}{}
+	if start, end, found := findSettingsClause(declared); found {
+		for _, setting := range splitTopLevel(declared[start+len(settingsKeyword):end], ", ") {
+			name, _, _ := strings.Cut(setting, " = ")
+			declaredSettings[name] = struct{}{}
+		}
+	}
+
+	start, end, found := findSettingsClause(actual)
+	if !found {
+		return actual
+	}
+
+	var kept []string
+	for _, setting := range splitTopLevel(actual[start+len(settingsKeyword):end], ", ") {
+		name, _, _ := strings.Cut(setting, " = ")
+		if _, ok := declaredSettings[name]; ok {
+			kept = append(kept, setting)
+		}
+	}
+
+	if len(kept) == 0 {
+		return actual[:start] + actual[end:]
+	}
+
+	return actual[:start] + settingsKeyword + strings.Join(kept, ", ") + actual[end:]
+}
+
+const (
+	engineKeyword   = " ENGINE = "
+	settingsKeyword = " SETTINGS "
+	commentKeyword  = " COMMENT "
+)
+
+// findEngine returns the engine name and arguments of the formatted query.
+func findEngine(query string) (string, string, bool) {
+	start, nameEnd, end, found := findEngineClause(query)
+	if !found {
+		return "", "", false
+	}
+
+	return query[start+len(engineKeyword) : nameEnd], query[nameEnd:end], true
+}
+
+// findEngineClause returns the bounds of the ENGINE clause of the formatted query and the end of the engine name.
+func findEngineClause(query string) (int, int, int, bool) {
+	positions := findTopLevel(query, engineKeyword)
+	if len(positions) == 0 {
+		return 0, 0, 0, false
+	}
+
+	start := positions[0]
+
+	nameEnd := start + len(engineKeyword)
+	for nameEnd < len(query) && (query[nameEnd] == '_' || isAlphanumeric(query[nameEnd])) {
+		nameEnd++
+	}
+
+	end := nameEnd
+	if end < len(query) && query[end] == '(' {
+		end = matchingParen(query, end) + 1
+	}
+
+	return start, nameEnd, end, true
+}
+
+// findSettingsClause returns the bounds of the table SETTINGS clause of the formatted query.
+func findSettingsClause(query string) (int, int, bool) {
+	positions := findTopLevel(query, settingsKeyword)
+	if len(positions) == 0 {
+		return 0, 0, false
+	}
+
+	start := positions[len(positions)-1]
+
+	end := len(query)
+	for _, pos := range findTopLevel(query, commentKeyword) {
+		if pos > start {
+			end = pos
+			break
+		}
+	}
+
+	return start, end, true
+}
+
+// findTopLevel returns positions of the token outside of quotes and parentheses.
+func findTopLevel(query, token string) []int {
+	var (
+		positions []int
+		depth     int
+		quote     byte
+	)
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case depth == 0 && strings.HasPrefix(query[i:], token):
+			positions = append(positions, i)
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		}
+	}
+
+	return positions
+}
+
+// matchingParen returns the position of the parenthesis closing the one at the given position.
+func matchingParen(query string, open int) int {
+	for _, pos := range findTopLevel(query[open+1:], ")") {
+		return open + 1 + pos
+	}
+
+	return len(query) - 1
+}
+
+// splitTopLevel splits the list by the separator outside of quotes and parentheses.
+func splitTopLevel(list, sep string) []string {
+	var (
+		parts []string
+		last  int
+	)
+
+	for _, pos := range findTopLevel(list, sep) {
+		parts = append(parts, list[last:pos])
+		last = pos + len(sep)
+	}
+
+	return append(parts, list[last:])
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func failedSchemaObject(object v1.ClickHouseSchemaObject, err error) v1.ClickHouseSchemaObjectStatus {
+	return v1.ClickHouseSchemaObjectStatus{
+		Kind:     object.Kind,
+		Database: object.Database,
+		Name:     object.Name,
+		State:    v1.SchemaObjectStateFailed,
+		Message:  err.Error(),
+	}
+}
+
+// schemaSyncSummary returns the InSync condition fields for the objects status. Failures take precedence over drift.
+func schemaSyncSummary(objects []v1.ClickHouseSchemaObjectStatus) (metav1.ConditionStatus, v1.ConditionReason, string) {
+	byState := map[v1.ClickHouseSchemaObjectState][]string{}
+	for _, object := range objects {
+		name := fmt.Sprintf("`%s`.`%s`", object.Database, object.Name)
+		if object.Kind == v1.SchemaObjectKindDatabase {
+			name = fmt.Sprintf("`%s`", object.Database)
+		}
+
+		byState[object.State] = append(byState[object.State], name)
+	}
+
+	var (
+		reason v1.ConditionReason
+		parts  []string
+	)
+
+	for _, state := range []struct {
+		state  v1.ClickHouseSchemaObjectState
+		reason v1.ConditionReason
+	}{
+		{v1.SchemaObjectStateMissing, v1.SchemaConditionReasonObjectsMissing},
+		{v1.SchemaObjectStateDrifted, v1.SchemaConditionReasonObjectsDrifted},
+		{v1.SchemaObjectStateFailed, v1.SchemaConditionReasonObjectsFailed},
+	} {
+		if names := byState[state.state]; len(names) > 0 {
+			reason = state.reason
+			parts = append(parts, fmt.Sprintf("%s: %s", state.state, strings.Join(names, ", ")))
+		}
+	}
+
+	if len(parts) == 0 {
+		return metav1.ConditionTrue, v1.SchemaConditionReasonObjectsInSync, fmt.Sprintf("All %d objects are in sync", len(objects))
+	}
+
+	return metav1.ConditionFalse, reason, strings.Join(parts, "; ")
+}
+
+func (sc *SchemaController) setCondition(
+	schema *v1.ClickHouseSchema,
+	conditionType v1.ConditionType,
+	status metav1.ConditionStatus,
+	reason v1.ConditionReason,
+	message string,
+) {
+	meta.SetStatusCondition(&schema.Status.Conditions, metav1.Condition{
+		Type:               string(conditionType),
+		Status:             status,
+		Reason:             string(reason),
+		Message:            message,
+		ObservedGeneration: schema.Generation,
+	})
+}
+
+func (sc *SchemaController) updateStatus(ctx context.Context, schema *v1.ClickHouseSchema) error {
+	if err := sc.Status().Update(ctx, schema); err != nil {
+		return fmt.Errorf("update clickhouse schema status: %w", err)
+	}
+
+	return nil
+}
+
+// SetupSchemaWithManager sets up the ClickHouseSchema controller with the Manager.
+func SetupSchemaWithManager(mgr ctrl.Manager, log controllerutil.Logger) error {
+	schemaController := &SchemaController{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("clickhouse-schema-controller"),
+		Logger:   log.Named("clickhouse-schema"),
+	}
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1.ClickHouseSchema{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&v1.ClickHouseCluster{},
+			handler.EnqueueRequestsFromMapFunc(schemaController.schemasForCluster),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, clusterReadinessChangedPredicate())),
+		).
+		Complete(schemaController)
+	if err != nil {
+		return fmt.Errorf("setup ClickHouseSchema controller: %w", err)
+	}
+
+	return nil
+}
+
+// clusterReadinessChangedPredicate passes the ClickHouseCluster updates changing the Ready condition status.
+// Other status updates do not affect the schema.
+func clusterReadinessChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, oldOK := e.ObjectOld.(*v1.ClickHouseCluster)
+			newCluster, newOK := e.ObjectNew.(*v1.ClickHouseCluster)
+			if !oldOK || !newOK {
+				return false
+			}
+
+			return meta.IsStatusConditionTrue(oldCluster.Status.Conditions, string(v1.ConditionTypeReady)) !=
+				meta.IsStatusConditionTrue(newCluster.Status.Conditions, string(v1.ConditionTypeReady))
+		},
+	}
+}
+
+func (sc *SchemaController) schemasForCluster(ctx context.Context, obj client.Object) []reconcile.Request {
+	cluster, ok := obj.(*v1.ClickHouseCluster)
+	if !ok {
+		panic(fmt.Errorf("expected v1.ClickHouseCluster but got a %T", obj))
+	}
+
+	var schemaList v1.ClickHouseSchemaList
+	if err := sc.List(ctx, &schemaList, client.InNamespace(cluster.Namespace)); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, schema := range schemaList.Items {
+		if schema.ClusterNamespacedName() == cluster.NamespacedName() {
+			requests = append(requests, reconcile.Request{NamespacedName: schema.NamespacedName()})
+		}
+	}
+
+	return requests
+}
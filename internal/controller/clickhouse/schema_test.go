@@ -0,0 +1,213 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("schemaSyncSummary", func() {
+	database := v1.ClickHouseSchemaObjectStatus{Kind: v1.SchemaObjectKindDatabase, Database: "analytics", State: v1.SchemaObjectStateInSync}
+	table := v1.ClickHouseSchemaObjectStatus{Kind: v1.SchemaObjectKindTable, Database: "analytics", Name: "events", State: v1.SchemaObjectStateInSync}
+
+	It("should report all objects in sync", func() {
+		status, reason, _ := schemaSyncSummary([]v1.ClickHouseSchemaObjectStatus{database, table})
+		Expect(status).To(Equal(metav1.ConditionTrue))
+		Expect(reason).To(Equal(v1.SchemaConditionReasonObjectsInSync))
+	})
+
+	It("should prefer failures over drift", func() {
+		drifted, failed := database, table
+		drifted.State = v1.SchemaObjectStateDrifted
+		failed.State = v1.SchemaObjectStateFailed
+
+		status, reason, message := schemaSyncSummary([]v1.ClickHouseSchemaObjectStatus{drifted, failed})
+		Expect(status).To(Equal(metav1.ConditionFalse))
+		Expect(reason).To(Equal(v1.SchemaConditionReasonObjectsFailed))
+		Expect(message).To(Equal("Drifted: `analytics`; Failed: `analytics`.`events`"))
+	})
+})
+
+var _ = Describe("normalizeShowCreate", func() {
+	DescribeTable("should ignore the parts added by the server",
+		func(kind v1.ClickHouseSchemaObjectKind, declared, actual string) {
+			Expect(normalizeShowCreate(kind, declared, actual)).To(Equal(declared))
+		},
+		Entry("expanded Replicated engine arguments and default settings", v1.SchemaObjectKindTable,
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = ReplicatedMergeTree ORDER BY id",
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = ReplicatedMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}') ORDER BY id SETTINGS index_granularity = 8192"),
+		Entry("Replicated engine variant in Replicated database", v1.SchemaObjectKindTable,
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id",
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = ReplicatedMergeTree ORDER BY id SETTINGS index_granularity = 8192"),
+		Entry("default settings next to the declared ones", v1.SchemaObjectKindTable,
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id SETTINGS ttl_only_drop_parts = 1 COMMENT 'events'",
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id SETTINGS index_granularity = 8192, ttl_only_drop_parts = 1 COMMENT 'events'"),
+		Entry("omitted database engine", v1.SchemaObjectKindDatabase,
+			"CREATE DATABASE analytics",
+			"CREATE DATABASE analytics ENGINE = Replicated('/clickhouse/databases/analytics', '{shard}', '{replica}')"),
+	)
+
+	DescribeTable("should keep the differences from the declared query",
+		func(kind v1.ClickHouseSchemaObjectKind, declared, actual string) {
+			Expect(normalizeShowCreate(kind, declared, actual)).ToNot(Equal(declared))
+		},
+		Entry("different columns", v1.SchemaObjectKindTable,
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id",
+			"CREATE TABLE analytics.events (`id` UInt32) ENGINE = MergeTree ORDER BY id"),
+		Entry("different engine", v1.SchemaObjectKindTable,
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id",
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = ReplacingMergeTree ORDER BY id"),
+		Entry("different declared engine arguments", v1.SchemaObjectKindDatabase,
+			"CREATE DATABASE analytics ENGINE = Replicated('/clickhouse/databases/analytics', '{shard}', '{replica}')",
+			"CREATE DATABASE analytics ENGINE = Replicated('/clickhouse/databases/other', '{shard}', '{replica}')"),
+		Entry("different declared setting value", v1.SchemaObjectKindTable,
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id SETTINGS ttl_only_drop_parts = 1",
+			"CREATE TABLE analytics.events (`id` UInt64) ENGINE = MergeTree ORDER BY id SETTINGS index_granularity = 8192, ttl_only_drop_parts = 0"),
+	)
+})
+
+// fakeSchemaServer emulates the schema queries of a single ClickHouse replica.
+type fakeSchemaServer struct {
+	// Database engines by name.
+	databases map[string]string
+	// SHOW CREATE output by the object full name.
+	objects map[string]string
+	// Server form of the created objects by the executed query.
+	created map[string]string
+	execErr error
+}
+
+func (s *fakeSchemaServer) handle(query string, args ...any) ([][]any, error) {
+	switch {
+	case query == databaseEngineQuery:
+		engine, ok := s.databases[args[0].(string)]
+		if !ok {
+			return nil, nil
+		}
+
+		return [][]any{{engine}}, nil
+	case query == tableExistsQuery:
+		if _, ok := s.objects["`"+args[0].(string)+"`.`"+args[1].(string)+"`"]; ok {
+			return [][]any{{uint64(1)}}, nil
+		}
+
+		return [][]any{{uint64(0)}}, nil
+	case query == formatSingleLineQuery:
+		return [][]any{{strings.Join(strings.Fields(args[0].(string)), " ")}}, nil
+	case strings.HasPrefix(query, "SHOW CREATE "):
+		fields := strings.Fields(query)
+		return [][]any{{s.objects[fields[len(fields)-1]]}}, nil
+	case strings.HasPrefix(query, "CREATE "):
+		if s.execErr != nil {
+			return nil, s.execErr
+		}
+
+		s.objects["`analytics`.`events`"] = s.created[query]
+		return nil, nil
+	}
+
+	return nil, errors.New("unexpected query: " + query)
+}
+
+var _ = Describe("syncSchemaObject", func() {
+	const (
+		declared = "CREATE TABLE IF NOT EXISTS analytics.events (`id` UInt64) ENGINE = ReplicatedMergeTree ORDER BY id"
+		expanded = "CREATE TABLE analytics.events (`id` UInt64) " +
+			"ENGINE = ReplicatedMergeTree('/clickhouse/tables/{uuid}/{shard}', '{replica}') ORDER BY id SETTINGS index_granularity = 8192"
+		onCluster = "CREATE TABLE IF NOT EXISTS analytics.events ON CLUSTER `default` (`id` UInt64) ENGINE = ReplicatedMergeTree ORDER BY id"
+	)
+
+	var (
+		id     = v1.ClickHouseReplicaID{}
+		object = v1.ClickHouseSchemaObject{Kind: v1.SchemaObjectKindTable, Database: "analytics", Name: "events", Query: declared}
+		log    = ctrlutil.NewLogger(zap.NewRaw(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+		server *fakeSchemaServer
+		conn   *fakeConn
+		cmd    *commander
+		sc     *SchemaController
+		schema *v1.ClickHouseSchema
+	)
+
+	BeforeEach(func() {
+		server = &fakeSchemaServer{
+			databases: map[string]string{"analytics": "Atomic"},
+			objects:   map[string]string{},
+			created:   map[string]string{onCluster: expanded, declared: expanded},
+		}
+		conn = &fakeConn{handler: server.handle}
+		cmd = newFakeCommander(&v1.ClickHouseCluster{}, map[v1.ClickHouseReplicaID]*fakeConn{id: conn})
+		sc = &SchemaController{Recorder: events.NewFakeRecorder(16), Logger: log}
+		schema = &v1.ClickHouseSchema{Spec: v1.ClickHouseSchemaSpec{
+			ClusterRef: corev1.LocalObjectReference{Name: "test"},
+			Objects:    []v1.ClickHouseSchemaObject{object},
+		}}
+	})
+
+	It("should create missing object on cluster and report it in sync", func(ctx context.Context) {
+		status := sc.syncSchemaObject(ctx, log, cmd, id, schema, object)
+		Expect(status.State).To(Equal(v1.SchemaObjectStateInSync))
+		Expect(status.ActualQuery).To(BeEmpty())
+		Expect(conn.Executed()).To(Equal([]string{onCluster}))
+	})
+
+	It("should create object without ON CLUSTER in Replicated database", func(ctx context.Context) {
+		server.databases["analytics"] = "Replicated"
+
+		status := sc.syncSchemaObject(ctx, log, cmd, id, schema, object)
+		Expect(status.State).To(Equal(v1.SchemaObjectStateInSync))
+		Expect(conn.Executed()).To(Equal([]string{declared}))
+	})
+
+	It("should only report missing object in dry run", func(ctx context.Context) {
+		schema.Spec.DryRun = true
+
+		status := sc.syncSchemaObject(ctx, log, cmd, id, schema, object)
+		Expect(status.State).To(Equal(v1.SchemaObjectStateMissing))
+		Expect(conn.Executed()).To(BeEmpty())
+	})
+
+	It("should report drifted object without changing it", func(ctx context.Context) {
+		actual := strings.Replace(expanded, "UInt64", "UInt32", 1)
+		server.objects["`analytics`.`events`"] = actual
+
+		status := sc.syncSchemaObject(ctx, log, cmd, id, schema, object)
+		Expect(status.State).To(Equal(v1.SchemaObjectStateDrifted))
+		Expect(status.ActualQuery).To(Equal(actual))
+		Expect(conn.Executed()).To(BeEmpty())
+	})
+
+	It("should report failed creation", func(ctx context.Context) {
+		server.execErr = errors.New("not enough privileges")
+
+		status := sc.syncSchemaObject(ctx, log, cmd, id, schema, object)
+		Expect(status.State).To(Equal(v1.SchemaObjectStateFailed))
+		Expect(status.Message).To(ContainSubstring("not enough privileges"))
+	})
+})
+
+var _ = Describe("clusterReadinessChangedPredicate", func() {
+	cluster := func(ready metav1.ConditionStatus) *v1.ClickHouseCluster {
+		return &v1.ClickHouseCluster{Status: v1.ClickHouseClusterStatus{Conditions: []metav1.Condition{{
+			Type:   string(v1.ConditionTypeReady),
+			Status: ready,
+		}}}}
+	}
+
+	It("should pass only readiness changes", func() {
+		pred := clusterReadinessChangedPredicate()
+		Expect(pred.Update(event.UpdateEvent{ObjectOld: cluster(metav1.ConditionFalse), ObjectNew: cluster(metav1.ConditionTrue)})).To(BeTrue())
+		Expect(pred.Update(event.UpdateEvent{ObjectOld: cluster(metav1.ConditionTrue), ObjectNew: cluster(metav1.ConditionTrue)})).To(BeFalse())
+	})
+})
@@ -3,6 +3,7 @@ package clickhouse
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"slices"
@@ -19,7 +20,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
 	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
@@ -38,6 +41,48 @@ type replicaState struct {
 	Error       bool `json:"error"`
 	StatefulSet *appsv1.StatefulSet
 	Pinged      bool
+	// Failed replication health checks. Populated only for pinged replicas.
+	HealthProblems []string
+	// CaughtUp is true if the replica has no significant replication backlog.
+	// Rolling update does not proceed to the next replica until the restarted one has caught up.
+	CaughtUp bool
+}
+
+// CatchUpPending returns true if the replica was restarted by the rolling update and has not caught up yet.
+func (r replicaState) CatchUpPending() bool {
+	if r.StatefulSet == nil {
+		return false
+	}
+
+	_, ok := r.StatefulSet.Annotations[ctrlutil.AnnotationCatchUpPending]
+
+	return ok
+}
+
+// CatchUpTimedOut returns true if the replica restarted by the rolling update has not caught up within the timeout.
+// The replica with the malformed catch up mark is considered timed out, so it never blocks the rolling update.
+func (r replicaState) CatchUpTimedOut(timeout time.Duration, now time.Time) bool {
+	if !r.CatchUpPending() || r.CaughtUp {
+		return false
+	}
+
+	restartedAt, err := time.Parse(time.RFC3339, r.StatefulSet.Annotations[ctrlutil.AnnotationCatchUpPending])
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(restartedAt) > timeout
+}
+
+// TableSyncPending returns true if the replica is new and the replicated tables of its shard are not synced to it yet.
+func (r replicaState) TableSyncPending() bool {
+	if r.StatefulSet == nil {
+		return false
+	}
+
+	_, ok := r.StatefulSet.Annotations[ctrlutil.AnnotationTableSyncPending]
+
+	return ok
 }
 
 func (r replicaState) Updated() bool {
@@ -54,7 +99,7 @@ func (r replicaState) Ready() bool {
 		return false
 	}
 
-	return r.Pinged && r.StatefulSet.Status.ReadyReplicas == 1 // Not reliable, but allows to wait until pod is `green`
+	return r.Pinged && len(r.HealthProblems) == 0 && r.StatefulSet.Status.ReadyReplicas == 1
 }
 
 func (r replicaState) HasStatefulSetDiff(rec *clickhouseReconciler) bool {
@@ -94,6 +139,10 @@ func (r replicaState) UpdateStage(rec *clickhouseReconciler) chctrl.ReplicaUpdat
 		return chctrl.StageNotReadyUpToDate
 	}
 
+	if r.CatchUpPending() && !r.CaughtUp {
+		return chctrl.StageCatchingUp
+	}
+
 	return chctrl.StageUpToDate
 }
 
@@ -102,6 +151,8 @@ type reconcilerBase = chctrl.ResourceReconcilerBase[v1.ClickHouseClusterStatus,
 type clickhouseReconciler struct {
 	reconcilerBase
 
+	operatorNamespace string
+
 	// Should be populated after reconcileClusterRevisions.
 	keeper v1.KeeperCluster
 	// Should be populated by reconcileCommonResources.
@@ -110,6 +161,10 @@ type clickhouseReconciler struct {
 
 	databasesInSync        bool
 	staleReplicasCleanedUp bool
+	// Replicas with the non-Replicated `default` database left untouched due to the migration policy.
+	defaultDatabaseBlocked []v1.ClickHouseReplicaID
+	// Databases renamed by the `default` database migration which still contain tables, with their replicas.
+	defaultDatabaseRenamed []string
 }
 
 type reconcileFunc func(context.Context, ctrlutil.Logger) (*ctrl.Result, error)
@@ -130,6 +185,7 @@ func (r *clickhouseReconciler) sync(ctx context.Context, log ctrlutil.Logger) (c
 		r.reconcileReplicaResources,
 		r.reconcileReplicateSchema,
 		r.reconcileCleanUp,
+		r.reconcileDistributedDDLQueue,
 		r.reconcileConditions,
 	}
 
@@ -139,7 +195,10 @@ func (r *clickhouseReconciler) sync(ctx context.Context, log ctrlutil.Logger) (c
 		stepLog := log.With("reconcile_step", funcName)
 		stepLog.Debug("starting reconcile step")
 
+		stepStart := time.Now()
 		stepResult, err := fn(ctx, stepLog)
+		chctrl.ObserveReconcileStep(chctrl.MetricsControllerClickHouse, funcName, time.Since(stepStart), err)
+
 		if err != nil {
 			if k8serrors.IsConflict(err) {
 				stepLog.Error(err, "update conflict for resource, reschedule to retry")
@@ -196,6 +255,34 @@ func (r *clickhouseReconciler) reconcileCommonResources(ctx context.Context, log
 		return nil, fmt.Errorf("reconcile service resource: %w", err)
 	}
 
+	if err := r.reconcileClientServices(ctx, log); err != nil {
+		return nil, err
+	}
+
+	gateway := r.Cluster.Spec.Services.Gateway
+
+	ingress := templateIngress(r.Cluster)
+	if _, err := r.ReconcileIngress(ctx, log, ingress, r.Cluster.Spec.Services.Ingress.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile Ingress resource: %w", err)
+	}
+
+	httpRoute := templateHTTPRoute(r.Cluster)
+	if _, err := r.ReconcileHTTPRoute(ctx, log, httpRoute, gateway.Enabled && gateway.TLSMode != v1.GatewayTLSModePassthrough, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile HTTPRoute resource: %w", err)
+	}
+
+	backendTLSPolicy := templateBackendTLSPolicy(r.Cluster)
+	backendPort, _ := httpBackendPort(r.Cluster)
+	backendTLS := gateway.Enabled && gateway.TLSMode != v1.GatewayTLSModePassthrough && backendPort == "http-secure"
+	if _, err := r.ReconcileBackendTLSPolicy(ctx, log, backendTLSPolicy, backendTLS, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile BackendTLSPolicy resource: %w", err)
+	}
+
+	tlsRoute := templateTLSRoute(r.Cluster)
+	if _, err := r.ReconcileTLSRoute(ctx, log, tlsRoute, gateway.Enabled && gateway.TLSMode == v1.GatewayTLSModePassthrough, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile TLSRoute resource: %w", err)
+	}
+
 	for shard := range r.Cluster.Shards() {
 		pdb := templatePodDisruptionBudget(r.Cluster, shard)
 		if _, err := r.ReconcilePodDisruptionBudget(ctx, log, pdb, v1.EventActionReconciling); err != nil {
@@ -203,6 +290,21 @@ func (r *clickhouseReconciler) reconcileCommonResources(ctx context.Context, log
 		}
 	}
 
+	podMonitor := templatePodMonitor(r.Cluster)
+	if _, err := r.ReconcilePodMonitor(ctx, log, podMonitor, r.Cluster.Spec.Monitoring.PodMonitor.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile PodMonitor resource: %w", err)
+	}
+
+	prometheusRule := templatePrometheusRule(r.Cluster)
+	if _, err := r.ReconcilePrometheusRule(ctx, log, prometheusRule, r.Cluster.Spec.Monitoring.Alerts.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile PrometheusRule resource: %w", err)
+	}
+
+	networkPolicy := templateNetworkPolicy(r.Cluster, r.operatorNamespace)
+	if _, err := r.ReconcileNetworkPolicy(ctx, log, networkPolicy, r.Cluster.Spec.NetworkPolicy.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile NetworkPolicy resource: %w", err)
+	}
+
 	var disruptionBudgets policyv1.PodDisruptionBudgetList
 	if err := r.GetClient().List(ctx, &disruptionBudgets,
 		ctrlutil.AppRequirements(r.Cluster.Namespace, r.Cluster.SpecificName())); err != nil {
@@ -259,7 +361,66 @@ func (r *clickhouseReconciler) reconcileCommonResources(ctx context.Context, log
 	return nil, nil
 }
 
+// reconcileClientServices creates enabled client-facing Services and removes disabled ones and ones of removed shards.
+func (r *clickhouseReconciler) reconcileClientServices(ctx context.Context, log ctrlutil.Logger) error {
+	clusterEnabled := ptr.Deref(r.Cluster.Spec.Services.Cluster.Enabled, true)
+	shardEnabled := ptr.Deref(r.Cluster.Spec.Services.Shard.Enabled, false)
+
+	if clusterEnabled {
+		if _, err := r.ReconcileService(ctx, log, templateClusterService(r.Cluster), v1.EventActionReconciling); err != nil {
+			return fmt.Errorf("reconcile cluster Service: %w", err)
+		}
+	}
+
+	if shardEnabled {
+		for shard := range r.Cluster.Shards() {
+			if _, err := r.ReconcileService(ctx, log, templateShardService(r.Cluster, shard), v1.EventActionReconciling); err != nil {
+				return fmt.Errorf("reconcile Service for shard %d: %w", shard, err)
+			}
+		}
+	}
+
+	var services corev1.ServiceList
+	if err := r.GetClient().List(ctx, &services,
+		ctrlutil.AppRequirements(r.Cluster.Namespace, r.Cluster.SpecificName())); err != nil {
+		return fmt.Errorf("list Services: %w", err)
+	}
+
+	for _, service := range services.Items {
+		if !metav1.IsControlledBy(&service, r.Cluster) {
+			continue
+		}
+
+		remove := false
+		if shardLabel, ok := service.Labels[ctrlutil.LabelClickHouseShardID]; ok {
+			shardID, err := strconv.Atoi(shardLabel)
+			if err != nil {
+				log.Warn("failed to get shard ID from Service labels", "service", service.Name, "error", err)
+				continue
+			}
+
+			remove = !shardEnabled || shardID >= int(r.Cluster.Shards())
+		} else if service.Name == r.Cluster.ClusterServiceName() {
+			remove = !clusterEnabled
+		}
+
+		if remove {
+			log.Info("removing Service", "service", service.Name)
+
+			if err := r.Delete(ctx, &service, v1.EventActionReconciling); err != nil {
+				return fmt.Errorf("remove Service %s: %w", service.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (r *clickhouseReconciler) reconcileClusterRevisions(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	if err := r.pinKeeperRoot(ctx, log); err != nil {
+		return nil, err
+	}
+
 	if r.Cluster.Status.ObservedGeneration != r.Cluster.Generation {
 		r.Cluster.Status.ObservedGeneration = r.Cluster.Generation
 		log.Debug(fmt.Sprintf("observed new CR generation %d", r.Cluster.Generation))
@@ -275,19 +436,12 @@ func (r *clickhouseReconciler) reconcileClusterRevisions(ctx context.Context, lo
 		log.Debug(fmt.Sprintf("observed new CR revision %q", updateRevision))
 	}
 
-	if err := r.GetClient().Get(ctx, types.NamespacedName{
-		Namespace: r.Cluster.Namespace,
-		Name:      r.Cluster.Spec.KeeperClusterRef.Name,
-	}, &r.keeper); err != nil {
-		return nil, fmt.Errorf("get keeper cluster: %w", err)
-	}
-
-	if cond := meta.FindStatusCondition(r.keeper.Status.Conditions, string(v1.ConditionTypeReady)); cond == nil || cond.Status != metav1.ConditionTrue {
-		if cond == nil {
-			log.Warn("keeper cluster is not ready")
-		} else {
-			log.Warn("keeper cluster is not ready", "reason", cond.Reason, "message", cond.Message)
+	if r.Cluster.Spec.Coordination.External == nil {
+		if err := r.reconcileKeeperCluster(ctx, log); err != nil {
+			return nil, err
 		}
+	} else if err := r.reconcileKeeperNetworkPolicy(ctx, log); err != nil {
+		return nil, err
 	}
 
 	configRevision, err := getConfigurationRevision(r)
@@ -313,6 +467,136 @@ func (r *clickhouseReconciler) reconcileClusterRevisions(ctx context.Context, lo
 	return nil, nil
 }
 
+// pinKeeperRoot stores the Keeper root in the cluster annotation on the first reconcile, so the default root never
+// moves the cluster data, even if the status is lost. Clusters reconciled before the default roots were introduced
+// keep using the KeeperCluster root.
+func (r *clickhouseReconciler) pinKeeperRoot(ctx context.Context, log ctrlutil.Logger) error {
+	if r.Cluster.Spec.KeeperClusterRef == nil || r.Cluster.Annotations[ctrlutil.AnnotationKeeperRoot] != "" {
+		return nil
+	}
+
+	root := r.Cluster.KeeperRoot()
+	if r.Cluster.Status.UpdateRevision != "" && r.Cluster.Spec.Coordination.Root == "" {
+		root = v1.KeeperRootNone
+	}
+
+	if err := r.patchClusterMetadata(ctx, func(meta *metav1.ObjectMeta) {
+		metav1.SetMetaDataAnnotation(meta, ctrlutil.AnnotationKeeperRoot, root)
+	}); err != nil {
+		return fmt.Errorf("pin keeper root %q: %w", root, err)
+	}
+
+	log.Info("pinned cluster keeper root", "root", root)
+
+	return nil
+}
+
+// patchClusterMetadata applies the metadata change to the stored cluster.
+// The patched copy keeps the in-memory status from being overwritten by the stored one.
+func (r *clickhouseReconciler) patchClusterMetadata(ctx context.Context, mutate func(meta *metav1.ObjectMeta)) error {
+	patched := r.Cluster.DeepCopy()
+	mutate(&patched.ObjectMeta)
+
+	if err := r.GetClient().Patch(ctx, patched, client.MergeFromWithOptions(r.Cluster, client.MergeFromWithOptimisticLock{})); err != nil {
+		return fmt.Errorf("patch ClickHouseCluster metadata: %w", err)
+	}
+
+	r.Cluster.ObjectMeta = patched.ObjectMeta
+
+	return nil
+}
+
+// checkKeeperRootConflict stops reconciling the cluster using the root of another ClickHouse cluster created earlier.
+// The webhook rejects such clusters, the check guards the clusters admitted while the webhook was unavailable.
+func (r *clickhouseReconciler) checkKeeperRootConflict(ctx context.Context) error {
+	var clusters v1.ClickHouseClusterList
+	if err := r.GetClient().List(ctx, &clusters); err != nil {
+		return fmt.Errorf("list ClickHouse clusters: %w", err)
+	}
+
+	conflict := findKeeperRootConflict(r.Cluster, clusters.Items)
+	if conflict == nil {
+		return nil
+	}
+
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonKeeperRootConflict, v1.EventActionReconciling,
+		"Keeper root %q of KeeperCluster %s is already used by ClickHouseCluster %s, set coordination.root",
+		r.Cluster.KeeperRoot(), r.keeper.NamespacedName(), conflict.NamespacedName())
+
+	return fmt.Errorf("keeper root %q of keeper cluster %s is already used by ClickHouse cluster %s",
+		r.Cluster.KeeperRoot(), r.keeper.NamespacedName(), conflict.NamespacedName())
+}
+
+// reconcileKeeperNetworkPolicy reconciles the policy allowing ClickHouse replicas to access the Keeper cluster
+// restricting access to its client ports.
+// Policies can't be owned by a cluster in another namespace, access from allowed namespaces is granted
+// by the Keeper cluster policy instead. The policy left from the previous Keeper cluster in the same namespace
+// is removed once the cluster switches to another namespace or to the external coordination.
+func (r *clickhouseReconciler) reconcileKeeperNetworkPolicy(ctx context.Context, log ctrlutil.Logger) error {
+	keeperPolicy := templateKeeperNetworkPolicy(r.Cluster, &r.keeper)
+	policyEnabled := r.Cluster.Spec.Coordination.External == nil &&
+		r.keeper.Spec.NetworkPolicy.Enabled && r.keeper.Namespace == r.Cluster.Namespace
+	if _, err := r.ReconcileNetworkPolicy(ctx, log, keeperPolicy, policyEnabled, v1.EventActionReconciling); err != nil {
+		return fmt.Errorf("reconcile Keeper NetworkPolicy resource: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileKeeperCluster fetches the referenced KeeperCluster and grants the cluster access to it.
+func (r *clickhouseReconciler) reconcileKeeperCluster(ctx context.Context, log ctrlutil.Logger) error {
+	if err := r.GetClient().Get(ctx, r.Cluster.KeeperClusterNamespacedName(), &r.keeper); err != nil {
+		return fmt.Errorf("get keeper cluster: %w", err)
+	}
+
+	if !r.keeper.AllowsNamespace(r.Cluster.Namespace) {
+		return fmt.Errorf("keeper cluster %s does not allow ClickHouse clusters from namespace %q",
+			r.keeper.NamespacedName(), r.Cluster.Namespace)
+	}
+
+	if err := r.checkKeeperRootConflict(ctx); err != nil {
+		return err
+	}
+
+	if err := r.reconcileKeeperNetworkPolicy(ctx, log); err != nil {
+		return err
+	}
+
+	// The root is removed with the cluster, so the cluster recreated with the same name and a new identity
+	// can create it again.
+	if r.Cluster.KeeperRoot() != "" && !slices.Contains(r.Cluster.Finalizers, FinalizerKeeperRoot) {
+		if err := r.patchClusterMetadata(ctx, func(meta *metav1.ObjectMeta) {
+			meta.Finalizers = append(meta.Finalizers, FinalizerKeeperRoot)
+		}); err != nil {
+			return fmt.Errorf("add keeper root finalizer: %w", err)
+		}
+	}
+
+	cond := meta.FindStatusCondition(r.keeper.Status.Conditions, string(v1.ConditionTypeReady))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		if cond == nil {
+			log.Warn("keeper cluster is not ready")
+		} else {
+			log.Warn("keeper cluster is not ready", "reason", cond.Reason, "message", cond.Message)
+		}
+
+		// The root is created once the Keeper cluster becomes ready, its status change triggers reconcile.
+		return nil
+	}
+
+	root := r.Cluster.KeeperRoot()
+	if root == "" {
+		return nil
+	}
+
+	// The root is ensured on every reconcile, it is lost if the KeeperCluster is recreated.
+	if err := ensureKeeperRoot(ctx, log, &r.keeper, root, string(r.secret.Data[SecretKeyKeeperIdentity])); err != nil {
+		return fmt.Errorf("prepare keeper root %q: %w", root, err)
+	}
+
+	return nil
+}
+
 func (r *clickhouseReconciler) reconcileActiveReplicaStatus(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
 	listOpts := ctrlutil.AppRequirements(r.Cluster.Namespace, r.Cluster.SpecificName())
 
@@ -335,17 +619,37 @@ func (r *clickhouseReconciler) reconcileActiveReplicaStatus(ctx context.Context,
 			hasError = true
 		}
 
+		var (
+			healthProblems []string
+			caughtUp       bool
+		)
+
 		pingErr := r.commander.Ping(ctx, id)
 		if pingErr != nil {
 			log.Debug("failed to ping replica", "replica_id", id, "error", pingErr)
+		} else {
+			// Health is unknown if the check failed, the replica responding to ping is not reported unhealthy.
+			health, err := r.commander.Health(ctx, id)
+			if err != nil {
+				log.Info("failed to check replica health", "replica_id", id, "error", err)
+			} else {
+				healthProblems = health.Problems(r.Cluster.Spec.Settings.ReplicaHealth)
+				caughtUp = health.CaughtUp(r.Cluster.Spec.Settings.ReplicaHealth)
+			}
+
+			if len(healthProblems) > 0 {
+				log.Info("replica is unhealthy", "replica_id", id, "problems", healthProblems)
+			}
 		}
 
 		log.Debug("load replica state done", "replica_id", id, "statefulset", sts.Name)
 
 		return id, replicaState{
-			StatefulSet: &sts,
-			Error:       hasError,
-			Pinged:      pingErr == nil,
+			StatefulSet:    &sts,
+			Error:          hasError,
+			Pinged:         pingErr == nil,
+			HealthProblems: healthProblems,
+			CaughtUp:       caughtUp,
 		}, nil
 	})
 
@@ -374,12 +678,19 @@ func (r *clickhouseReconciler) reconcileActiveReplicaStatus(ctx context.Context,
 // If all replicas exists performs rolling upgrade, with the following order preferences:
 // NotExists -> CrashLoop/ImagePullErr -> OnlySts -> OnlyConfig -> Any.
 func (r *clickhouseReconciler) reconcileReplicaResources(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	if err := r.clearCaughtUpReplicas(ctx, log); err != nil {
+		return nil, err
+	}
+
 	highestStage := chctrl.StageUpToDate
 
 	var replicasInStatus []v1.ClickHouseReplicaID
 
+	stageCounts := map[chctrl.ReplicaUpdateStage]int{}
 	for id := range r.Cluster.ReplicaIDs() {
 		stage := r.Replica(id).UpdateStage(r)
+		stageCounts[stage]++
+
 		if stage == highestStage {
 			replicasInStatus = append(replicasInStatus, id)
 			continue
@@ -391,6 +702,8 @@ func (r *clickhouseReconciler) reconcileReplicaResources(ctx context.Context, lo
 		}
 	}
 
+	chctrl.SetReplicaStageCounts(chctrl.MetricsControllerClickHouse, r.Cluster.NamespacedName(), stageCounts)
+
 	result := ctrl.Result{}
 
 	switch highestStage {
@@ -400,6 +713,10 @@ func (r *clickhouseReconciler) reconcileReplicaResources(ctx context.Context, lo
 	case chctrl.StageNotReadyUpToDate, chctrl.StageUpdating:
 		log.Info("waiting for updated replicas to become ready", "replicas", replicasInStatus, "priority", highestStage.String())
 
+		result = ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}
+	case chctrl.StageCatchingUp:
+		log.Info("waiting for replicas to catch up replication", "replicas", replicasInStatus)
+
 		result = ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}
 	case chctrl.StageHasDiff:
 		// Leave one replica to rolling update. replicasInStatus must not be empty.
@@ -430,6 +747,46 @@ func (r *clickhouseReconciler) reconcileReplicaResources(ctx context.Context, lo
 	return &result, nil
 }
 
+// clearCaughtUpReplicas removes the catch up mark from the restarted replicas that have caught up the replication
+// or have not caught up within the catch up timeout.
+func (r *clickhouseReconciler) clearCaughtUpReplicas(ctx context.Context, log ctrlutil.Logger) error {
+	timeout := time.Duration(r.Cluster.Spec.Settings.ReplicaHealth.CatchUpTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = v1.DefaultCatchUpTimeoutSeconds * time.Second
+	}
+
+	now := time.Now()
+
+	for id, replica := range r.ReplicaState {
+		if !replica.CatchUpPending() || !replica.Ready() {
+			continue
+		}
+
+		restartedAt := replica.StatefulSet.Annotations[ctrlutil.AnnotationCatchUpPending]
+
+		switch {
+		case replica.CaughtUp:
+			log.Info("restarted replica caught up the replication", "replica_id", id, "restarted_at", restartedAt)
+		case replica.CatchUpTimedOut(timeout, now):
+			log.Warn("restarted replica has not caught up the replication in time, proceeding with the rolling update",
+				"replica_id", id, "restarted_at", restartedAt, "timeout", timeout)
+			r.GetRecorder().Eventf(r.Cluster, replica.StatefulSet, corev1.EventTypeWarning, v1.EventReasonReplicaCatchUpTimedOut,
+				v1.EventActionUpdating, "Replica %s restarted at %s has not caught up the replication within %s, "+
+					"proceeding with the rolling update", id, restartedAt, timeout)
+		default:
+			continue
+		}
+
+		delete(replica.StatefulSet.Annotations, ctrlutil.AnnotationCatchUpPending)
+
+		if err := r.Update(ctx, replica.StatefulSet, v1.EventActionReconciling); err != nil {
+			return fmt.Errorf("clear catch up mark of replica %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
 func (r *clickhouseReconciler) reconcileReplicateSchema(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
 	if !r.Cluster.Spec.Settings.EnableDatabaseSync {
 		log.Info("database sync is disabled, skipping")
@@ -448,14 +805,9 @@ func (r *clickhouseReconciler) reconcileReplicateSchema(ctx context.Context, log
 		return nil, nil
 	}
 
-	hasNotSynced := false
-	replicaDatabases := ctrlutil.ExecuteParallel(readyReplicas, func(id v1.ClickHouseReplicaID) (v1.ClickHouseReplicaID, map[string]databaseDescriptor, error) {
-		if err := r.commander.EnsureDefaultDatabaseEngine(ctx, log, r.Cluster, id); err != nil {
-			log.Info("failed to ensure default database engine for replica", "replica", id, "error", err)
-
-			hasNotSynced = true
-		}
+	hasNotSynced := !r.migrateDefaultDatabase(ctx, log, readyReplicas)
 
+	replicaDatabases := ctrlutil.ExecuteParallel(readyReplicas, func(id v1.ClickHouseReplicaID) (v1.ClickHouseReplicaID, map[string]databaseDescriptor, error) {
 		databases, err := r.commander.Databases(ctx, id)
 
 		return id, databases, err
@@ -498,6 +850,10 @@ func (r *clickhouseReconciler) reconcileReplicateSchema(ctx context.Context, log
 		return id, struct{}{}, nil
 	})
 
+	if !r.replicateTables(ctx, log, readyReplicas) {
+		hasNotSynced = true
+	}
+
 	r.databasesInSync = !hasNotSynced
 	if hasNotSynced {
 		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
@@ -506,6 +862,146 @@ func (r *clickhouseReconciler) reconcileReplicateSchema(ctx context.Context, log
 	return nil, nil
 }
 
+// migrateDefaultDatabase ensures the `default` database has the Replicated engine on all ready replicas.
+// Replicas where the migration is blocked by the policy are stored for the SchemaInSync condition.
+// Returns false if the migration failed on some replica.
+func (r *clickhouseReconciler) migrateDefaultDatabase(ctx context.Context, log ctrlutil.Logger, readyReplicas []v1.ClickHouseReplicaID) bool {
+	results := ctrlutil.ExecuteParallel(readyReplicas, func(id v1.ClickHouseReplicaID) (v1.ClickHouseReplicaID, defaultDatabaseMigration, error) {
+		migration, err := r.commander.EnsureDefaultDatabaseEngine(ctx, log, r.Cluster, id)
+		return id, migration, err
+	})
+
+	succeeded := true
+	r.defaultDatabaseBlocked = nil
+	r.defaultDatabaseRenamed = nil
+
+	for id, res := range results {
+		hostname := r.Cluster.HostnameByID(id)
+
+		switch res.Result.Action {
+		case defaultDatabaseRenamed:
+			r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonDefaultDatabaseRenamed,
+				v1.EventActionReconciling, "Database `default` with tables is renamed to %q on replica %q. "+
+					"Its tables are not replicated: recreate them in the Replicated `default` database, "+
+					"copy the data with INSERT INTO default.<table> SELECT * FROM %s.<table> and drop %q",
+				res.Result.RenamedTo, hostname, res.Result.RenamedTo, res.Result.RenamedTo)
+		case defaultDatabaseDropped:
+			r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonDefaultDatabaseDropped,
+				v1.EventActionReconciling, "Database `default` with tables is dropped on replica %q", hostname)
+		}
+
+		for _, database := range res.Result.PendingRenamed {
+			r.defaultDatabaseRenamed = append(r.defaultDatabaseRenamed, fmt.Sprintf("%s on replica %q", database, hostname))
+		}
+
+		switch {
+		case errors.Is(res.Err, errDefaultDatabaseNotEmpty):
+			r.defaultDatabaseBlocked = append(r.defaultDatabaseBlocked, id)
+		case res.Err != nil:
+			log.Info("failed to ensure default database engine for replica", "replica", id, "error", res.Err)
+
+			succeeded = false
+		}
+	}
+
+	slices.SortFunc(r.defaultDatabaseBlocked, compareReplicaID)
+	slices.Sort(r.defaultDatabaseRenamed)
+
+	return succeeded
+}
+
+// replicateTables creates replicated tables of non-Replicated databases on the new replicas and waits for them
+// to fetch the data. Tables are taken from the other ready replicas of the same shard, a table may exist only on
+// some shards. Existing replicas are never synced, so tables dropped without ON CLUSTER are not recreated.
+// Returns false if some new replica is not in sync.
+func (r *clickhouseReconciler) replicateTables(ctx context.Context, log ctrlutil.Logger, readyReplicas []v1.ClickHouseReplicaID) bool {
+	pendingShards := map[int32]struct{}{}
+	for _, id := range readyReplicas {
+		if r.Replica(id).TableSyncPending() {
+			pendingShards[id.ShardID] = struct{}{}
+		}
+	}
+
+	if len(pendingShards) == 0 {
+		return true
+	}
+
+	var shardReplicas []v1.ClickHouseReplicaID
+	for _, id := range readyReplicas {
+		if _, ok := pendingShards[id.ShardID]; ok {
+			shardReplicas = append(shardReplicas, id)
+		}
+	}
+
+	replicaTables := ctrlutil.ExecuteParallel(shardReplicas, func(id v1.ClickHouseReplicaID) (v1.ClickHouseReplicaID, map[string]tableDescriptor, error) {
+		tables, err := r.commander.ReplicatedTables(ctx, id)
+		return id, tables, err
+	})
+
+	inSync := true
+	shardTables := map[int32]map[string]tableDescriptor{}
+	for id, replTables := range replicaTables {
+		if replTables.Err != nil {
+			log.Warn("failed to get replicated tables from replica", "replica_id", id, "error", replTables.Err)
+
+			// Tables of the shard are not known, new replicas of the shard are synced by the next reconcile.
+			delete(pendingShards, id.ShardID)
+			inSync = false
+			continue
+		}
+
+		shardTables[id.ShardID] = ctrlutil.MergeMaps(shardTables[id.ShardID], replTables.Result)
+	}
+
+	var pendingReplicas []v1.ClickHouseReplicaID
+	for _, id := range shardReplicas {
+		if _, ok := pendingShards[id.ShardID]; ok && r.Replica(id).TableSyncPending() {
+			pendingReplicas = append(pendingReplicas, id)
+		}
+	}
+
+	results := ctrlutil.ExecuteParallel(pendingReplicas, func(id v1.ClickHouseReplicaID) (v1.ClickHouseReplicaID, struct{}, error) {
+		tablesToSync := map[string]tableDescriptor{}
+		for name, desc := range shardTables[id.ShardID] {
+			if _, ok := replicaTables[id].Result[name]; !ok {
+				tablesToSync[name] = desc
+			}
+		}
+
+		if len(tablesToSync) > 0 {
+			log.Info("replicating tables to replica", "replica_id", id, "tables", slices.Collect(maps.Keys(tablesToSync)))
+
+			if err := r.commander.CreateTables(ctx, id, tablesToSync); err != nil {
+				return id, struct{}{}, err
+			}
+		}
+
+		// Tables created by the previous reconcile may still be fetching the data.
+		return id, struct{}{}, r.commander.SyncTables(ctx, id, slices.Sorted(maps.Keys(shardTables[id.ShardID])))
+	})
+
+	for id, res := range results {
+		if res.Err != nil {
+			log.Info("failed to sync tables to replica", "error", res.Err, "replica_id", id)
+
+			inSync = false
+			continue
+		}
+
+		sts := r.Replica(id).StatefulSet
+		log.Info("replicated tables are synced to the new replica", "replica_id", id)
+		delete(sts.Annotations, ctrlutil.AnnotationTableSyncPending)
+
+		if err := r.Update(ctx, sts, v1.EventActionReconciling); err != nil {
+			log.Info("failed to clear table sync mark of replica", "error", err, "replica_id", id)
+
+			inSync = false
+		}
+	}
+
+	return inSync
+}
+
 type replicaResources struct {
 	cfg *corev1.ConfigMap
 	sts *appsv1.StatefulSet
@@ -633,6 +1129,7 @@ func (r *clickhouseReconciler) reconcileConditions(ctx context.Context, log ctrl
 	var (
 		errorReplicas      []v1.ClickHouseReplicaID
 		notReadyReplicas   []v1.ClickHouseReplicaID
+		unhealthyReplicas  []v1.ClickHouseReplicaID
 		notUpdatedReplicas []v1.ClickHouseReplicaID
 		notReadyShards     []int32
 	)
@@ -648,6 +1145,10 @@ func (r *clickhouseReconciler) reconcileConditions(ctx context.Context, log ctrl
 				errorReplicas = append(errorReplicas, id)
 			}
 
+			if len(replica.HealthProblems) > 0 {
+				unhealthyReplicas = append(unhealthyReplicas, id)
+			}
+
 			if !replica.Ready() {
 				notReadyReplicas = append(notReadyReplicas, id)
 			} else {
@@ -676,6 +1177,18 @@ func (r *clickhouseReconciler) reconcileConditions(ctx context.Context, log ctrl
 	if len(notReadyReplicas) > 0 {
 		slices.SortFunc(notReadyReplicas, compareReplicaID)
 		message := fmt.Sprintf("Not ready replicas: %v", notReadyReplicas)
+
+		if len(unhealthyReplicas) > 0 {
+			slices.SortFunc(unhealthyReplicas, compareReplicaID)
+
+			problems := make([]string, 0, len(unhealthyReplicas))
+			for _, id := range unhealthyReplicas {
+				problems = append(problems, fmt.Sprintf("%v: %s", id, strings.Join(r.Replica(id).HealthProblems, ", ")))
+			}
+
+			message += fmt.Sprintf(". Unhealthy replicas: %s", strings.Join(problems, "; "))
+		}
+
 		r.SetCondition(log, r.NewCondition(v1.ConditionTypeHealthy, metav1.ConditionFalse, v1.ConditionReasonReplicasNotReady, message))
 	} else {
 		r.SetCondition(log, r.NewCondition(v1.ConditionTypeHealthy, metav1.ConditionTrue, v1.ConditionReasonReplicasReady, ""))
@@ -724,32 +1237,37 @@ func (r *clickhouseReconciler) reconcileConditions(ctx context.Context, log ctrl
 		return nil, fmt.Errorf("update ready condition: %w", err)
 	}
 
-	{
-		condType := metav1.ConditionTrue
-		condReason := v1.ClickHouseConditionSchemaSyncDisabled
-
-		condMessage := "Database schema sync is disabled"
-		if r.Cluster.Spec.Settings.EnableDatabaseSync {
-			switch {
-			case !r.databasesInSync:
-				condType = metav1.ConditionFalse
-				condReason = v1.ClickHouseConditionDatabasesNotCreated
-				condMessage = "Some databases are not created on all replicas"
-			case !r.staleReplicasCleanedUp:
-				condType = metav1.ConditionFalse
-				condReason = v1.ClickHouseConditionReplicasNotCleanedUp
-				condMessage = "Some stale replicas are not cleaned up"
-			default:
-				condType = metav1.ConditionTrue
-				condReason = v1.ClickHouseConditionReplicasInSync
-				condMessage = "Databases are sync on all replicas"
-			}
-		}
+	condType, condReason, condMessage := r.schemaInSyncCondition()
+	r.SetCondition(log, r.NewCondition(v1.ClickHouseConditionTypeSchemaInSync, condType, condReason, condMessage))
+
+	return nil, nil
+}
 
-		r.SetCondition(log, r.NewCondition(v1.ClickHouseConditionTypeSchemaInSync, condType, condReason, condMessage))
+// schemaInSyncCondition returns the SchemaInSync condition fields for the last schema sync results.
+func (r *clickhouseReconciler) schemaInSyncCondition() (metav1.ConditionStatus, v1.ConditionReason, string) {
+	if !r.Cluster.Spec.Settings.EnableDatabaseSync {
+		return metav1.ConditionTrue, v1.ClickHouseConditionSchemaSyncDisabled, "Database schema sync is disabled"
 	}
 
-	return nil, nil
+	switch {
+	case len(r.defaultDatabaseBlocked) > 0:
+		return metav1.ConditionFalse, v1.ClickHouseConditionDefaultDatabaseNotReplicated,
+			fmt.Sprintf("Database `default` is not Replicated and contains tables on replicas %v. "+
+				"Move the tables to another database, or set settings.defaultDatabaseMigration to Rename or Drop",
+				r.defaultDatabaseBlocked)
+	case len(r.defaultDatabaseRenamed) > 0:
+		return metav1.ConditionFalse, v1.ClickHouseConditionDefaultDatabaseRenamed,
+			fmt.Sprintf("Tables of the non-Replicated `default` database are kept in %s and are not replicated. "+
+				"Recreate them in the Replicated `default` database, copy the data with "+
+				"INSERT INTO default.<table> SELECT * FROM <database>.<table> and drop the renamed database",
+				strings.Join(r.defaultDatabaseRenamed, ", "))
+	case !r.databasesInSync:
+		return metav1.ConditionFalse, v1.ClickHouseConditionDatabasesNotCreated, "Some databases are not created on all replicas"
+	case !r.staleReplicasCleanedUp:
+		return metav1.ConditionFalse, v1.ClickHouseConditionReplicasNotCleanedUp, "Some stale replicas are not cleaned up"
+	default:
+		return metav1.ConditionTrue, v1.ClickHouseConditionReplicasInSync, "Databases are sync on all replicas"
+	}
 }
 
 func (r *clickhouseReconciler) updateReplica(ctx context.Context, log ctrlutil.Logger, id v1.ClickHouseReplicaID) (*ctrl.Result, error) {
@@ -780,6 +1298,8 @@ func (r *clickhouseReconciler) updateReplica(ctx context.Context, log ctrlutil.L
 		log.Info("replica StatefulSet not found, creating", "stateful_set", statefulSet.Name)
 		ctrlutil.AddObjectConfigHash(statefulSet, r.Cluster.Status.ConfigurationRevision)
 		ctrlutil.AddHashWithKeyToAnnotations(statefulSet, ctrlutil.AnnotationSpecHash, r.Cluster.Status.StatefulSetRevision)
+		// Tables are synced only to the new replicas, a table missing on the existing replica may be dropped on purpose.
+		ctrlutil.AddHashWithKeyToAnnotations(statefulSet, ctrlutil.AnnotationTableSyncPending, time.Now().Format(time.RFC3339))
 
 		if err := r.Create(ctx, statefulSet, v1.EventActionReconciling); err != nil {
 			return nil, fmt.Errorf("create replica %s: %w", id, err)
@@ -846,6 +1366,8 @@ func (r *clickhouseReconciler) updateReplica(ctx context.Context, log ctrlutil.L
 	replica.StatefulSet.Annotations = ctrlutil.MergeMaps(replica.StatefulSet.Annotations, statefulSet.Annotations)
 	replica.StatefulSet.Labels = ctrlutil.MergeMaps(replica.StatefulSet.Labels, statefulSet.Labels)
 	ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationSpecHash, r.Cluster.Status.StatefulSetRevision)
+	// The rolling update waits for the restarted replica to catch up the replication before updating the next one.
+	ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationCatchUpPending, time.Now().Format(time.RFC3339))
 
 	if err := r.Update(ctx, replica.StatefulSet, v1.EventActionReconciling); err != nil {
 		return nil, fmt.Errorf("update replica %s: %w", id, err)
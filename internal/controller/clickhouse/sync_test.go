@@ -0,0 +1,489 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// setupReconciler returns the reconciler of the test cluster backed by the fake Kubernetes client.
+// Every replica has the StatefulSet, replicas in pending are marked for the table sync.
+func setupReconciler(
+	cluster *v1.ClickHouseCluster,
+	conns map[v1.ClickHouseReplicaID]*fakeConn,
+	pending ...v1.ClickHouseReplicaID,
+) (ctrlutil.Logger, *clickhouseReconciler) {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(v1.AddToScheme(scheme)).To(Succeed())
+
+	logger := ctrlutil.NewLogger(zap.NewRaw(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	rec := &clickhouseReconciler{
+		reconcilerBase: chctrl.NewReconcilerBase[
+			v1.ClickHouseClusterStatus,
+			*v1.ClickHouseCluster,
+			v1.ClickHouseReplicaID,
+			replicaState,
+		](&ClusterController{
+			Scheme:   scheme,
+			Client:   fakeClient,
+			Logger:   logger,
+			Recorder: events.NewFakeRecorder(32),
+		}, cluster),
+		commander: newFakeCommander(cluster, conns),
+	}
+
+	for id := range cluster.ReplicaIDs() {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   cluster.Namespace,
+			Name:        cluster.StatefulSetNameByReplicaID(id),
+			Annotations: map[string]string{},
+		}}
+		for _, pendingID := range pending {
+			if pendingID == id {
+				sts.Annotations[ctrlutil.AnnotationTableSyncPending] = "2026-01-01T00:00:00Z"
+			}
+		}
+
+		Expect(fakeClient.Create(context.Background(), sts)).To(Succeed())
+		rec.SetReplica(id, replicaState{StatefulSet: sts})
+	}
+
+	return logger, rec
+}
+
+// fakeTablesServer emulates the replicated tables queries of a single ClickHouse replica.
+type fakeTablesServer struct {
+	tables    []tableDescriptor
+	listErr   error
+	createErr error
+	syncErr   error
+}
+
+func (s *fakeTablesServer) handle(query string, _ ...any) ([][]any, error) {
+	switch {
+	case query == listReplicatedTablesQuery:
+		rows := make([][]any, 0, len(s.tables))
+		for _, table := range s.tables {
+			rows = append(rows, []any{table})
+		}
+
+		return rows, s.listErr
+	case strings.HasPrefix(query, "CREATE "):
+		return nil, s.createErr
+	case strings.HasPrefix(query, "SYSTEM SYNC REPLICA "):
+		return nil, s.syncErr
+	}
+
+	return nil, errors.New("unexpected query: " + query)
+}
+
+var _ = Describe("replicateTables", func() {
+	var (
+		eventsTable = tableDescriptor{
+			Database:       "analytics",
+			Name:           "events",
+			CreateQuery:    "CREATE TABLE analytics.events UUID '9c4e6b2d-4a8c-4a61-9d3e-2f5b8a7c1e00' (`id` UInt64) ENGINE = ReplicatedMergeTree ORDER BY id",
+			DatabaseUUID:   "6f1c2a4e-8b3d-4e5f-9a7b-1c2d3e4f5a6b",
+			DatabaseEngine: "Atomic",
+		}
+		sessionsTable = tableDescriptor{
+			Database:       "analytics",
+			Name:           "sessions",
+			CreateQuery:    "CREATE TABLE analytics.sessions UUID '1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d' (`id` UInt64) ENGINE = ReplicatedMergeTree ORDER BY id",
+			DatabaseUUID:   "6f1c2a4e-8b3d-4e5f-9a7b-1c2d3e4f5a6b",
+			DatabaseEngine: "Atomic",
+		}
+
+		replica00 = v1.ClickHouseReplicaID{ShardID: 0, Index: 0}
+		replica01 = v1.ClickHouseReplicaID{ShardID: 0, Index: 1}
+		replica10 = v1.ClickHouseReplicaID{ShardID: 1, Index: 0}
+		replica11 = v1.ClickHouseReplicaID{ShardID: 1, Index: 1}
+		replicas  = []v1.ClickHouseReplicaID{replica00, replica01, replica10, replica11}
+
+		servers map[v1.ClickHouseReplicaID]*fakeTablesServer
+		conns   map[v1.ClickHouseReplicaID]*fakeConn
+		cluster *v1.ClickHouseCluster
+	)
+
+	BeforeEach(func() {
+		servers = map[v1.ClickHouseReplicaID]*fakeTablesServer{
+			replica00: {tables: []tableDescriptor{eventsTable}},
+			replica01: {},
+			replica10: {tables: []tableDescriptor{sessionsTable}},
+			replica11: {},
+		}
+		conns = map[v1.ClickHouseReplicaID]*fakeConn{}
+		for id, server := range servers {
+			conns[id] = &fakeConn{handler: server.handle}
+		}
+
+		cluster = &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+			Spec: v1.ClickHouseClusterSpec{
+				Replicas: ptr.To[int32](2),
+				Shards:   ptr.To[int32](2),
+			},
+		}
+	})
+
+	tableSyncPending := func(rec *clickhouseReconciler, id v1.ClickHouseReplicaID) bool {
+		var sts appsv1.StatefulSet
+		Expect(rec.GetClient().Get(context.Background(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.StatefulSetNameByReplicaID(id),
+		}, &sts)).To(Succeed())
+
+		_, ok := sts.Annotations[ctrlutil.AnnotationTableSyncPending]
+
+		return ok
+	}
+
+	It("should sync only the tables of the same shard to the new replica", func(ctx context.Context) {
+		log, rec := setupReconciler(cluster, conns, replica01)
+
+		Expect(rec.replicateTables(ctx, log, replicas)).To(BeTrue())
+		Expect(conns[replica01].Executed()).To(Equal([]string{
+			"CREATE DATABASE IF NOT EXISTS `analytics` UUID '6f1c2a4e-8b3d-4e5f-9a7b-1c2d3e4f5a6b' ENGINE = Atomic",
+			eventsTable.CreateIfNotExistsQuery(),
+			"SYSTEM SYNC REPLICA `analytics`.`events` LIGHTWEIGHT",
+		}))
+		Expect(tableSyncPending(rec, replica01)).To(BeFalse())
+	})
+
+	It("should not recreate tables missing on the existing replicas", func(ctx context.Context) {
+		log, rec := setupReconciler(cluster, conns)
+
+		Expect(rec.replicateTables(ctx, log, replicas)).To(BeTrue())
+		for _, conn := range conns {
+			Expect(conn.Executed()).To(BeEmpty())
+		}
+	})
+
+	It("should keep the new replica pending if tables of the shard are unknown", func(ctx context.Context) {
+		servers[replica00].listErr = errors.New("connection refused")
+		log, rec := setupReconciler(cluster, conns, replica01, replica11)
+
+		Expect(rec.replicateTables(ctx, log, replicas)).To(BeFalse())
+		Expect(conns[replica01].Executed()).To(BeEmpty())
+		Expect(tableSyncPending(rec, replica01)).To(BeTrue())
+		// Other shards are not affected.
+		Expect(conns[replica11].Executed()).To(HaveLen(3))
+		Expect(tableSyncPending(rec, replica11)).To(BeFalse())
+	})
+
+	It("should keep the new replica pending if table creation fails", func(ctx context.Context) {
+		servers[replica01].createErr = errors.New("not enough space")
+		log, rec := setupReconciler(cluster, conns, replica01)
+
+		Expect(rec.replicateTables(ctx, log, replicas)).To(BeFalse())
+		Expect(tableSyncPending(rec, replica01)).To(BeTrue())
+	})
+
+	It("should keep syncing created tables until the data is fetched", func(ctx context.Context) {
+		servers[replica01].syncErr = context.DeadlineExceeded
+		log, rec := setupReconciler(cluster, conns, replica01)
+
+		Expect(rec.replicateTables(ctx, log, replicas)).To(BeFalse())
+		Expect(tableSyncPending(rec, replica01)).To(BeTrue())
+
+		servers[replica01].tables = []tableDescriptor{eventsTable}
+		servers[replica01].syncErr = nil
+		Expect(rec.replicateTables(ctx, log, replicas)).To(BeTrue())
+		Expect(conns[replica01].Executed()).To(HaveLen(4))
+		Expect(conns[replica01].Executed()[3]).To(Equal("SYSTEM SYNC REPLICA `analytics`.`events` LIGHTWEIGHT"))
+		Expect(tableSyncPending(rec, replica01)).To(BeFalse())
+	})
+})
+
+// fakeDefaultDatabaseServer emulates the `default` database queries of a single ClickHouse replica.
+type fakeDefaultDatabaseServer struct {
+	engine    string
+	tables    uint64
+	databases []string
+	// Renamed databases which still contain tables.
+	renamedTables []string
+}
+
+func (s *fakeDefaultDatabaseServer) handle(query string, _ ...any) ([][]any, error) {
+	switch {
+	case strings.HasPrefix(query, "SELECT engine FROM system.databases WHERE name='default'"):
+		if s.engine == "" {
+			return nil, nil
+		}
+
+		return [][]any{{s.engine}}, nil
+	case strings.HasPrefix(query, "SELECT COUNT() FROM system.tables"):
+		return [][]any{{s.tables}}, nil
+	case query == renamedDefaultDatabasesQuery:
+		rows := make([][]any, 0, len(s.databases))
+		for _, name := range s.databases {
+			rows = append(rows, []any{name})
+		}
+
+		return rows, nil
+	case query == renamedDefaultTablesQuery:
+		rows := make([][]any, 0, len(s.renamedTables))
+		for _, name := range s.renamedTables {
+			rows = append(rows, []any{name})
+		}
+
+		return rows, nil
+	case strings.HasPrefix(query, "DROP DATABASE"),
+		strings.HasPrefix(query, "RENAME DATABASE"),
+		query == createDefaultDatabaseQuery:
+		return nil, nil
+	}
+
+	return nil, errors.New("unexpected query: " + query)
+}
+
+var _ = Describe("migrateDefaultDatabase", func() {
+	var (
+		id      = v1.ClickHouseReplicaID{}
+		server  *fakeDefaultDatabaseServer
+		conn    *fakeConn
+		cluster *v1.ClickHouseCluster
+	)
+
+	BeforeEach(func() {
+		server = &fakeDefaultDatabaseServer{engine: "Atomic", tables: 2}
+		conn = &fakeConn{handler: server.handle}
+		cluster = &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+			Spec: v1.ClickHouseClusterSpec{
+				Replicas: ptr.To[int32](1),
+				Settings: v1.ClickHouseSettings{
+					EnableDatabaseSync:       true,
+					DefaultDatabaseMigration: v1.DefaultDatabaseMigrationBlock,
+				},
+			},
+		}
+	})
+
+	migrate := func(ctx context.Context) (*clickhouseReconciler, bool) {
+		log, rec := setupReconciler(cluster, map[v1.ClickHouseReplicaID]*fakeConn{id: conn})
+		rec.databasesInSync = true
+		rec.staleReplicasCleanedUp = true
+
+		return rec, rec.migrateDefaultDatabase(ctx, log, []v1.ClickHouseReplicaID{id})
+	}
+
+	It("should recreate empty database with the Replicated engine", func(ctx context.Context) {
+		server.tables = 0
+
+		_, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(Equal([]string{"DROP DATABASE default SYNC", createDefaultDatabaseQuery}))
+	})
+
+	It("should block migration of database with tables", func(ctx context.Context) {
+		rec, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(BeEmpty())
+		Expect(rec.defaultDatabaseBlocked).To(Equal([]v1.ClickHouseReplicaID{id}))
+
+		status, reason, _ := rec.schemaInSyncCondition()
+		Expect(status).To(Equal(metav1.ConditionFalse))
+		Expect(reason).To(Equal(v1.ClickHouseConditionDefaultDatabaseNotReplicated))
+	})
+
+	It("should rename Atomic database with tables", func(ctx context.Context) {
+		cluster.Spec.Settings.DefaultDatabaseMigration = v1.DefaultDatabaseMigrationRename
+		server.renamedTables = []string{"default_non_replicated"}
+
+		rec, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(Equal([]string{
+			"RENAME DATABASE default TO `default_non_replicated`",
+			createDefaultDatabaseQuery,
+		}))
+
+		recorder := rec.GetRecorder().(*events.FakeRecorder)
+		Expect(recorder.Events).To(Receive(SatisfyAll(
+			ContainSubstring(v1.EventReasonDefaultDatabaseRenamed),
+			ContainSubstring("INSERT INTO default.<table> SELECT * FROM default_non_replicated.<table>"),
+		)))
+
+		status, reason, message := rec.schemaInSyncCondition()
+		Expect(status).To(Equal(metav1.ConditionFalse))
+		Expect(reason).To(Equal(v1.ClickHouseConditionDefaultDatabaseRenamed))
+		Expect(message).To(ContainSubstring(`default_non_replicated on replica "` + cluster.HostnameByID(id) + `"`))
+	})
+
+	It("should report renamed database until its tables are moved", func(ctx context.Context) {
+		server.engine = "Replicated"
+		server.renamedTables = []string{"default_non_replicated"}
+
+		rec, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(BeEmpty())
+
+		_, reason, _ := rec.schemaInSyncCondition()
+		Expect(reason).To(Equal(v1.ClickHouseConditionDefaultDatabaseRenamed))
+
+		server.renamedTables = nil
+		rec, succeeded = migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+
+		status, reason, _ := rec.schemaInSyncCondition()
+		Expect(status).To(Equal(metav1.ConditionTrue))
+		Expect(reason).To(Equal(v1.ClickHouseConditionReplicasInSync))
+	})
+
+	It("should rename database next to the previously renamed ones", func(ctx context.Context) {
+		cluster.Spec.Settings.DefaultDatabaseMigration = v1.DefaultDatabaseMigrationRename
+		server.databases = []string{"default_non_replicated", "default_non_replicated_1"}
+
+		_, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(Equal([]string{
+			"RENAME DATABASE default TO `default_non_replicated_2`",
+			createDefaultDatabaseQuery,
+		}))
+	})
+
+	It("should block rename of non-Atomic database", func(ctx context.Context) {
+		cluster.Spec.Settings.DefaultDatabaseMigration = v1.DefaultDatabaseMigrationRename
+		server.engine = "Ordinary"
+
+		rec, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(BeEmpty())
+		Expect(rec.defaultDatabaseBlocked).To(Equal([]v1.ClickHouseReplicaID{id}))
+	})
+
+	It("should drop database with tables", func(ctx context.Context) {
+		cluster.Spec.Settings.DefaultDatabaseMigration = v1.DefaultDatabaseMigrationDrop
+		server.engine = "Ordinary"
+
+		rec, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(Equal([]string{"DROP DATABASE default SYNC", createDefaultDatabaseQuery}))
+		Expect(rec.defaultDatabaseBlocked).To(BeEmpty())
+	})
+
+	It("should keep Replicated database", func(ctx context.Context) {
+		server.engine = "Replicated"
+
+		_, succeeded := migrate(ctx)
+		Expect(succeeded).To(BeTrue())
+		Expect(conn.Executed()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("reconcileKeeperNetworkPolicy", func() {
+	var (
+		cluster *v1.ClickHouseCluster
+		keeper  *v1.KeeperCluster
+	)
+
+	BeforeEach(func() {
+		cluster = &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test-uid"},
+			Spec:       v1.ClickHouseClusterSpec{Replicas: ptr.To[int32](1)},
+		}
+		keeper = &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "keeper"},
+			Spec:       v1.KeeperClusterSpec{NetworkPolicy: v1.NetworkPolicySpec{Enabled: true}},
+		}
+	})
+
+	reconcile := func(ctx context.Context) (ctrlutil.Logger, *clickhouseReconciler) {
+		log, rec := setupReconciler(cluster, nil)
+		rec.keeper = *keeper
+		Expect(rec.reconcileKeeperNetworkPolicy(ctx, log)).To(Succeed())
+
+		return log, rec
+	}
+
+	getPolicy := func(ctx context.Context, rec *clickhouseReconciler) error {
+		return rec.GetClient().Get(ctx, types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.SpecificName() + "-keeper",
+		}, &networkingv1.NetworkPolicy{})
+	}
+
+	It("should create policy for Keeper cluster in the same namespace", func(ctx context.Context) {
+		_, rec := reconcile(ctx)
+		Expect(getPolicy(ctx, rec)).To(Succeed())
+	})
+
+	It("should remove policy once cluster switches to Keeper cluster in another namespace", func(ctx context.Context) {
+		log, rec := reconcile(ctx)
+		Expect(getPolicy(ctx, rec)).To(Succeed())
+
+		rec.keeper.Namespace = "platform"
+		Expect(rec.reconcileKeeperNetworkPolicy(ctx, log)).To(Succeed())
+		Expect(k8serrors.IsNotFound(getPolicy(ctx, rec))).To(BeTrue())
+	})
+
+	It("should remove policy once cluster switches to external coordination", func(ctx context.Context) {
+		log, rec := reconcile(ctx)
+		Expect(getPolicy(ctx, rec)).To(Succeed())
+
+		rec.Cluster.Spec.Coordination.External = &v1.ExternalKeeperSpec{}
+		rec.keeper = v1.KeeperCluster{}
+		Expect(rec.reconcileKeeperNetworkPolicy(ctx, log)).To(Succeed())
+		Expect(k8serrors.IsNotFound(getPolicy(ctx, rec))).To(BeTrue())
+	})
+})
+
+var _ = Describe("clearCaughtUpReplicas", func() {
+	id := v1.ClickHouseReplicaID{}
+
+	setup := func(restartedAt time.Time) (ctrlutil.Logger, *clickhouseReconciler) {
+		cluster := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+			Spec: v1.ClickHouseClusterSpec{
+				Replicas: ptr.To[int32](1),
+				Settings: v1.ClickHouseSettings{ReplicaHealth: v1.ReplicaHealthSpec{CatchUpTimeoutSeconds: 600}},
+			},
+		}
+		log, rec := setupReconciler(cluster, nil)
+
+		replica := rec.Replica(id)
+		replica.StatefulSet.Annotations[ctrlutil.AnnotationCatchUpPending] = restartedAt.Format(time.RFC3339)
+		replica.StatefulSet.Status.ReadyReplicas = 1
+		replica.Pinged = true
+		rec.SetReplica(id, replica)
+
+		return log, rec
+	}
+
+	It("should keep waiting for the lagging replica before the timeout", func(ctx context.Context) {
+		log, rec := setup(time.Now())
+		Expect(rec.clearCaughtUpReplicas(ctx, log)).To(Succeed())
+		Expect(rec.Replica(id).CatchUpPending()).To(BeTrue())
+	})
+
+	It("should proceed with the rolling update once the timeout passes", func(ctx context.Context) {
+		log, rec := setup(time.Now().Add(-time.Hour))
+		Expect(rec.clearCaughtUpReplicas(ctx, log)).To(Succeed())
+		Expect(rec.Replica(id).CatchUpPending()).To(BeFalse())
+
+		recorder := rec.GetRecorder().(*events.FakeRecorder)
+		Expect(recorder.Events).To(Receive(ContainSubstring(v1.EventReasonReplicaCatchUpTimedOut)))
+	})
+})
@@ -1,45 +1,37 @@
 package clickhouse
 
 import (
+	_ "embed"
 	"fmt"
 	"maps"
 	"net"
 	"path"
+	"slices"
 	"strconv"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
 	"github.com/ClickHouse/clickhouse-operator/internal"
 	"github.com/ClickHouse/clickhouse-operator/internal/controller"
+	"github.com/ClickHouse/clickhouse-operator/internal/controller/keeper"
 	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
-func templateHeadlessService(cr *v1.ClickHouseCluster) *corev1.Service {
-	protocols := buildProtocols(cr)
-
-	ports := make([]corev1.ServicePort, 0, len(protocols))
-	for name, protocol := range protocols {
-		if protocol.Port == 0 {
-			continue
-		}
+//go:embed templates/pre-stop.sh
+var preStopScript string
 
-		ports = append(ports, corev1.ServicePort{
-			Protocol:   corev1.ProtocolTCP,
-			Name:       name,
-			Port:       int32(protocol.Port),
-			TargetPort: intstr.FromInt32(int32(protocol.Port)),
-		})
-	}
-
-	controllerutil.SortKey(ports, func(port corev1.ServicePort) string {
-		return port.Name
-	})
+func templateHeadlessService(cr *v1.ClickHouseCluster) *corev1.Service {
+	ports := buildServicePorts(buildProtocols(cr))
 
 	return &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
@@ -66,6 +58,330 @@ func templateHeadlessService(cr *v1.ClickHouseCluster) *corev1.Service {
 	}
 }
 
+func templateClusterService(cr *v1.ClickHouseCluster) *corev1.Service {
+	return templateClientService(cr, cr.Spec.Services.Cluster, cr.ClusterServiceName(), map[string]string{
+		controllerutil.LabelAppKey: cr.SpecificName(),
+	})
+}
+
+func templateShardService(cr *v1.ClickHouseCluster, shardID int32) *corev1.Service {
+	return templateClientService(cr, cr.Spec.Services.Shard, cr.ShardServiceName(shardID), map[string]string{
+		controllerutil.LabelAppKey:            cr.SpecificName(),
+		controllerutil.LabelClickHouseShardID: strconv.Itoa(int(shardID)),
+	})
+}
+
+// templateClientService returns the Service routing client connections to Ready replicas matching the selector.
+func templateClientService(cr *v1.ClickHouseCluster, spec v1.ServiceTemplateSpec, name string, selector map[string]string) *corev1.Service {
+	protocols := buildProtocols(cr)
+	for _, internalName := range internalProtocols {
+		delete(protocols, internalName)
+	}
+
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   cr.Namespace,
+			Labels:      controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, selector),
+			Annotations: controllerutil.MergeMaps(cr.Spec.Annotations, spec.Annotations),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     spec.Type,
+			Ports:    buildServicePorts(protocols),
+			Selector: selector,
+		},
+	}
+
+	if service.Spec.Type == "" {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+
+	if service.Spec.Type != corev1.ServiceTypeClusterIP {
+		service.Spec.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+	}
+
+	return service
+}
+
+func templateNetworkPolicy(cr *v1.ClickHouseCluster, operatorNamespace string) *networkingv1.NetworkPolicy {
+	var openPorts []int32
+	for name, protocol := range buildProtocols(cr) {
+		if name != "interserver" && name != "management" && protocol.Port > 0 {
+			openPorts = append(openPorts, int32(protocol.Port))
+		}
+	}
+
+	slices.Sort(openPorts)
+
+	return controller.TemplateNetworkPolicy(metav1.ObjectMeta{
+		Name:      cr.SpecificName(),
+		Namespace: cr.Namespace,
+		Labels: controllerutil.MergeMaps(cr.Spec.Labels, map[string]string{
+			controllerutil.LabelAppKey: cr.SpecificName(),
+		}),
+		Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+	}, cr.SpecificName(), []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: controller.NetworkPolicyPorts(PortInterserver, PortManagement),
+			From:  append([]networkingv1.NetworkPolicyPeer{controller.AppPeer(cr.SpecificName())}, controller.NamespacePeers(operatorNamespace)...),
+		},
+		{
+			Ports: controller.NetworkPolicyPorts(openPorts...),
+		},
+	})
+}
+
+// templateKeeperNetworkPolicy returns the NetworkPolicy allowing ClickHouse replicas to connect to the Keeper client ports.
+// Required if the referenced KeeperCluster restricts access with NetworkPolicy.
+// The policy is always placed in the cluster namespace, it is used only with a KeeperCluster in the same namespace.
+func templateKeeperNetworkPolicy(cr *v1.ClickHouseCluster, keeperCluster *v1.KeeperCluster) *networkingv1.NetworkPolicy {
+	return controller.TemplateNetworkPolicy(metav1.ObjectMeta{
+		Name:      cr.SpecificName() + "-keeper",
+		Namespace: cr.Namespace,
+		Labels: controllerutil.MergeMaps(cr.Spec.Labels, map[string]string{
+			controllerutil.LabelAppKey: cr.SpecificName(),
+		}),
+		Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+	}, keeperCluster.SpecificName(), []networkingv1.NetworkPolicyIngressRule{{
+		Ports: controller.NetworkPolicyPorts(keeper.PortNative, keeper.PortNativeSecure),
+		From:  []networkingv1.NetworkPolicyPeer{controller.AppPeer(cr.SpecificName())},
+	}})
+}
+
+func templateIngress(cr *v1.ClickHouseCluster) *networkingv1.Ingress {
+	spec := cr.Spec.Services.Ingress
+	portName, _ := httpBackendPort(cr)
+
+	var backendAnnotations map[string]string
+	if portName == "http-secure" {
+		backendAnnotations = map[string]string{IngressBackendProtocolAnnotation: "HTTPS"}
+	}
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.SpecificName(),
+			Namespace: cr.Namespace,
+			Labels: controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, map[string]string{
+				controllerutil.LabelAppKey: cr.SpecificName(),
+			}),
+			Annotations: controllerutil.MergeMaps(backendAnnotations, cr.Spec.Annotations, spec.Annotations),
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.ClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: spec.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: ptr.To(networkingv1.PathTypePrefix),
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: cr.ClusterServiceName(),
+									Port: networkingv1.ServiceBackendPort{Name: portName},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	if spec.TLSSecret != nil {
+		tls := networkingv1.IngressTLS{SecretName: spec.TLSSecret.Name}
+		if spec.Host != "" {
+			tls.Hosts = []string{spec.Host}
+		}
+
+		ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+
+	return ingress
+}
+
+func templateHTTPRoute(cr *v1.ClickHouseCluster) *gatewayv1.HTTPRoute {
+	_, port := httpBackendPort(cr)
+
+	return &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HTTPRoute",
+			APIVersion: gatewayv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: routeObjectMeta(cr),
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: buildRouteParentRefs(cr.Spec.Services.Gateway),
+			Hostnames:       buildRouteHostnames(cr.Spec.Services.Gateway),
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{{
+					BackendRef: buildRouteBackendRef(cr, port),
+				}},
+			}},
+		},
+	}
+}
+
+// templateBackendTLSPolicy returns the policy making the Gateway connect to the httpSecure port with TLS.
+// The server certificate is verified with the cluster CA bundle, or with the system CA certificates if the cluster has
+// neither the CA bundle nor the server certificate secret.
+func templateBackendTLSPolicy(cr *v1.ClickHouseCluster) *gatewayv1.BackendTLSPolicy {
+	spec := cr.Spec.Services.Gateway
+
+	caSecret := ""
+	if tls := cr.Spec.Settings.TLS; tls.CABundle != nil {
+		caSecret = tls.CABundle.Name
+	} else if tls.ServerCertSecret != nil {
+		caSecret = tls.ServerCertSecret.Name
+	}
+
+	hostname := spec.BackendHostname
+	if hostname == "" {
+		hostname = fmt.Sprintf("%s.%s.svc", cr.ClusterServiceName(), cr.Namespace)
+	}
+
+	validation := gatewayv1.BackendTLSPolicyValidation{
+		Hostname: gatewayv1.PreciseHostname(hostname),
+	}
+	if caSecret != "" {
+		validation.CACertificateRefs = []gatewayv1.LocalObjectReference{{
+			Kind: "Secret",
+			Name: gatewayv1.ObjectName(caSecret),
+		}}
+	} else {
+		validation.WellKnownCACertificates = ptr.To(gatewayv1.WellKnownCACertificatesSystem)
+	}
+
+	return &gatewayv1.BackendTLSPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BackendTLSPolicy",
+			APIVersion: gatewayv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: routeObjectMeta(cr),
+		Spec: gatewayv1.BackendTLSPolicySpec{
+			TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{{
+				LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+					Kind: "Service",
+					Name: gatewayv1.ObjectName(cr.ClusterServiceName()),
+				},
+				SectionName: ptr.To(gatewayv1.SectionName("http-secure")),
+			}},
+			Validation: validation,
+		},
+	}
+}
+
+func templateTLSRoute(cr *v1.ClickHouseCluster) *gatewayv1alpha2.TLSRoute {
+	return &gatewayv1alpha2.TLSRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TLSRoute",
+			APIVersion: gatewayv1alpha2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: routeObjectMeta(cr),
+		Spec: gatewayv1alpha2.TLSRouteSpec{
+			CommonRouteSpec: buildRouteParentRefs(cr.Spec.Services.Gateway),
+			Hostnames:       buildRouteHostnames(cr.Spec.Services.Gateway),
+			Rules: []gatewayv1alpha2.TLSRouteRule{{
+				BackendRefs: []gatewayv1alpha2.BackendRef{
+					buildRouteBackendRef(cr, buildProtocols(cr)["http-secure"].Port),
+				},
+			}},
+		},
+	}
+}
+
+// httpBackendPort returns the port name and number of the HTTP interface exposed by Ingress and HTTPRoute.
+// Plain HTTP is preferred, HTTPS is used if plain protocol is disabled.
+// The HTTPS backend requires the Ingress backend protocol annotation or the BackendTLSPolicy for HTTPRoute.
+func httpBackendPort(cr *v1.ClickHouseCluster) (string, uint16) {
+	protocols := buildProtocols(cr)
+	if protocols["http"].Port > 0 {
+		return "http", protocols["http"].Port
+	}
+
+	return "http-secure", protocols["http-secure"].Port
+}
+
+func routeObjectMeta(cr *v1.ClickHouseCluster) metav1.ObjectMeta {
+	spec := cr.Spec.Services.Gateway
+
+	return metav1.ObjectMeta{
+		Name:      cr.SpecificName(),
+		Namespace: cr.Namespace,
+		Labels: controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, map[string]string{
+			controllerutil.LabelAppKey: cr.SpecificName(),
+		}),
+		Annotations: controllerutil.MergeMaps(cr.Spec.Annotations, spec.Annotations),
+	}
+}
+
+func buildRouteParentRefs(spec v1.GatewayRouteSpec) gatewayv1.CommonRouteSpec {
+	parentRefs := make([]gatewayv1.ParentReference, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := gatewayv1.ParentReference{
+			Name: gatewayv1.ObjectName(ref.Name),
+		}
+		if ref.Namespace != "" {
+			parentRef.Namespace = ptr.To(gatewayv1.Namespace(ref.Namespace))
+		}
+
+		if ref.SectionName != "" {
+			parentRef.SectionName = ptr.To(gatewayv1.SectionName(ref.SectionName))
+		}
+
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	return gatewayv1.CommonRouteSpec{ParentRefs: parentRefs}
+}
+
+func buildRouteHostnames(spec v1.GatewayRouteSpec) []gatewayv1.Hostname {
+	hostnames := make([]gatewayv1.Hostname, 0, len(spec.Hostnames))
+	for _, hostname := range spec.Hostnames {
+		hostnames = append(hostnames, gatewayv1.Hostname(hostname))
+	}
+
+	return hostnames
+}
+
+func buildRouteBackendRef(cr *v1.ClickHouseCluster, port uint16) gatewayv1.BackendRef {
+	return gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{
+			Name: gatewayv1.ObjectName(cr.ClusterServiceName()),
+			Port: ptr.To(gatewayv1.PortNumber(port)),
+		},
+	}
+}
+
+func buildServicePorts(protocols map[string]protocol) []corev1.ServicePort {
+	ports := make([]corev1.ServicePort, 0, len(protocols))
+	for name, protocol := range protocols {
+		if protocol.Port == 0 {
+			continue
+		}
+
+		ports = append(ports, corev1.ServicePort{
+			Protocol:   corev1.ProtocolTCP,
+			Name:       name,
+			Port:       int32(protocol.Port),
+			TargetPort: intstr.FromInt32(int32(protocol.Port)),
+		})
+	}
+
+	controllerutil.SortKey(ports, func(port corev1.ServicePort) string {
+		return port.Name
+	})
+
+	return ports
+}
+
 func templatePodDisruptionBudget(cr *v1.ClickHouseCluster, shardID int32) *policyv1.PodDisruptionBudget {
 	minAvailable := intstr.FromInt32(1)
 
@@ -95,6 +411,77 @@ func templatePodDisruptionBudget(cr *v1.ClickHouseCluster, shardID int32) *polic
 	}
 }
 
+func templatePodMonitor(cr *v1.ClickHouseCluster) *monitoringv1.PodMonitor {
+	spec := cr.Spec.Monitoring.PodMonitor
+
+	return &monitoringv1.PodMonitor{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       monitoringv1.PodMonitorsKind,
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.SpecificName(),
+			Namespace: cr.Namespace,
+			Labels: controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, map[string]string{
+				controllerutil.LabelAppKey: cr.SpecificName(),
+			}),
+			Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+		},
+		Spec: monitoringv1.PodMonitorSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					controllerutil.LabelAppKey: cr.SpecificName(),
+				},
+			},
+			PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{
+				controller.TemplatePodMetricsEndpoint(spec, []monitoringv1.RelabelConfig{
+					controller.PodLabelRelabeling(controllerutil.LabelClickHouseShardID, "shard"),
+					controller.PodLabelRelabeling(controllerutil.LabelClickHouseReplicaID, "replica"),
+				}),
+			},
+		},
+	}
+}
+
+func templatePrometheusRule(cr *v1.ClickHouseCluster) *monitoringv1.PrometheusRule {
+	spec := cr.Spec.Monitoring.Alerts
+	selector := controller.PodMetricsSelector(cr.Namespace, cr.SpecificName()+"-[0-9]+-[0-9]+-0")
+
+	return controller.TemplatePrometheusRule(metav1.ObjectMeta{
+		Name:      cr.SpecificName(),
+		Namespace: cr.Namespace,
+		Labels: controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, map[string]string{
+			controllerutil.LabelAppKey: cr.SpecificName(),
+		}),
+		Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+	}, spec.AlertsSpec, cr.SpecificName()+".rules", []controller.AlertRule{
+		{
+			Name:     "ClickHouseReplicationLag",
+			Expr:     fmt.Sprintf("max by (namespace, pod) (ClickHouseAsyncMetrics_ReplicasMaxAbsoluteDelay{%s}) > %d", selector, spec.ReplicationDelaySeconds),
+			Severity: controller.AlertSeverityWarning,
+			Summary:  "ClickHouse replica is lagging behind",
+			Description: fmt.Sprintf("Replica {{ $labels.pod }} of ClickHouseCluster %s/%s is {{ $value }}s behind other replicas.",
+				cr.Namespace, cr.Name),
+		},
+		{
+			Name:     "ClickHouseReadonlyReplica",
+			Expr:     fmt.Sprintf("max by (namespace, pod) (ClickHouseMetrics_ReadonlyReplica{%s}) > 0", selector),
+			Severity: controller.AlertSeverityCritical,
+			Summary:  "ClickHouse replica is in read-only mode",
+			Description: fmt.Sprintf("Replica {{ $labels.pod }} of ClickHouseCluster %s/%s has {{ $value }} read-only tables. "+
+				"Usually it means the replica lost the connection to Keeper.", cr.Namespace, cr.Name),
+		},
+		{
+			Name:     "ClickHouseTooManyParts",
+			Expr:     fmt.Sprintf("max by (namespace, pod) (ClickHouseAsyncMetrics_MaxPartCountForPartition{%s}) > %d", selector, spec.PartsPerPartition),
+			Severity: controller.AlertSeverityWarning,
+			Summary:  "ClickHouse partition has too many parts",
+			Description: fmt.Sprintf("Replica {{ $labels.pod }} of ClickHouseCluster %s/%s has {{ $value }} parts in a single partition. "+
+				"Inserts will be delayed and then rejected if merges do not catch up.", cr.Namespace, cr.Name),
+		},
+	})
+}
+
 func templateClusterSecrets(cr *v1.ClickHouseCluster, secret *corev1.Secret) bool {
 	secret.Name = cr.SecretName()
 	secret.Namespace = cr.Namespace
@@ -202,13 +589,13 @@ func templateStatefulSet(r *clickhouseReconciler, id v1.ClickHouseReplicaID) (*a
 	if protocol, ok := protocols["http"]; ok && protocol.Port > 0 {
 		probeCommand = []string{"/bin/bash", "-c", fmt.Sprintf(
 			"wget -qO- http://%s | grep -o Ok.",
-			net.JoinHostPort("127.0.0.1", strconv.Itoa(PortHTTP)),
+			net.JoinHostPort("127.0.0.1", strconv.Itoa(int(protocol.Port))),
 		)}
 	} else {
 		probeCommand = []string{"/bin/bash", "-c", fmt.Sprintf(
 			"wget --ca-certificate=%s -qO- https://%s | grep -o Ok.",
 			path.Join(TLSConfigPath, CABundleFilename),
-			net.JoinHostPort(r.Cluster.HostnameByID(id), strconv.Itoa(PortHTTPSecure)),
+			net.JoinHostPort(r.Cluster.HostnameByID(id), strconv.Itoa(int(protocols["http-secure"].Port))),
 		)}
 	}
 
@@ -244,7 +631,7 @@ func templateStatefulSet(r *clickhouseReconciler, id v1.ClickHouseReplicaID) (*a
 		Ports: []corev1.ContainerPort{
 			{
 				Protocol:      corev1.ProtocolTCP,
-				Name:          "prometheus",
+				Name:          controller.PrometheusPortName,
 				ContainerPort: PortPrometheusScrape,
 			},
 			{
@@ -265,16 +652,40 @@ func templateStatefulSet(r *clickhouseReconciler, id v1.ClickHouseReplicaID) (*a
 		},
 	}
 
+	drainTimeout := r.Cluster.ShutdownDrainTimeout()
+	if drainTimeout > 0 {
+		container.Lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"/bin/bash", "-c", preStopScript, "pre-stop", strconv.Itoa(int(drainTimeout.Seconds()))},
+				},
+			},
+		}
+	}
+
 	for _, secret := range secretsToEnvMapping {
+		secretRef := &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: r.Cluster.SecretName(),
+			},
+			Key: secret.Key,
+		}
+
+		// External coordination service may require the identity provisioned by its administrators.
+		if external := r.Cluster.Spec.Coordination.External; secret.Key == SecretKeyKeeperIdentity &&
+			external != nil && external.AuthSecret != nil {
+			secretRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: external.AuthSecret.Name,
+				},
+				Key: external.AuthSecret.Key,
+			}
+		}
+
 		container.Env = append(container.Env, corev1.EnvVar{
 			Name: secret.Env,
 			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: r.Cluster.SecretName(),
-					},
-					Key: secret.Key,
-				},
+				SecretKeyRef: secretRef,
 			},
 		})
 	}
@@ -356,6 +767,10 @@ func templateStatefulSet(r *clickhouseReconciler, id v1.ClickHouseReplicaID) (*a
 		},
 	}
 
+	if serverPodSpec.TerminationGracePeriodSeconds == nil && drainTimeout > 0 {
+		serverPodSpec.TerminationGracePeriodSeconds = ptr.To(int64((drainTimeout + ShutdownGracePeriodMargin).Seconds()))
+	}
+
 	if r.Cluster.Spec.PodTemplate.TopologyZoneKey != nil && *r.Cluster.Spec.PodTemplate.TopologyZoneKey != "" {
 		if serverPodSpec.Affinity == nil {
 			serverPodSpec.Affinity = &corev1.Affinity{}
@@ -380,18 +795,21 @@ func templateStatefulSet(r *clickhouseReconciler, id v1.ClickHouseReplicaID) (*a
 				},
 			},
 		})
-		serverPodSpec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(serverPodSpec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
-			PodAffinityTerm: corev1.PodAffinityTerm{
-				TopologyKey: *r.Cluster.Spec.PodTemplate.TopologyZoneKey,
-				LabelSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{
-						controllerutil.LabelAppKey:  r.keeper.SpecificName(),
-						controllerutil.LabelRoleKey: controllerutil.LabelKeeperValue,
+		// Replicas are preferably co-located with the managed Keeper cluster replicas.
+		if r.Cluster.Spec.Coordination.External == nil {
+			serverPodSpec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(serverPodSpec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					TopologyKey: *r.Cluster.Spec.PodTemplate.TopologyZoneKey,
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							controllerutil.LabelAppKey:  r.keeper.SpecificName(),
+							controllerutil.LabelRoleKey: controllerutil.LabelKeeperValue,
+						},
 					},
 				},
-			},
-			Weight: 1,
-		})
+				Weight: 1,
+			})
+		}
 		serverPodSpec.TopologySpreadConstraints = append(serverPodSpec.TopologySpreadConstraints, corev1.TopologySpreadConstraint{
 			MaxSkew:           1,
 			TopologyKey:       *r.Cluster.Spec.PodTemplate.TopologyZoneKey,
@@ -505,6 +923,9 @@ func generateConfigForSingleReplica(r *clickhouseReconciler, id v1.ClickHouseRep
 	return configFiles, nil
 }
 
+// internalProtocols are used for cluster internal communication and not exposed by client-facing Services.
+var internalProtocols = []string{"interserver", "management", "prometheus"}
+
 type protocol struct {
 	Type        string `yaml:"type"`
 	Port        uint16 `yaml:"port,omitempty"`
@@ -512,6 +933,21 @@ type protocol struct {
 	Description string `yaml:"description,omitempty"`
 }
 
+// clientProtocols maps client protocols from the cluster spec to the port names and protocol configuration.
+var clientProtocols = map[string]struct {
+	Name     string
+	Protocol protocol
+}{
+	"http":       {"http", protocol{Type: "http", Description: "http"}},
+	"tcp":        {"tcp", protocol{Type: "tcp", Description: "native protocol"}},
+	"httpSecure": {"http-secure", protocol{Type: "tls", Impl: "http", Description: "https"}},
+	"tcpSecure":  {"tcp-secure", protocol{Type: "tls", Impl: "tcp", Description: "secure native protocol"}},
+	"tcpProxy":   {"tcp-proxy", protocol{Type: "proxy1", Impl: "tcp", Description: "native protocol with PROXYv1"}},
+	"mysql":      {"mysql", protocol{Type: "mysql", Description: "mysql compatibility protocol"}},
+	"postgresql": {"postgresql", protocol{Type: "postgres", Description: "postgresql compatibility protocol"}},
+	"grpc":       {"grpc", protocol{Type: "grpc", Description: "grpc protocol"}},
+}
+
 func buildProtocols(cr *v1.ClickHouseCluster) map[string]protocol {
 	protocols := map[string]protocol{
 		"interserver": {
@@ -529,6 +965,7 @@ func buildProtocols(cr *v1.ClickHouseCluster) map[string]protocol {
 			Port:        PortManagement,
 			Description: "tcp-management",
 		},
+		// Secure and proxy protocols wrap plain protocols, so they are always declared without ports.
 		"tcp": {
 			Type: "tcp",
 		},
@@ -537,32 +974,11 @@ func buildProtocols(cr *v1.ClickHouseCluster) map[string]protocol {
 		},
 	}
 
-	if !cr.Spec.Settings.TLS.Enabled || !cr.Spec.Settings.TLS.Required {
-		protocols["http"] = protocol{
-			Type:        "http",
-			Port:        PortHTTP,
-			Description: "http",
-		}
-		protocols["tcp"] = protocol{
-			Type:        "tcp",
-			Port:        PortNative,
-			Description: "native protocol",
-		}
-	}
-
-	if cr.Spec.Settings.TLS.Enabled {
-		protocols["tcp-secure"] = protocol{
-			Type:        "tls",
-			Port:        PortNativeSecure,
-			Impl:        "tcp",
-			Description: "secure native protocol",
-		}
-		protocols["http-secure"] = protocol{
-			Type:        "tls",
-			Port:        PortHTTPSecure,
-			Impl:        "http",
-			Description: "https",
-		}
+	for field, port := range cr.Spec.Settings.Protocols.EnabledPorts(cr.Spec.Settings.TLS) {
+		client := clientProtocols[field]
+		proto := client.Protocol
+		proto.Port = uint16(port)
+		protocols[client.Name] = proto
 	}
 
 	return protocols
@@ -0,0 +1,16 @@
+# Drains the ClickHouse replica before the server is stopped.
+# Terminating pod is removed from the Service endpoints, so clients do not send new queries to it.
+# Usage: pre-stop.sh <drain timeout seconds>
+deadline=$((SECONDS + $1))
+
+while [ "$SECONDS" -lt "$deadline" ]; do
+  running=$(clickhouse-client --query "SELECT count() FROM system.processes WHERE is_initial_query AND query_id != queryID()") || break
+  if [ "$running" -eq 0 ]; then
+    break
+  fi
+
+  sleep 1
+done
+
+clickhouse-client --query "SELECT format('SYSTEM FLUSH DISTRIBUTED \`{}\`.\`{}\`;', database, name) FROM system.tables WHERE engine = 'Distributed' FORMAT TSVRaw" \
+  | clickhouse-client --multiquery
@@ -3,11 +3,16 @@ package clickhouse
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
 	"github.com/ClickHouse/clickhouse-operator/internal"
+	"github.com/ClickHouse/clickhouse-operator/internal/controller"
 	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
@@ -192,6 +197,382 @@ var _ = Describe("BuildVolumes", func() {
 	})
 })
 
+var _ = Describe("GracefulShutdown", func() {
+	newReconciler := func(settings v1.ClickHouseSettings, gracePeriod *int64) *clickhouseReconciler {
+		r := &clickhouseReconciler{}
+		r.Cluster = &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Settings: settings,
+				PodTemplate: v1.PodTemplateSpec{
+					TerminationGracePeriodSeconds: gracePeriod,
+				},
+			},
+		}
+
+		return r
+	}
+
+	It("should add preStop hook and extend grace period by default", func() {
+		sts, err := templateStatefulSet(newReconciler(v1.ClickHouseSettings{}, nil), v1.ClickHouseReplicaID{})
+		Expect(err).ToNot(HaveOccurred())
+
+		container := sts.Spec.Template.Spec.Containers[0]
+		Expect(container.Lifecycle).ToNot(BeNil())
+		Expect(container.Lifecycle.PreStop.Exec.Command).To(HaveExactElements(
+			"/bin/bash", "-c", preStopScript, "pre-stop", "60"))
+		Expect(*sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeEquivalentTo(90))
+	})
+
+	It("should keep explicitly set grace period", func() {
+		sts, err := templateStatefulSet(newReconciler(v1.ClickHouseSettings{
+			ShutdownDrainTimeoutSeconds: ptr.To[int32](10),
+		}, ptr.To[int64](15)), v1.ClickHouseReplicaID{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sts.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(ContainElement("10"))
+		Expect(*sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeEquivalentTo(15))
+	})
+
+	It("should not drain if timeout is zero", func() {
+		sts, err := templateStatefulSet(newReconciler(v1.ClickHouseSettings{
+			ShutdownDrainTimeoutSeconds: ptr.To[int32](0),
+		}, nil), v1.ClickHouseReplicaID{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sts.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNil())
+	})
+})
+
+var _ = Describe("ExternalCoordination", func() {
+	It("should take Keeper identity from the external auth Secret", func() {
+		r := &clickhouseReconciler{}
+		r.Cluster = &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Coordination: v1.CoordinationSpec{
+					External: &v1.ExternalKeeperSpec{
+						Nodes:      []string{"zookeeper:2181"},
+						AuthSecret: &v1.SecretKeySelector{Name: "zookeeper-auth", Key: "identity"},
+					},
+				},
+			},
+		}
+
+		sts, err := templateStatefulSet(r, v1.ClickHouseReplicaID{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(sts.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: EnvKeeperIdentity,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "zookeeper-auth"},
+					Key:                  "identity",
+				},
+			},
+		}))
+	})
+})
+
+var _ = Describe("ClientServices", func() {
+	It("should expose only client protocols of ready replicas", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+
+		service := templateClusterService(cr)
+		Expect(service.Name).To(Equal(cr.ClusterServiceName()))
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(service.Spec.PublishNotReadyAddresses).To(BeFalse())
+		Expect(service.Spec.ExternalTrafficPolicy).To(BeEmpty())
+
+		portNames := make([]string, 0, len(service.Spec.Ports))
+		for _, port := range service.Spec.Ports {
+			portNames = append(portNames, port.Name)
+		}
+
+		Expect(portNames).To(ConsistOf("http", "tcp"))
+	})
+
+	It("should select single shard replicas and apply service template", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Services: v1.ClickHouseServicesSpec{
+					Shard: v1.ServiceTemplateSpec{
+						Enabled:               ptr.To(true),
+						Type:                  corev1.ServiceTypeLoadBalancer,
+						ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+						Annotations:           map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+					},
+				},
+			},
+		}
+
+		service := templateShardService(cr, 1)
+		Expect(service.Name).To(Equal(cr.ShardServiceName(1)))
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(service.Spec.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyLocal))
+		Expect(service.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-internal", "true"))
+		Expect(service.Spec.Selector).To(HaveKeyWithValue(controllerutil.LabelClickHouseShardID, "1"))
+		Expect(service.Labels).To(HaveKeyWithValue(controllerutil.LabelClickHouseShardID, "1"))
+	})
+})
+
+var _ = Describe("Exposure", func() {
+	It("should route Ingress to plain HTTP port of the cluster Service", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Services: v1.ClickHouseServicesSpec{
+					Ingress: v1.IngressSpec{
+						Enabled:   true,
+						Host:      "clickhouse.example.com",
+						TLSSecret: &corev1.LocalObjectReference{Name: "ingress-tls"},
+					},
+				},
+			},
+		}
+
+		ingress := templateIngress(cr)
+		Expect(ingress.Spec.Rules).To(HaveLen(1))
+		Expect(ingress.Spec.Rules[0].Host).To(Equal("clickhouse.example.com"))
+		backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+		Expect(backend.Name).To(Equal(cr.ClusterServiceName()))
+		Expect(backend.Port.Name).To(Equal("http"))
+		Expect(ingress.Annotations).NotTo(HaveKey(IngressBackendProtocolAnnotation))
+		Expect(ingress.Spec.TLS).To(ConsistOf(networkingv1.IngressTLS{
+			Hosts:      []string{"clickhouse.example.com"},
+			SecretName: "ingress-tls",
+		}))
+	})
+
+	It("should route to HTTPS port if TLS is required", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Settings: v1.ClickHouseSettings{
+					TLS: v1.ClusterTLSSpec{
+						Enabled:          true,
+						Required:         true,
+						ServerCertSecret: &corev1.LocalObjectReference{Name: "server-cert"},
+					},
+				},
+				Services: v1.ClickHouseServicesSpec{
+					Gateway: v1.GatewayRouteSpec{
+						Enabled:    true,
+						ParentRefs: []v1.GatewayParentReference{{Name: "gateway", Namespace: "infra"}},
+						Hostnames:  []string{"clickhouse.example.com"},
+					},
+				},
+			},
+		}
+
+		ingress := templateIngress(cr)
+		Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Name).To(Equal("http-secure"))
+		Expect(ingress.Annotations).To(HaveKeyWithValue(IngressBackendProtocolAnnotation, "HTTPS"))
+
+		httpRoute := templateHTTPRoute(cr)
+		Expect(httpRoute.Spec.ParentRefs).To(HaveLen(1))
+		Expect(httpRoute.Spec.ParentRefs[0].Name).To(BeEquivalentTo("gateway"))
+		Expect(httpRoute.Spec.ParentRefs[0].Namespace).To(HaveValue(BeEquivalentTo("infra")))
+		Expect(httpRoute.Spec.ParentRefs[0].SectionName).To(BeNil())
+		Expect(httpRoute.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("clickhouse.example.com")))
+		Expect(httpRoute.Spec.Rules[0].BackendRefs[0].Port).To(HaveValue(BeEquivalentTo(PortHTTPSecure)))
+
+		policy := templateBackendTLSPolicy(cr)
+		Expect(policy.Spec.TargetRefs).To(HaveLen(1))
+		Expect(policy.Spec.TargetRefs[0].Name).To(BeEquivalentTo(cr.ClusterServiceName()))
+		Expect(policy.Spec.TargetRefs[0].SectionName).To(HaveValue(BeEquivalentTo("http-secure")))
+		Expect(policy.Spec.Validation.CACertificateRefs).To(ConsistOf(gatewayv1.LocalObjectReference{
+			Kind: "Secret",
+			Name: "server-cert",
+		}))
+		Expect(policy.Spec.Validation.WellKnownCACertificates).To(BeNil())
+		Expect(policy.Spec.Validation.Hostname).To(BeEquivalentTo(cr.ClusterServiceName() + ".default.svc"))
+
+		By("verifying the backend with the system CA certificates without the cluster CA secrets")
+		cr.Spec.Settings.TLS.ServerCertSecret = nil
+		policy = templateBackendTLSPolicy(cr)
+		Expect(policy.Spec.Validation.CACertificateRefs).To(BeEmpty())
+		Expect(policy.Spec.Validation.WellKnownCACertificates).To(HaveValue(Equal(gatewayv1.WellKnownCACertificatesSystem)))
+
+		tlsRoute := templateTLSRoute(cr)
+		Expect(tlsRoute.Spec.Rules[0].BackendRefs[0].Name).To(BeEquivalentTo(cr.ClusterServiceName()))
+		Expect(tlsRoute.Spec.Rules[0].BackendRefs[0].Port).To(HaveValue(BeEquivalentTo(PortHTTPSecure)))
+	})
+})
+
+var _ = Describe("NetworkPolicy", func() {
+	cr := &v1.ClickHouseCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test-namespace",
+		},
+	}
+
+	It("should restrict interserver and management ports to cluster replicas and operator", func() {
+		policy := templateNetworkPolicy(cr, "operator")
+		Expect(policy.Spec.Ingress).To(HaveLen(2))
+
+		internalRule := policy.Spec.Ingress[0]
+		Expect(internalRule.Ports).To(HaveLen(2))
+		Expect(internalRule.Ports[0].Port.IntVal).To(BeEquivalentTo(PortInterserver))
+		Expect(internalRule.Ports[1].Port.IntVal).To(BeEquivalentTo(PortManagement))
+		Expect(internalRule.From).To(HaveLen(2))
+		Expect(internalRule.From[0].PodSelector.MatchLabels).To(HaveKeyWithValue(controllerutil.LabelAppKey, cr.SpecificName()))
+		Expect(internalRule.From[1].NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "operator"))
+
+		openRule := policy.Spec.Ingress[1]
+		Expect(openRule.From).To(BeEmpty())
+
+		openPorts := make([]int32, 0, len(openRule.Ports))
+		for _, port := range openRule.Ports {
+			openPorts = append(openPorts, port.Port.IntVal)
+		}
+
+		Expect(openPorts).To(ConsistOf(int32(PortHTTP), int32(PortNative), int32(PortPrometheusScrape)))
+	})
+
+	It("should allow replicas to access Keeper client ports", func() {
+		keeperCluster := &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "keeper",
+				Namespace: "test-namespace",
+			},
+		}
+
+		policy := templateKeeperNetworkPolicy(cr, keeperCluster)
+		Expect(policy.Namespace).To(Equal(cr.Namespace))
+		Expect(policy.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(controllerutil.LabelAppKey, keeperCluster.SpecificName()))
+		Expect(policy.Spec.Ingress).To(HaveLen(1))
+		Expect(policy.Spec.Ingress[0].From).To(ConsistOf(controller.AppPeer(cr.SpecificName())))
+	})
+})
+
+var _ = Describe("PodMonitor", func() {
+	It("should scrape prometheus port with shard and replica labels", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Monitoring: v1.ClickHouseMonitoringSpec{
+					PodMonitor: v1.PodMonitorSpec{
+						Enabled:  true,
+						Labels:   map[string]string{"release": "prometheus"},
+						Interval: "30s",
+					},
+				},
+			},
+		}
+
+		podMonitor := templatePodMonitor(cr)
+		Expect(podMonitor.Labels).To(HaveKeyWithValue("release", "prometheus"))
+		Expect(podMonitor.Spec.Selector.MatchLabels).To(HaveKeyWithValue(controllerutil.LabelAppKey, cr.SpecificName()))
+		Expect(podMonitor.Spec.PodMetricsEndpoints).To(HaveLen(1))
+
+		endpoint := podMonitor.Spec.PodMetricsEndpoints[0]
+		Expect(*endpoint.Port).To(Equal(controller.PrometheusPortName))
+		Expect(*endpoint.Scheme).To(Equal(monitoringv1.SchemeHTTP))
+		Expect(endpoint.Interval).To(BeEquivalentTo("30s"))
+		Expect(endpoint.TLSConfig).To(BeNil())
+
+		targetLabels := map[string]monitoringv1.LabelName{}
+		for _, relabeling := range endpoint.RelabelConfigs {
+			Expect(relabeling.SourceLabels).To(HaveLen(1))
+			targetLabels[relabeling.TargetLabel] = relabeling.SourceLabels[0]
+		}
+
+		Expect(targetLabels).To(HaveKeyWithValue("shard", monitoringv1.LabelName("__meta_kubernetes_pod_label_clickhouse_com_shard_id")))
+		Expect(targetLabels).To(HaveKeyWithValue("replica", monitoringv1.LabelName("__meta_kubernetes_pod_label_clickhouse_com_replica_id")))
+	})
+
+	It("should scrape over HTTPS if TLS is configured", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Monitoring: v1.ClickHouseMonitoringSpec{
+					PodMonitor: v1.PodMonitorSpec{
+						Enabled: true,
+						TLS: &v1.MonitoringTLSSpec{
+							CABundle:   &v1.SecretKeySelector{Name: "ca", Key: "ca.crt"},
+							ServerName: "clickhouse.example.com",
+						},
+					},
+				},
+			},
+		}
+
+		endpoint := templatePodMonitor(cr).Spec.PodMetricsEndpoints[0]
+		Expect(*endpoint.Scheme).To(Equal(monitoringv1.SchemeHTTPS))
+		Expect(endpoint.TLSConfig).ToNot(BeNil())
+		Expect(*endpoint.TLSConfig.ServerName).To(Equal("clickhouse.example.com"))
+		Expect(endpoint.TLSConfig.CA.Secret.Name).To(Equal("ca"))
+		Expect(endpoint.TLSConfig.CA.Secret.Key).To(Equal("ca.crt"))
+	})
+})
+
+var _ = Describe("PrometheusRule", func() {
+	It("should render alerts with thresholds from the spec", func() {
+		cr := &v1.ClickHouseCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: v1.ClickHouseClusterSpec{
+				Monitoring: v1.ClickHouseMonitoringSpec{
+					Alerts: v1.ClickHouseAlertsSpec{
+						AlertsSpec: v1.AlertsSpec{
+							Enabled:     true,
+							Labels:      map[string]string{"release": "prometheus"},
+							AlertLabels: map[string]string{"team": "db", "severity": "none"},
+							For:         "10m",
+						},
+						ReplicationDelaySeconds: 120,
+						PartsPerPartition:       500,
+					},
+				},
+			},
+		}
+
+		rule := templatePrometheusRule(cr)
+		Expect(rule.Labels).To(HaveKeyWithValue("release", "prometheus"))
+		Expect(rule.Spec.Groups).To(HaveLen(1))
+
+		alerts := map[string]monitoringv1.Rule{}
+		for _, alert := range rule.Spec.Groups[0].Rules {
+			alerts[alert.Alert] = alert
+			Expect(alert.Expr.StrVal).To(ContainSubstring(`namespace="default", pod=~"test-clickhouse-[0-9]+-[0-9]+-0"`))
+			Expect(*alert.For).To(BeEquivalentTo("10m"))
+			Expect(alert.Labels).To(HaveKeyWithValue("team", "db"))
+			Expect(alert.Labels).ToNot(HaveKeyWithValue("severity", "none"))
+		}
+
+		Expect(alerts).To(HaveKey("ClickHouseReadonlyReplica"))
+		Expect(alerts["ClickHouseReadonlyReplica"].Labels).To(HaveKeyWithValue("severity", controller.AlertSeverityCritical))
+		Expect(alerts).To(HaveKey("ClickHouseReplicationLag"))
+		Expect(alerts["ClickHouseReplicationLag"].Expr.StrVal).To(HaveSuffix("> 120"))
+		Expect(alerts).To(HaveKey("ClickHouseTooManyParts"))
+		Expect(alerts["ClickHouseTooManyParts"].Expr.StrVal).To(HaveSuffix("> 500"))
+	})
+})
+
 func checkVolumeMounts(volumes []corev1.Volume, mounts []corev1.VolumeMount) {
 	volumeMap := map[string]struct{}{
 		internal.PersistentVolumeName: {},
@@ -71,6 +71,7 @@ type OpenSSLParams struct {
 	CertificateFile     string `yaml:"certificateFile"`
 	PrivateKeyFile      string `yaml:"privateKeyFile"`
 	CAConfig            string `yaml:"caConfig"`
+	LoadDefaultCAFile   bool   `yaml:"loadDefaultCAFile,omitempty"`
 	VerificationMode    string `yaml:"verificationMode"`
 	DisableProtocols    string `yaml:"disableProtocols"`
 	PreferServerCiphers bool   `yaml:"preferServerCiphers"`
@@ -0,0 +1,68 @@
+package keeper
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// bootstrapReplicaID is the replica importing the ZooKeeper data. Other replicas receive the data from its snapshot.
+const bootstrapReplicaID v1.KeeperReplicaID = 0
+
+// Interval between checks of the imported data that did not match the expected znode count.
+const bootstrapCheckInterval = time.Minute
+
+// reconcileBootstrap verifies the ZooKeeper data imported into the first replica of a new cluster.
+// Other replicas are not created until the import is verified, so they can't form a quorum with empty data.
+func (r *keeperReconciler) reconcileBootstrap(_ context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	r.BootstrapPending = false
+
+	if r.Cluster.Spec.Bootstrap == nil || r.Cluster.Status.Bootstrapped {
+		return nil, nil
+	}
+
+	// New cluster, only the bootstrap replica is created.
+	if len(r.ReplicaState) == 0 {
+		r.BootstrapPending = true
+		return nil, nil
+	}
+
+	replica, ok := r.ReplicaState[bootstrapReplicaID]
+	if !ok || len(r.ReplicaState) > 1 {
+		log.Info("cluster already has replicas, ignoring bootstrap", "replicas", len(r.ReplicaState))
+		return nil, nil
+	}
+
+	r.BootstrapPending = true
+	hostname := r.Cluster.HostnameByID(bootstrapReplicaID)
+
+	if !replica.Ready(r) || replica.Status.ServerState == "" {
+		log.Info("waiting for the bootstrap replica to import the ZooKeeper data", "replica_id", bootstrapReplicaID)
+		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+
+	znodeCount := replica.Status.ZnodeCount
+	if expected := r.Cluster.Spec.Bootstrap.ExpectedZnodeCount; expected != nil && znodeCount < *expected {
+		log.Warn("imported data has less znodes than expected", "znode_count", znodeCount, "expected", *expected)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonBootstrapVerificationFailed,
+			v1.EventActionBootstrapping, "Replica %q has %d znodes after the ZooKeeper data import, expected at least %d",
+			hostname, znodeCount, *expected)
+
+		return &ctrl.Result{RequeueAfter: bootstrapCheckInterval}, nil
+	}
+
+	log.Info("ZooKeeper data import is verified", "znode_count", znodeCount)
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonBootstrapCompleted,
+		v1.EventActionBootstrapping, "ZooKeeper data is imported into replica %q: %d znodes", hostname, znodeCount)
+
+	r.Cluster.Status.Bootstrapped = true
+	r.BootstrapPending = false
+
+	return nil, nil
+}
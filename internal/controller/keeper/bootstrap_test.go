@@ -0,0 +1,86 @@
+package keeper
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("Bootstrap", func() {
+	var (
+		ctx context.Context
+		log util.Logger
+		rec *keeperReconciler
+	)
+
+	readyReplica := func(znodeCount int64) replicaState {
+		return replicaState{
+			StatefulSet: &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 1}},
+			Status:      serverStatus{ServerState: ModeStandalone, ZnodeCount: znodeCount},
+		}
+	}
+
+	BeforeEach(func() {
+		var cancelEvents context.CancelFunc
+		ctx = context.Background()
+		log, rec, cancelEvents = setupReconciler()
+		DeferCleanup(cancelEvents)
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.Cluster.Spec.Bootstrap = &v1.KeeperBootstrapSpec{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "zookeeper-data"},
+			ExpectedZnodeCount:    ptr.To[int64](100),
+		}
+	})
+
+	It("should create only the bootstrap replica for the new cluster", func() {
+		_, err := rec.reconcileBootstrap(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.BootstrapPending).To(BeTrue())
+
+		_, err = rec.reconcileQuorumMembership(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.ReplicaState).To(HaveLen(1))
+		Expect(rec.ReplicaState).To(HaveKey(bootstrapReplicaID))
+	})
+
+	It("should wait until imported data matches the expected znode count", func() {
+		rec.SetReplica(bootstrapReplicaID, replicaState{})
+
+		result, err := rec.reconcileBootstrap(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(rec.BootstrapPending).To(BeTrue())
+
+		rec.SetReplica(bootstrapReplicaID, readyReplica(50))
+		result, err = rec.reconcileBootstrap(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(bootstrapCheckInterval))
+		Expect(rec.BootstrapPending).To(BeTrue())
+		Expect(rec.Cluster.Status.Bootstrapped).To(BeFalse())
+
+		rec.SetReplica(bootstrapReplicaID, readyReplica(105))
+		result, err = rec.reconcileBootstrap(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+		Expect(rec.BootstrapPending).To(BeFalse())
+		Expect(rec.Cluster.Status.Bootstrapped).To(BeTrue())
+	})
+
+	It("should ignore bootstrap for the existing cluster", func() {
+		rec.SetReplica(0, readyReplica(0))
+		rec.SetReplica(1, readyReplica(0))
+
+		_, err := rec.reconcileBootstrap(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.BootstrapPending).To(BeFalse())
+		Expect(rec.Cluster.Status.Bootstrapped).To(BeFalse())
+	})
+})
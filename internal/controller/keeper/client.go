@@ -0,0 +1,95 @@
+package keeper
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+const (
+	clientSessionTimeout = 10 * time.Second
+	clientConnectTimeout = 10 * time.Second
+)
+
+type zkLogger struct {
+	log controllerutil.Logger
+}
+
+func (l zkLogger) Printf(format string, args ...any) {
+	l.log.Debug(fmt.Sprintf(format, args...))
+}
+
+// Connect opens a Keeper client session to the given KeeperCluster replicas.
+// Connects to all replicas if no hostnames provided. The caller must close the returned connection.
+func Connect(ctx context.Context, log controllerutil.Logger, cluster *v1.KeeperCluster, hostnames ...string) (*zk.Conn, error) {
+	tlsRequired := cluster.Spec.Settings.TLS.Required
+
+	port := PortNative
+	if tlsRequired {
+		port = PortNativeSecure
+	}
+
+	if len(hostnames) == 0 {
+		hostnames = cluster.Hostnames()
+	}
+
+	servers := make([]string, 0, len(hostnames))
+	for _, host := range hostnames {
+		servers = append(servers, net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+
+	dialer := func(network, address string, timeout time.Duration) (net.Conn, error) {
+		if !tlsRequired {
+			return net.DialTimeout(network, address, timeout)
+		}
+
+		return (&tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: timeout},
+			Config: &tls.Config{
+				//nolint:gosec // User managed certificate may be outdated or issued for other hostnames.
+				InsecureSkipVerify: true,
+			},
+		}).DialContext(ctx, network, address)
+	}
+
+	conn, events, err := zk.Connect(servers, clientSessionTimeout, zk.WithLogger(zkLogger{log}), zk.WithDialer(dialer))
+	if err != nil {
+		return nil, fmt.Errorf("connect to keeper: %w", err)
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, clientConnectTimeout)
+	defer cancel()
+
+	if err := waitSession(connectCtx, events); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func waitSession(ctx context.Context, events <-chan zk.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for keeper session: %w", ctx.Err())
+		case event, ok := <-events:
+			if !ok {
+				return errors.New("keeper connection closed")
+			}
+
+			if event.State == zk.StateHasSession {
+				return nil
+			}
+		}
+	}
+}
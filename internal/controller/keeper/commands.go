@@ -15,20 +15,49 @@ import (
 
 const (
 	FLWCommand = "mntr"
+	// FLWRequestLeadership asks the receiving follower to become the leader.
+	FLWRequestLeadership = "rqld"
+	// LeadershipRequestSent is the response of the leadership request when it is forwarded to the leader.
+	LeadershipRequestSent = "Sent leadership request to leader."
+	// FLWDirectories reports the total size of snapshot and log files.
+	FLWDirectories = "dirs"
+	// FLWCreateSnapshot schedules a snapshot creation.
+	FLWCreateSnapshot = "csnp"
+	// FLWLogInfo reports the Raft log indexes of the replica.
+	FLWLogInfo = "lgif"
+	// FLWRecovery switches the replica to the quorum recovery mode.
+	FLWRecovery = "rcvr"
 
 	ModeLeader     = "leader"
 	ModeFollower   = "follower"
+	ModeObserver   = "observer"
 	ModeStandalone = "standalone"
 )
 
 var (
-	clusterModes = []string{ModeLeader, ModeFollower}
+	clusterModes = []string{ModeLeader, ModeFollower, ModeObserver}
 )
 
 // serverStatus holds parsed fields of the "mntr" command response.
 type serverStatus struct {
 	ServerState string
 	Followers   int
+	// ApproximateDataSize is the approximate size of the Keeper in-memory data in bytes.
+	ApproximateDataSize int64
+	// ZnodeCount is the number of znodes stored by the replica.
+	ZnodeCount int64
+}
+
+// storageStatus holds parsed fields of the "dirs" command response.
+type storageStatus struct {
+	Known           bool
+	SnapshotDirSize int64
+	LogDirSize      int64
+}
+
+// Used returns the data volume space used by snapshots and logs.
+func (s storageStatus) Used() int64 {
+	return s.SnapshotDirSize + s.LogDirSize
 }
 
 type dialer interface {
@@ -58,29 +87,39 @@ func getConnection(ctx context.Context, hostname string, tlsRequired bool) (net.
 	return conn, nil
 }
 
-func queryKeeper(ctx context.Context, log controllerutil.Logger, conn net.Conn) (serverStatus, error) {
-	log.Debug("querying keeper pod: " + conn.RemoteAddr().String())
+// sendCommand sends four letter word command to the Keeper and returns the raw response.
+func sendCommand(ctx context.Context, log controllerutil.Logger, conn net.Conn, command string) ([]byte, error) {
+	log.Debug(fmt.Sprintf("sending %q to keeper pod: %s", command, conn.RemoteAddr().String()))
 
 	if dl, ok := ctx.Deadline(); ok {
 		if err := conn.SetDeadline(dl); err != nil {
-			return serverStatus{}, fmt.Errorf("set deadline: %w", err)
+			return nil, fmt.Errorf("set deadline: %w", err)
 		}
 	}
 
-	n, err := io.WriteString(conn, FLWCommand)
+	n, err := io.WriteString(conn, command)
 	if err != nil {
-		return serverStatus{}, fmt.Errorf("write command: %w", err)
+		return nil, fmt.Errorf("write command: %w", err)
 	}
 
-	if n != len(FLWCommand) {
-		return serverStatus{}, fmt.Errorf("can't write the whole string to socket expected: %d; actual: %d", len(FLWCommand), n)
+	if n != len(command) {
+		return nil, fmt.Errorf("can't write the whole string to socket expected: %d; actual: %d", len(command), n)
 	}
 
 	reader := bufio.NewReader(conn)
 
 	data, err := io.ReadAll(reader)
 	if err != nil {
-		return serverStatus{}, fmt.Errorf("got error while reading from socket: %w", err)
+		return nil, fmt.Errorf("got error while reading from socket: %w", err)
+	}
+
+	return data, nil
+}
+
+func queryKeeper(ctx context.Context, log controllerutil.Logger, conn net.Conn) (serverStatus, error) {
+	data, err := sendCommand(ctx, log, conn, FLWCommand)
+	if err != nil {
+		return serverStatus{}, err
 	}
 
 	statMap := map[string]string{}
@@ -104,6 +143,20 @@ func queryKeeper(ctx context.Context, log controllerutil.Logger, conn net.Conn)
 		return serverStatus{}, fmt.Errorf("response missing required field 'Mode': %q", string(data))
 	}
 
+	if dataSize, ok := statMap["zk_approximate_data_size"]; ok {
+		result.ApproximateDataSize, err = strconv.ParseInt(dataSize, 10, 64)
+		if err != nil {
+			return serverStatus{}, fmt.Errorf("failed to parse field 'zk_approximate_data_size': %w", err)
+		}
+	}
+
+	if znodeCount, ok := statMap["zk_znode_count"]; ok {
+		result.ZnodeCount, err = strconv.ParseInt(znodeCount, 10, 64)
+		if err != nil {
+			return serverStatus{}, fmt.Errorf("failed to parse field 'zk_znode_count': %w", err)
+		}
+	}
+
 	if result.ServerState == ModeLeader {
 		if followers, ok := statMap["zk_followers"]; ok {
 			result.Followers, err = strconv.Atoi(followers)
@@ -139,3 +192,178 @@ func getServerStatus(ctx context.Context, log controllerutil.Logger, hostname st
 
 	return status
 }
+
+// requestLeadership asks the follower to take over the quorum leadership.
+func requestLeadership(ctx context.Context, log controllerutil.Logger, hostname string, tlsRequired bool) error {
+	conn, err := getConnection(ctx, hostname, tlsRequired)
+	if err != nil {
+		return err
+	}
+	defer func(conn net.Conn) {
+		if err := conn.Close(); err != nil {
+			log.Warn("failed to close connection", "error", err)
+		}
+	}(conn)
+
+	data, err := sendCommand(ctx, log, conn, FLWRequestLeadership)
+	if err != nil {
+		return err
+	}
+
+	if response := strings.TrimSpace(string(data)); response != LeadershipRequestSent {
+		return fmt.Errorf("leadership request rejected: %q", response)
+	}
+
+	return nil
+}
+
+// parseStorageStatus parses the "dirs" command response.
+func parseStorageStatus(data []byte) (storageStatus, error) {
+	result := storageStatus{}
+
+	for line := range strings.Lines(string(data)) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+
+		var field *int64
+
+		switch strings.TrimSpace(key) {
+		case "snapshot_dir_size":
+			field = &result.SnapshotDirSize
+		case "log_dir_size":
+			field = &result.LogDirSize
+		default:
+			continue
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return storageStatus{}, fmt.Errorf("failed to parse field %q: %w", key, err)
+		}
+
+		*field = size
+		result.Known = true
+	}
+
+	if !result.Known {
+		return storageStatus{}, fmt.Errorf("response missing directory sizes: %q", string(data))
+	}
+
+	return result, nil
+}
+
+func getStorageStatus(ctx context.Context, log controllerutil.Logger, hostname string, tlsRequired bool) storageStatus {
+	conn, err := getConnection(ctx, hostname, tlsRequired)
+	if err != nil {
+		log.Info("failed to get keeper connection", "error", err)
+		return storageStatus{}
+	}
+	defer func(conn net.Conn) {
+		if err := conn.Close(); err != nil {
+			log.Warn("failed to close connection", "error", err)
+		}
+	}(conn)
+
+	data, err := sendCommand(ctx, log, conn, FLWDirectories)
+	if err != nil {
+		log.Info("failed to query keeper storage", "error", err)
+		return storageStatus{}
+	}
+
+	status, err := parseStorageStatus(data)
+	if err != nil {
+		log.Info("failed to parse keeper storage", "error", err)
+		return storageStatus{}
+	}
+
+	return status
+}
+
+// createSnapshot schedules a snapshot creation, allowing the Keeper to remove Raft logs covered by it.
+func createSnapshot(ctx context.Context, log controllerutil.Logger, hostname string, tlsRequired bool) error {
+	conn, err := getConnection(ctx, hostname, tlsRequired)
+	if err != nil {
+		return err
+	}
+	defer func(conn net.Conn) {
+		if err := conn.Close(); err != nil {
+			log.Warn("failed to close connection", "error", err)
+		}
+	}(conn)
+
+	data, err := sendCommand(ctx, log, conn, FLWCreateSnapshot)
+	if err != nil {
+		return err
+	}
+
+	if response := strings.TrimSpace(string(data)); !strings.HasPrefix(response, "Snapshot creation scheduled") {
+		return fmt.Errorf("snapshot creation rejected: %q", response)
+	}
+
+	return nil
+}
+
+// parseLastLogIndex returns the last Raft log index from the "lgif" command response.
+func parseLastLogIndex(data []byte) (uint64, error) {
+	for line := range strings.Lines(string(data)) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || key != "last_log_idx" {
+			continue
+		}
+
+		index, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse field %q: %w", key, err)
+		}
+
+		return index, nil
+	}
+
+	return 0, fmt.Errorf("response missing required field 'last_log_idx': %q", string(data))
+}
+
+// getLastLogIndex returns the last Raft log index stored by the replica.
+func getLastLogIndex(ctx context.Context, log controllerutil.Logger, hostname string, tlsRequired bool) (uint64, error) {
+	conn, err := getConnection(ctx, hostname, tlsRequired)
+	if err != nil {
+		return 0, err
+	}
+	defer func(conn net.Conn) {
+		if err := conn.Close(); err != nil {
+			log.Warn("failed to close connection", "error", err)
+		}
+	}(conn)
+
+	data, err := sendCommand(ctx, log, conn, FLWLogInfo)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseLastLogIndex(data)
+}
+
+// forceRecovery switches the replica to the recovery mode, it forms a new quorum from the current quorum configuration.
+func forceRecovery(ctx context.Context, log controllerutil.Logger, hostname string, tlsRequired bool) error {
+	conn, err := getConnection(ctx, hostname, tlsRequired)
+	if err != nil {
+		return err
+	}
+	defer func(conn net.Conn) {
+		if err := conn.Close(); err != nil {
+			log.Warn("failed to close connection", "error", err)
+		}
+	}(conn)
+
+	data, err := sendCommand(ctx, log, conn, FLWRecovery)
+	if err != nil {
+		return err
+	}
+
+	if response := strings.TrimSpace(string(data)); response != "ok" {
+		return fmt.Errorf("recovery request rejected: %q", response)
+	}
+
+	return nil
+}
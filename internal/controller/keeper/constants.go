@@ -29,6 +29,16 @@ const (
 	StorageLogPath      = internal.KeeperDataPath + "/coordination/log/"
 	StorageSnapshotPath = internal.KeeperDataPath + "/coordination/snapshots/"
 
+	BootstrapSourcePath   = "/zookeeper-data/"
+	BootstrapDownloadPath = internal.KeeperDataPath + "/zookeeper-bootstrap/"
+	// BootstrapConvertPath keeps the snapshot until the conversion completes, partial output never reaches Keeper.
+	BootstrapConvertPath = internal.KeeperDataPath + "/zookeeper-bootstrap-snapshots/"
+	// BootstrapMarkerPath is created once the converted snapshot is in place, the import is skipped afterwards.
+	BootstrapMarkerPath = internal.KeeperDataPath + "/.zookeeper-bootstrap-completed"
+
+	BootstrapConvertContainerName  = "bootstrap-convert"
+	BootstrapDownloadContainerName = "bootstrap-download"
+
 	ContainerName          = "clickhouse-keeper"
 	DefaultRevisionHistory = 10
 )
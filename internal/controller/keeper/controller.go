@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -30,7 +32,15 @@ type ClusterController struct {
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
 	Logger   controllerutil.Logger
-	Webhook  webhookv1.KeeperClusterWebhook
+	// Optional APIs available in the Kubernetes cluster.
+	Capabilities chctrl.Capabilities
+	// Namespace of the operator, allowed to access cluster internal ports by generated NetworkPolicies.
+	OperatorNamespace string
+	// Uncached reader for the objects the controller does not watch, e.g. Nodes.
+	APIReader client.Reader
+	// Reads the data volume usage reported by kubelet. Nil if the operator is not allowed to read kubelet stats.
+	VolumeStats chctrl.VolumeStatsReader
+	Webhook     webhookv1.KeeperClusterWebhook
 }
 
 // +kubebuilder:rbac:groups=clickhouse.com,resources=keeperclusters,verbs=get;list;watch;create;update;patch;delete
@@ -38,9 +48,12 @@ type ClusterController struct {
 // +kubebuilder:rbac:groups=clickhouse.com,resources=keeperclusters/finalizers,verbs=update
 
 // +kubebuilder:rbac:groups="",resources=configmaps;services;pods,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors;prometheusrules,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -52,6 +65,8 @@ func (cc *ClusterController) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if err != nil {
 		if errors.IsNotFound(err) {
 			cc.Logger.Info("keeper cluster not found")
+			chctrl.DeleteClusterMetrics(chctrl.MetricsControllerKeeper, req.NamespacedName)
+
 			return ctrl.Result{}, nil
 		}
 
@@ -96,7 +111,10 @@ func (cc *ClusterController) Reconcile(ctx context.Context, req ctrl.Request) (c
 			v1.KeeperReplicaID,
 			replicaState,
 		](cc, cluster),
-		ExtraConfig: map[string]any{},
+		ExtraConfig:       map[string]any{},
+		OperatorNamespace: cc.OperatorNamespace,
+		APIReader:         cc.APIReader,
+		VolumeStats:       cc.VolumeStats,
 	}
 
 	return reconciler.sync(ctx, logger)
@@ -117,28 +135,63 @@ func (cc *ClusterController) GetRecorder() events.EventRecorder {
 	return cc.Recorder
 }
 
+// GetCapabilities returns optional APIs available in the Kubernetes cluster.
+func (cc *ClusterController) GetCapabilities() chctrl.Capabilities {
+	return cc.Capabilities
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func SetupWithManager(mgr ctrl.Manager, log controllerutil.Logger) error {
+// Kubelet volume stats are read through the node proxy only if enableKubeletVolumeStats is set, as it requires
+// the cluster-wide nodes/proxy permission.
+func SetupWithManager(mgr ctrl.Manager, log controllerutil.Logger, operatorNamespace string, enableKubeletVolumeStats bool) error {
 	namedLogger := log.Named("keeper")
 
+	capabilities, err := chctrl.DetectCapabilities(mgr.GetRESTMapper())
+	if err != nil {
+		return fmt.Errorf("detect cluster capabilities: %w", err)
+	}
+
+	var volumeStats chctrl.VolumeStatsReader
+	if enableKubeletVolumeStats {
+		kubeletStats, err := chctrl.NewKubeletVolumeStatsReader(mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("create kubelet volume stats reader: %w", err)
+		}
+
+		volumeStats = kubeletStats
+	}
+
 	keeperController := &ClusterController{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorder("keeper-controller"),
-		Logger:   namedLogger,
-		Webhook:  webhookv1.KeeperClusterWebhook{Log: namedLogger},
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          mgr.GetEventRecorder("keeper-controller"),
+		Logger:            namedLogger,
+		Capabilities:      capabilities,
+		OperatorNamespace: operatorNamespace,
+		APIReader:         mgr.GetAPIReader(),
+		VolumeStats:       volumeStats,
+		Webhook:           webhookv1.KeeperClusterWebhook{Log: namedLogger},
 	}
 
-	err := ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1.KeeperCluster{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Service{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Owns(&corev1.Pod{}).
-		WithEventFilter(predicate.ResourceVersionChangedPredicate{}).
-		Complete(keeperController)
-	if err != nil {
+		WithEventFilter(predicate.ResourceVersionChangedPredicate{})
+
+	if capabilities.PodMonitor {
+		controllerBuilder = controllerBuilder.Owns(&monitoringv1.PodMonitor{})
+	}
+
+	if capabilities.PrometheusRule {
+		controllerBuilder = controllerBuilder.Owns(&monitoringv1.PrometheusRule{})
+	}
+
+	if err := controllerBuilder.Complete(keeperController); err != nil {
 		return fmt.Errorf("setup Keeper controller: %w", err)
 	}
 
@@ -60,10 +60,11 @@ var _ = When("reconciling standalone KeeperCluster resource", Ordered, func() {
 		suite = testutil.SetupEnvironment(v1.AddToScheme)
 		recorder = events.NewFakeRecorder(128)
 		controller = &ClusterController{
-			Client:   suite.Client,
-			Scheme:   scheme.Scheme,
-			Logger:   suite.Log.Named("keeper"),
-			Recorder: recorder,
+			Client:    suite.Client,
+			Scheme:    scheme.Scheme,
+			Logger:    suite.Log.Named("keeper"),
+			Recorder:  recorder,
+			APIReader: suite.Client,
 			Webhook: webhookv1.KeeperClusterWebhook{
 				Log: suite.Log.Named("keeper-webhook"),
 			},
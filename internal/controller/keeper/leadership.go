@@ -0,0 +1,162 @@
+package keeper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+const (
+	// Time to wait for the requested follower to take over the leadership before restarting the leader anyway.
+	leadershipTransferTimeout = time.Minute
+	leadershipRequestTimeout  = 10 * time.Second
+)
+
+// chooseReplicaToUpdate returns the next replica for the rolling update.
+// Followers are updated before the leader, replicas with higher id first.
+func (r *keeperReconciler) chooseReplicaToUpdate(ids []v1.KeeperReplicaID) v1.KeeperReplicaID {
+	return slices.MaxFunc(ids, func(a, b v1.KeeperReplicaID) int {
+		aLeader := r.Replica(a).Status.ServerState == ModeLeader
+		bLeader := r.Replica(b).Status.ServerState == ModeLeader
+
+		if aLeader != bLeader {
+			if aLeader {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Compare(a, b)
+	})
+}
+
+// chooseLeaderSuccessor returns the ready voting follower to take over the leadership from the given replica.
+// Prefers followers in the preferred leader zone, then followers without pending updates.
+func (r *keeperReconciler) chooseLeaderSuccessor(leader v1.KeeperReplicaID) (v1.KeeperReplicaID, bool) {
+	var candidates []v1.KeeperReplicaID
+
+	for id, replica := range r.ReplicaState {
+		if id != leader && replica.Status.ServerState == ModeFollower && r.canLead(id) && replica.Ready(r) {
+			candidates = append(candidates, id)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return -1, false
+	}
+
+	preferredZone := r.Cluster.Spec.LeaderPlacement.PreferredZone
+
+	return slices.MaxFunc(candidates, func(a, b v1.KeeperReplicaID) int {
+		if preferredZone != "" {
+			aPreferred := r.Replica(a).Zone == preferredZone
+			bPreferred := r.Replica(b).Zone == preferredZone
+
+			if aPreferred != bPreferred {
+				if aPreferred {
+					return 1
+				}
+
+				return -1
+			}
+		}
+
+		aUpdated := r.Replica(a).UpdateStage(r) == chctrl.StageUpToDate
+		bUpdated := r.Replica(b).UpdateStage(r) == chctrl.StageUpToDate
+
+		if aUpdated != bUpdated {
+			if aUpdated {
+				return 1
+			}
+
+			return -1
+		}
+
+		return cmp.Compare(a, b)
+	}), true
+}
+
+// handoffLeadership moves the quorum leadership away from the replica before it is restarted.
+// Returns non-nil result if the replica update must be postponed.
+func (r *keeperReconciler) handoffLeadership(ctx context.Context, log ctrlutil.Logger, id v1.KeeperReplicaID) (*ctrl.Result, error) {
+	replica := r.Replica(id)
+	if replica.StatefulSet == nil {
+		return nil, nil
+	}
+
+	if !replica.NeedsRestart(r) {
+		log.Debug("replica update does not restart it, skipping leadership handoff", "replica_id", id)
+		return nil, nil
+	}
+
+	hostname := r.Cluster.HostnameByID(id)
+	requestedAt, requested := replica.StatefulSet.Annotations[ctrlutil.AnnotationLeadershipTransferAt]
+
+	if replica.Status.ServerState != ModeLeader {
+		if requested {
+			r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonLeadershipTransferred,
+				v1.EventActionUpdating, "Replica %q handed off the leadership, updating it", hostname)
+		}
+
+		return nil, nil
+	}
+
+	if requested {
+		if ts, err := time.Parse(time.RFC3339, requestedAt); err == nil && time.Since(ts) < leadershipTransferTimeout {
+			log.Info("waiting for leadership transfer", "replica_id", id, "requested_at", requestedAt)
+			return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+		}
+
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonLeadershipTransferFailed,
+			v1.EventActionUpdating, "Replica %q kept the leadership for %s, restarting the leader",
+			hostname, leadershipTransferTimeout)
+
+		return nil, nil
+	}
+
+	successor, ok := r.chooseLeaderSuccessor(id)
+	if !ok {
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonLeadershipTransferFailed,
+			v1.EventActionUpdating, "No ready follower to take over the leadership from %q, restarting the leader", hostname)
+
+		return nil, nil
+	}
+
+	successorHostname := r.Cluster.HostnameByID(successor)
+
+	requestCtx, cancel := context.WithTimeout(ctx, leadershipRequestTimeout)
+	defer cancel()
+
+	if err := requestLeadership(requestCtx, log, successorHostname, r.Cluster.Spec.Settings.TLS.Required); err != nil {
+		log.Warn("failed to request leadership transfer", "replica_id", id, "successor", successor, "error", err)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonLeadershipTransferFailed,
+			v1.EventActionUpdating, "Failed to transfer the leadership from %q to %q, restarting the leader: %v",
+			hostname, successorHostname, err)
+
+		return nil, nil
+	}
+
+	log.Info("requested leadership transfer", "replica_id", id, "successor", successor)
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonLeadershipTransferRequested,
+		v1.EventActionUpdating, "Requested %q to take over the leadership from %q before the update",
+		successorHostname, hostname)
+
+	ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationLeadershipTransferAt,
+		time.Now().Format(time.RFC3339))
+
+	if err := r.Update(ctx, replica.StatefulSet, v1.EventActionUpdating); err != nil {
+		return nil, fmt.Errorf("mark replica %q leadership transfer: %w", id, err)
+	}
+
+	return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+}
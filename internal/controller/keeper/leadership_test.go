@@ -0,0 +1,127 @@
+package keeper
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("LeadershipHandoff", func() {
+	var (
+		log util.Logger
+		rec *keeperReconciler
+	)
+
+	replica := func(mode string, annotations map[string]string) replicaState {
+		return replicaState{
+			StatefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+			},
+			Status: serverStatus{ServerState: mode},
+		}
+	}
+
+	BeforeEach(func() {
+		var cancelEvents context.CancelFunc
+		log, rec, cancelEvents = setupReconciler()
+		DeferCleanup(cancelEvents)
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+	})
+
+	It("should update followers before the leader", func() {
+		rec.SetReplica(1, replica(ModeFollower, nil))
+		rec.SetReplica(2, replica(ModeFollower, nil))
+		rec.SetReplica(3, replica(ModeLeader, nil))
+
+		Expect(rec.chooseReplicaToUpdate([]v1.KeeperReplicaID{1, 2, 3})).To(Equal(v1.KeeperReplicaID(2)))
+		Expect(rec.chooseReplicaToUpdate([]v1.KeeperReplicaID{1, 3})).To(Equal(v1.KeeperReplicaID(1)))
+		Expect(rec.chooseReplicaToUpdate([]v1.KeeperReplicaID{3})).To(Equal(v1.KeeperReplicaID(3)))
+	})
+
+	It("should choose ready follower as the leader successor", func() {
+		rec.SetReplica(1, replica(ModeLeader, nil))
+		rec.SetReplica(2, replica(ModeFollower, nil))
+		rec.SetReplica(3, replica("", nil))
+
+		successor, ok := rec.chooseLeaderSuccessor(1)
+		Expect(ok).To(BeTrue())
+		Expect(successor).To(Equal(v1.KeeperReplicaID(2)))
+
+		delete(rec.ReplicaState, 2)
+		_, ok = rec.chooseLeaderSuccessor(1)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should prefer successor in the preferred zone and skip zero priority replicas", func() {
+		rec.Cluster.Spec.LeaderPlacement = v1.KeeperLeaderPlacementSpec{
+			PreferredZone: "zone-a",
+			Priorities:    []v1.KeeperReplicaPriority{{ReplicaID: 3, Priority: 0}},
+		}
+
+		leader := replica(ModeLeader, nil)
+		leader.Zone = "zone-b"
+		inZone := replica(ModeFollower, nil)
+		inZone.Zone = "zone-a"
+		rec.SetReplica(1, leader)
+		rec.SetReplica(2, inZone)
+		rec.SetReplica(3, replica(ModeFollower, nil))
+
+		successor, ok := rec.chooseLeaderSuccessor(1)
+		Expect(ok).To(BeTrue())
+		Expect(successor).To(Equal(v1.KeeperReplicaID(2)))
+
+		delete(rec.ReplicaState, 2)
+		_, ok = rec.chooseLeaderSuccessor(1)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should not postpone follower update", func(ctx context.Context) {
+		rec.SetReplica(1, replica(ModeFollower, nil))
+		rec.SetReplica(2, replica(ModeLeader, nil))
+
+		result, err := rec.handoffLeadership(ctx, log, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(BeNil())
+	})
+
+	It("should wait for requested leadership transfer", func(ctx context.Context) {
+		rec.SetReplica(1, replica(ModeFollower, nil))
+		rec.SetReplica(2, replica(ModeLeader, map[string]string{
+			util.AnnotationLeadershipTransferAt: time.Now().Format(time.RFC3339),
+		}))
+
+		result, err := rec.handoffLeadership(ctx, log, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeNil())
+		Expect(result.RequeueAfter).ToNot(BeZero())
+	})
+
+	It("should restart the leader if transfer timed out", func(ctx context.Context) {
+		rec.SetReplica(1, replica(ModeFollower, nil))
+		rec.SetReplica(2, replica(ModeLeader, map[string]string{
+			util.AnnotationLeadershipTransferAt: time.Now().Add(-2 * leadershipTransferTimeout).Format(time.RFC3339),
+		}))
+
+		result, err := rec.handoffLeadership(ctx, log, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(BeNil())
+	})
+
+	It("should restart the leader if no follower can take over", func(ctx context.Context) {
+		rec.SetReplica(1, replica("", nil))
+		rec.SetReplica(2, replica(ModeLeader, nil))
+
+		result, err := rec.handoffLeadership(ctx, log, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(BeNil())
+	})
+})
@@ -0,0 +1,154 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// Interval between attempts to move the leadership back to the preferred zone.
+const leaderPlacementCheckInterval = time.Minute
+
+// getReplicaZone returns the zone of the node running the replica pod. Returns empty string if it is unknown.
+func (r *keeperReconciler) getReplicaZone(ctx context.Context, log ctrlutil.Logger, sts *appsv1.StatefulSet) string {
+	zoneKey := r.Cluster.Spec.PodTemplate.TopologyZoneKey
+	if zoneKey == nil || *zoneKey == "" {
+		return ""
+	}
+
+	var pod corev1.Pod
+	if err := r.GetClient().Get(ctx, types.NamespacedName{Namespace: sts.Namespace, Name: sts.Name + "-0"}, &pod); err != nil {
+		log.Debug("failed to get replica pod", "error", err)
+		return ""
+	}
+
+	if pod.Spec.NodeName == "" {
+		return ""
+	}
+
+	var node corev1.Node
+	// Nodes are not watched by the operator, read the single node directly instead of caching all of them.
+	if err := r.APIReader.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+		log.Info("failed to get replica node", "node", pod.Spec.NodeName, "error", err)
+		return ""
+	}
+
+	return node.Labels[*zoneKey]
+}
+
+// canLead reports whether the replica may become the quorum leader.
+func (r *keeperReconciler) canLead(id v1.KeeperReplicaID) bool {
+	return r.replicaPriority(id) > 0
+}
+
+// choosePlacementSuccessor returns the ready follower in the preferred zone to take over the leadership.
+// The leader is moved only to a replica with at least its priority: Keeper would otherwise elect the
+// higher priority replica again and the leadership would flap between the zones.
+func (r *keeperReconciler) choosePlacementSuccessor(leader v1.KeeperReplicaID, preferredZone string) (v1.KeeperReplicaID, bool) {
+	leaderPriority := r.replicaPriority(leader)
+	successor := v1.KeeperReplicaID(-1)
+
+	for id, replica := range r.ReplicaState {
+		if replica.Zone != preferredZone || replica.Status.ServerState != ModeFollower || !r.canLead(id) || !replica.Ready(r) {
+			continue
+		}
+
+		priority := r.replicaPriority(id)
+		if priority < leaderPriority {
+			continue
+		}
+
+		if successor == -1 || priority > r.replicaPriority(successor) ||
+			(priority == r.replicaPriority(successor) && id < successor) {
+			successor = id
+		}
+	}
+
+	return successor, successor != -1
+}
+
+// reconcileLeaderPlacement reports whether the leader runs in the preferred zone and moves it back if it drifted away.
+func (r *keeperReconciler) reconcileLeaderPlacement(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	preferredZone := r.Cluster.Spec.LeaderPlacement.PreferredZone
+	if preferredZone == "" {
+		r.SetCondition(log, r.NewCondition(v1.KeeperConditionTypeLeaderInPreferredZone, metav1.ConditionUnknown,
+			v1.KeeperConditionReasonLeaderZoneUnknown, "Preferred leader zone is not configured"))
+
+		return nil, nil
+	}
+
+	leader := v1.KeeperReplicaID(-1)
+	for id, replica := range r.ReplicaState {
+		if replica.Status.ServerState == ModeLeader || replica.Status.ServerState == ModeStandalone {
+			leader = id
+		}
+	}
+
+	if leader == -1 || r.Replica(leader).Zone == "" {
+		r.SetCondition(log, r.NewCondition(v1.KeeperConditionTypeLeaderInPreferredZone, metav1.ConditionUnknown,
+			v1.KeeperConditionReasonLeaderZoneUnknown, "Leader zone is not known"))
+
+		return nil, nil
+	}
+
+	leaderZone := r.Replica(leader).Zone
+	if leaderZone == preferredZone {
+		r.SetCondition(log, r.NewCondition(v1.KeeperConditionTypeLeaderInPreferredZone, metav1.ConditionTrue,
+			v1.KeeperConditionReasonLeaderInPreferredZone, fmt.Sprintf("Leader replica %d is in zone %q", leader, leaderZone)))
+
+		return nil, nil
+	}
+
+	message := fmt.Sprintf("Leader replica %d is in zone %q instead of preferred zone %q", leader, leaderZone, preferredZone)
+	if _, err := r.UpsertConditionAndSendEvent(ctx, log,
+		r.NewCondition(v1.KeeperConditionTypeLeaderInPreferredZone, metav1.ConditionFalse,
+			v1.KeeperConditionReasonLeaderOutOfPreferredZone, message),
+		corev1.EventTypeWarning, v1.EventReasonLeaderOutOfPreferredZone, v1.EventActionReconciling, "%s", message,
+	); err != nil {
+		return nil, fmt.Errorf("update leader in preferred zone condition: %w", err)
+	}
+
+	// Rolling updates and scaling move the leadership on their own, wait for the stable cluster.
+	if !r.HorizontalScaleAllowed {
+		return &ctrl.Result{RequeueAfter: leaderPlacementCheckInterval}, nil
+	}
+
+	successor, ok := r.choosePlacementSuccessor(leader, preferredZone)
+	if !ok {
+		log.Info("no ready follower in the preferred zone with at least the leader priority to take over the leadership",
+			"zone", preferredZone, "leader_priority", r.replicaPriority(leader))
+
+		return &ctrl.Result{RequeueAfter: leaderPlacementCheckInterval}, nil
+	}
+
+	hostname := r.Cluster.HostnameByID(leader)
+	successorHostname := r.Cluster.HostnameByID(successor)
+
+	requestCtx, cancel := context.WithTimeout(ctx, leadershipRequestTimeout)
+	defer cancel()
+
+	if err := requestLeadership(requestCtx, log, successorHostname, r.Cluster.Spec.Settings.TLS.Required); err != nil {
+		log.Warn("failed to request leadership transfer", "replica_id", leader, "successor", successor, "error", err)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonLeadershipTransferFailed,
+			v1.EventActionReconciling, "Failed to transfer the leadership from %q to %q in preferred zone %q: %v",
+			hostname, successorHostname, preferredZone, err)
+
+		return &ctrl.Result{RequeueAfter: leaderPlacementCheckInterval}, nil
+	}
+
+	log.Info("requested leadership transfer to the preferred zone", "replica_id", leader, "successor", successor)
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonLeadershipTransferRequested,
+		v1.EventActionReconciling, "Requested %q in preferred zone %q to take over the leadership from %q",
+		successorHostname, preferredZone, hostname)
+
+	return &ctrl.Result{RequeueAfter: leaderPlacementCheckInterval}, nil
+}
@@ -0,0 +1,129 @@
+package keeper
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("LeaderPlacement", func() {
+	var (
+		ctx context.Context
+		log util.Logger
+		rec *keeperReconciler
+	)
+
+	replica := func(mode, zone string) replicaState {
+		return replicaState{
+			StatefulSet: &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 1}},
+			Status:      serverStatus{ServerState: mode},
+			Zone:        zone,
+		}
+	}
+
+	placementCondition := func() *metav1.Condition {
+		return meta.FindStatusCondition(rec.Cluster.Status.Conditions, string(v1.KeeperConditionTypeLeaderInPreferredZone))
+	}
+
+	BeforeEach(func() {
+		var cancelEvents context.CancelFunc
+		ctx = context.Background()
+		log, rec, cancelEvents = setupReconciler()
+		DeferCleanup(cancelEvents)
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.Cluster.Spec.PodTemplate.TopologyZoneKey = ptr.To("topology.kubernetes.io/zone")
+		rec.Cluster.Spec.LeaderPlacement.PreferredZone = "zone-a"
+		Expect(rec.GetClient().Create(ctx, rec.Cluster.DeepCopy())).To(Succeed())
+	})
+
+	It("should load replica zone from the pod node", func() {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-keeper-1"}}
+		Expect(rec.getReplicaZone(ctx, log, sts)).To(BeEmpty())
+
+		Expect(rec.GetClient().Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"topology.kubernetes.io/zone": "zone-a"},
+		}})).To(Succeed())
+		Expect(rec.GetClient().Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-keeper-1-0"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		})).To(Succeed())
+
+		Expect(rec.getReplicaZone(ctx, log, sts)).To(Equal("zone-a"))
+	})
+
+	It("should report unknown placement if preferred zone is not set", func() {
+		rec.Cluster.Spec.LeaderPlacement.PreferredZone = ""
+		rec.SetReplica(1, replica(ModeLeader, "zone-b"))
+
+		_, err := rec.reconcileLeaderPlacement(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(placementCondition().Status).To(Equal(metav1.ConditionUnknown))
+	})
+
+	It("should report leader in the preferred zone", func() {
+		rec.SetReplica(1, replica(ModeLeader, "zone-a"))
+		rec.SetReplica(2, replica(ModeFollower, "zone-b"))
+
+		result, err := rec.reconcileLeaderPlacement(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+		Expect(placementCondition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(placementCondition().Reason).To(BeEquivalentTo(v1.KeeperConditionReasonLeaderInPreferredZone))
+	})
+
+	It("should report leader drift out of the preferred zone", func() {
+		rec.SetReplica(1, replica(ModeLeader, "zone-b"))
+		rec.SetReplica(2, replica(ModeFollower, "zone-a"))
+
+		result, err := rec.reconcileLeaderPlacement(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(result.RequeueAfter).To(Equal(leaderPlacementCheckInterval))
+		Expect(placementCondition().Status).To(Equal(metav1.ConditionFalse))
+		Expect(placementCondition().Reason).To(BeEquivalentTo(v1.KeeperConditionReasonLeaderOutOfPreferredZone))
+		Expect(placementCondition().Message).To(ContainSubstring(`"zone-b" instead of preferred zone "zone-a"`))
+	})
+
+	It("should move the leadership to the highest priority follower in the preferred zone", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](4)
+		rec.Cluster.Spec.LeaderPlacement.Priorities = []v1.KeeperReplicaPriority{{ReplicaID: 3, Priority: 2}}
+		rec.SetReplica(1, replica(ModeLeader, "zone-b"))
+		rec.SetReplica(2, replica(ModeFollower, "zone-a"))
+		rec.SetReplica(3, replica(ModeFollower, "zone-a"))
+		rec.SetReplica(4, replica(ModeFollower, "zone-b"))
+
+		successor, ok := rec.choosePlacementSuccessor(1, "zone-a")
+		Expect(ok).To(BeTrue())
+		Expect(successor).To(BeEquivalentTo(3))
+	})
+
+	It("should not move the leadership to a lower priority follower", func() {
+		rec.Cluster.Spec.LeaderPlacement.Priorities = []v1.KeeperReplicaPriority{
+			{ReplicaID: 1, Priority: 5},
+			{ReplicaID: 2, Priority: 1},
+		}
+		rec.SetReplica(1, replica(ModeLeader, "zone-b"))
+		rec.SetReplica(2, replica(ModeFollower, "zone-a"))
+		rec.SetReplica(3, replica(ModeFollower, "zone-b"))
+
+		_, ok := rec.choosePlacementSuccessor(1, "zone-a")
+		Expect(ok).To(BeFalse())
+
+		rec.HorizontalScaleAllowed = true
+		result, err := rec.reconcileLeaderPlacement(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(leaderPlacementCheckInterval))
+		Expect(placementCondition().Status).To(Equal(metav1.ConditionFalse))
+	})
+})
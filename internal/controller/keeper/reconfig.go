@@ -0,0 +1,317 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-zookeeper/zk"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// KeeperConfigPath is the system node holding the current quorum configuration.
+const KeeperConfigPath = "/keeper/config"
+
+// quorumRole is the type of the server in the Keeper quorum.
+type quorumRole string
+
+const (
+	roleParticipant quorumRole = "participant"
+	roleLearner     quorumRole = "learner"
+)
+
+// defaultPriority is the leader election priority Keeper assigns to a participant without an explicit one.
+const defaultPriority int32 = 1
+
+// quorumMemberState is the server type and the leader election priority of a quorum member.
+type quorumMemberState struct {
+	Role     quorumRole
+	Priority int32
+}
+
+// isLearner reports whether the replica is a non-voting learner.
+func (r *keeperReconciler) isLearner(id v1.KeeperReplicaID) bool {
+	return r.Replica(id).Role == roleLearner
+}
+
+// assignReplicaRoles sets the role of the replicas without a persisted one.
+// A new replica becomes a learner only if the requested voting replicas are present and learners are missing.
+func (r *keeperReconciler) assignReplicaRoles() {
+	voters, learners := 0, 0
+
+	for _, replica := range r.ReplicaState {
+		switch replica.Role {
+		case roleParticipant:
+			voters++
+		case roleLearner:
+			learners++
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(r.ReplicaState)) {
+		replica := r.ReplicaState[id]
+		if replica.Role != "" {
+			continue
+		}
+
+		if voters >= int(r.Cluster.Replicas()) && learners < int(r.Cluster.Learners()) {
+			replica.Role = roleLearner
+			learners++
+		} else {
+			replica.Role = roleParticipant
+			voters++
+		}
+
+		r.ReplicaState[id] = replica
+	}
+}
+
+// votingReplicas returns the number of voting replicas, or the requested one before the replicas are created.
+func (r *keeperReconciler) votingReplicas() int32 {
+	if len(r.ReplicaState) == 0 {
+		return r.Cluster.Replicas()
+	}
+
+	voters, _ := r.replicasByRole()
+
+	return int32(len(voters)) //nolint:gosec
+}
+
+// reconcileReplicaRoles assigns roles to the new replicas and persists them in the replica StatefulSets.
+// If the requested voting replicas count changes without the cluster size change, a single replica at a time
+// is promoted or demoted.
+func (r *keeperReconciler) reconcileReplicaRoles(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	r.assignReplicaRoles()
+
+	stable := r.HorizontalScaleAllowed && !r.BootstrapPending && !r.RecoveryInProgress &&
+		len(r.ReplicaState) == int(r.Cluster.TotalReplicas())
+	if voters, learners := r.replicasByRole(); stable {
+		switch {
+		case len(voters) < int(r.Cluster.Replicas()) && len(learners) > int(r.Cluster.Learners()):
+			r.setReplicaRole(log, slices.Min(learners), roleParticipant)
+		case len(voters) > int(r.Cluster.Replicas()) && len(learners) < int(r.Cluster.Learners()):
+			r.setReplicaRole(log, slices.Max(voters), roleLearner)
+		}
+	}
+
+	for id, replica := range r.ReplicaState {
+		if replica.StatefulSet == nil || replica.StatefulSet.Annotations[ctrlutil.AnnotationKeeperRole] == string(replica.Role) {
+			continue
+		}
+
+		ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationKeeperRole, string(replica.Role))
+
+		if err := r.Update(ctx, replica.StatefulSet, v1.EventActionScaling); err != nil {
+			return nil, fmt.Errorf("persist replica %q role: %w", id, err)
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *keeperReconciler) setReplicaRole(log ctrlutil.Logger, id v1.KeeperReplicaID, role quorumRole) {
+	log.Info("changing replica quorum role", "replica_id", id, "role", role)
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonReplicaRoleChanged, v1.EventActionScaling,
+		"Changing replica %q role to %s", r.Cluster.HostnameByID(id), role)
+
+	replica := r.Replica(id)
+	replica.Role = role
+	r.SetReplica(id, replica)
+}
+
+// replicasByRole returns sorted IDs of the voting and the learner replicas.
+func (r *keeperReconciler) replicasByRole() ([]v1.KeeperReplicaID, []v1.KeeperReplicaID) {
+	var voters, learners []v1.KeeperReplicaID
+
+	for _, id := range slices.Sorted(maps.Keys(r.ReplicaState)) {
+		if r.isLearner(id) {
+			learners = append(learners, id)
+		} else {
+			voters = append(voters, id)
+		}
+	}
+
+	return voters, learners
+}
+
+func (r *keeperReconciler) quorumRole(id v1.KeeperReplicaID) quorumRole {
+	if r.isLearner(id) {
+		return roleLearner
+	}
+
+	return roleParticipant
+}
+
+// replicaPriority returns the leader election priority of the replica. Learners never become leaders.
+func (r *keeperReconciler) replicaPriority(id v1.KeeperReplicaID) int32 {
+	if r.isLearner(id) {
+		return 0
+	}
+
+	if priority, ok := r.Cluster.ReplicaPriority(id); ok {
+		return priority
+	}
+
+	return defaultPriority
+}
+
+// quorumMember returns the server definition used to add the replica with the `reconfig` command.
+func quorumMember(cr *v1.KeeperCluster, id v1.KeeperReplicaID, role quorumRole) string {
+	member := fmt.Sprintf("server.%d=%s", id, net.JoinHostPort(cr.HostnameByID(id), strconv.Itoa(PortInterserver)))
+	if role == roleLearner {
+		return member + ";" + string(roleLearner) + ";0"
+	}
+
+	if priority, ok := cr.ReplicaPriority(id); ok {
+		return fmt.Sprintf("%s;%s;%d", member, roleParticipant, priority)
+	}
+
+	return member
+}
+
+// parseQuorumMembers parses the content of the "/keeper/config" node.
+// Every line has the "server.<id>=<host>:<port>;<type>;<priority>" format.
+func parseQuorumMembers(data []byte) (map[v1.KeeperReplicaID]quorumMemberState, error) {
+	members := map[v1.KeeperReplicaID]quorumMemberState{}
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		server, definition, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid quorum config line %q", line)
+		}
+
+		rawID, ok := strings.CutPrefix(server, "server.")
+		if !ok {
+			return nil, fmt.Errorf("invalid quorum config line %q", line)
+		}
+
+		id, err := strconv.ParseInt(rawID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse server ID %q: %w", rawID, err)
+		}
+
+		member := quorumMemberState{Role: roleParticipant, Priority: defaultPriority}
+
+		fields := strings.Split(definition, ";")
+		if len(fields) > 1 && fields[1] != "" {
+			member.Role = quorumRole(fields[1])
+		}
+
+		if len(fields) > 2 && fields[2] != "" {
+			priority, err := strconv.ParseInt(fields[2], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("parse server %d priority %q: %w", id, fields[2], err)
+			}
+
+			member.Priority = int32(priority)
+		}
+
+		members[v1.KeeperReplicaID(id)] = member
+	}
+
+	return members, nil
+}
+
+func getQuorumMembers(conn *zk.Conn) (map[v1.KeeperReplicaID]quorumMemberState, error) {
+	data, _, err := conn.Get(KeeperConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", KeeperConfigPath, err)
+	}
+
+	members, err := parseQuorumMembers(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", KeeperConfigPath, err)
+	}
+
+	return members, nil
+}
+
+// planQuorumReconfig returns servers to add to and remove from the current quorum members.
+// Replicas join the quorum only after their StatefulSet has started with the bootstrap configuration.
+// Server type can not be changed in place, so a replica changing its role leaves the quorum and joins it again.
+// Priority is changed in place by adding the existing server with the new priority.
+func (r *keeperReconciler) planQuorumReconfig(members map[v1.KeeperReplicaID]quorumMemberState) ([]string, []string) {
+	var joining, leaving []string
+
+	// Do not bring back replicas that are pending removal.
+	scalingDown := len(r.ReplicaState) > int(r.Cluster.TotalReplicas())
+
+	for id, replica := range r.ReplicaState {
+		if _, ok := members[id]; ok || scalingDown {
+			continue
+		}
+
+		if replica.StatefulSet == nil || replica.Error || !replica.Updated() {
+			continue
+		}
+
+		joining = append(joining, quorumMember(r.Cluster, id, r.quorumRole(id)))
+	}
+
+	for id := range members {
+		if _, ok := r.ReplicaState[id]; !ok {
+			leaving = append(leaving, strconv.FormatInt(int64(id), 10))
+		}
+	}
+
+	slices.Sort(joining)
+	slices.Sort(leaving)
+
+	if len(joining) > 0 || len(leaving) > 0 || scalingDown {
+		return joining, leaving
+	}
+
+	// Change role of a single replica at a time to keep the quorum available.
+	var changingRole []v1.KeeperReplicaID
+
+	for id, member := range members {
+		if _, ok := r.ReplicaState[id]; ok && member.Role != r.quorumRole(id) {
+			changingRole = append(changingRole, id)
+		}
+	}
+
+	if len(changingRole) > 0 {
+		leaving = append(leaving, strconv.FormatInt(int64(slices.Min(changingRole)), 10))
+		return joining, leaving
+	}
+
+	// Learners do not take part in the leader election, their priority is ignored.
+	for id, member := range members {
+		if _, ok := r.ReplicaState[id]; ok && member.Role == roleParticipant && member.Priority != r.replicaPriority(id) {
+			joining = append(joining, quorumMember(r.Cluster, id, roleParticipant))
+		}
+	}
+
+	slices.Sort(joining)
+
+	return joining, leaving
+}
+
+// leftQuorum reports whether the removed replica resources may be deleted.
+func (r *keeperReconciler) leftQuorum(id v1.KeeperReplicaID) bool {
+	// Static membership is changed by the quorum ConfigMap, scale to zero drops the whole quorum.
+	if !r.Cluster.Spec.Settings.DynamicReconfiguration || len(r.ReplicaState) == 0 {
+		return true
+	}
+
+	if r.QuorumMembers == nil {
+		return false
+	}
+
+	_, member := r.QuorumMembers[id]
+
+	return !member
+}
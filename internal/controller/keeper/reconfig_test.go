@@ -0,0 +1,250 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("QuorumReconfiguration", func() {
+	var (
+		log util.Logger
+		rec *keeperReconciler
+	)
+
+	startedReplica := func() replicaState {
+		return replicaState{StatefulSet: &appsv1.StatefulSet{}}
+	}
+
+	participant := quorumMemberState{Role: roleParticipant, Priority: defaultPriority}
+	learner := quorumMemberState{Role: roleLearner}
+
+	BeforeEach(func() {
+		var cancelEvents context.CancelFunc
+		log, rec, cancelEvents = setupReconciler()
+		DeferCleanup(cancelEvents)
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.Cluster.Spec.Settings.DynamicReconfiguration = true
+	})
+
+	It("should parse quorum members from keeper config", func() {
+		members, err := parseQuorumMembers([]byte(
+			"server.1=test-keeper-1.test:9234;participant;1\nserver.3=test-keeper-3.test:9234;participant;1\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(members).To(HaveLen(2))
+		Expect(members).To(HaveKey(v1.KeeperReplicaID(1)))
+		Expect(members).To(HaveKey(v1.KeeperReplicaID(3)))
+
+		_, err = parseQuorumMembers([]byte("1=test-keeper-1.test:9234"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should add started replicas missing in the quorum", func() {
+		rec.SetReplica(1, startedReplica())
+		rec.SetReplica(2, startedReplica())
+		rec.SetReplica(3, replicaState{})
+
+		joining, leaving := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{1: participant})
+		Expect(joining).To(HaveExactElements(quorumMember(rec.Cluster, 2, roleParticipant)))
+		Expect(joining[0]).To(HavePrefix("server.2=" + rec.Cluster.HostnameByID(2) + ":9234"))
+		Expect(leaving).To(BeEmpty())
+	})
+
+	It("should remove replicas missing in the cluster state", func() {
+		rec.SetReplica(1, startedReplica())
+
+		joining, leaving := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{1: participant, 2: participant})
+		Expect(joining).To(BeEmpty())
+		Expect(leaving).To(HaveExactElements("2"))
+	})
+
+	It("should not rejoin replicas pending removal", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](1)
+		rec.SetReplica(1, startedReplica())
+		rec.SetReplica(2, startedReplica())
+
+		joining, _ := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{1: participant})
+		Expect(joining).To(BeEmpty())
+	})
+
+	It("should parse learners from keeper config", func() {
+		members, err := parseQuorumMembers([]byte(
+			"server.1=test-keeper-1.test:9234;participant;1\nserver.2=test-keeper-2.test:9234;learner;0\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(members).To(HaveKeyWithValue(v1.KeeperReplicaID(1), participant))
+		Expect(members).To(HaveKeyWithValue(v1.KeeperReplicaID(2), learner))
+	})
+
+	It("should parse priorities from keeper config", func() {
+		members, err := parseQuorumMembers([]byte(
+			"server.1=test-keeper-1.test:9234;participant;5\nserver.2=test-keeper-2.test:9234\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(members).To(HaveKeyWithValue(v1.KeeperReplicaID(1), quorumMemberState{Role: roleParticipant, Priority: 5}))
+		Expect(members).To(HaveKeyWithValue(v1.KeeperReplicaID(2), participant))
+
+		_, err = parseQuorumMembers([]byte("server.1=test-keeper-1.test:9234;participant;high"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should update priority of existing members in place", func() {
+		rec.Cluster.Spec.LeaderPlacement.Priorities = []v1.KeeperReplicaPriority{
+			{ReplicaID: 1, Priority: 5},
+			{ReplicaID: 3, Priority: 1},
+		}
+		rec.SetReplica(1, startedReplica())
+		rec.SetReplica(2, startedReplica())
+		rec.SetReplica(3, startedReplica())
+
+		joining, leaving := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{
+			1: participant,
+			2: {Role: roleParticipant, Priority: 3},
+			3: participant,
+		})
+		Expect(joining).To(HaveExactElements(
+			quorumMember(rec.Cluster, 1, roleParticipant),
+			quorumMember(rec.Cluster, 2, roleParticipant),
+		))
+		Expect(joining[0]).To(HaveSuffix(";participant;5"))
+		Expect(leaving).To(BeEmpty())
+	})
+
+	It("should change roles before priorities", func() {
+		rec.Cluster.Spec.LeaderPlacement.Priorities = []v1.KeeperReplicaPriority{{ReplicaID: 1, Priority: 5}}
+		rec.SetReplica(1, startedReplica())
+		rec.SetReplica(2, startedReplica())
+
+		joining, leaving := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{1: participant, 2: learner})
+		Expect(joining).To(BeEmpty())
+		Expect(leaving).To(HaveExactElements("2"))
+	})
+
+	It("should add learners with the learner role", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](1)
+		rec.Cluster.Spec.Learners = ptr.To[int32](1)
+		rec.SetReplica(1, startedReplica())
+		rec.SetReplica(2, startedReplica())
+		rec.assignReplicaRoles()
+
+		joining, leaving := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{1: participant})
+		Expect(joining).To(HaveExactElements(quorumMember(rec.Cluster, 2, roleLearner)))
+		Expect(joining[0]).To(HaveSuffix(";learner;0"))
+		Expect(leaving).To(BeEmpty())
+	})
+
+	It("should add replicas with requested priority", func() {
+		rec.Cluster.Spec.LeaderPlacement.Priorities = []v1.KeeperReplicaPriority{{ReplicaID: 2, Priority: 5}}
+
+		Expect(quorumMember(rec.Cluster, 1, roleParticipant)).NotTo(ContainSubstring(";"))
+		Expect(quorumMember(rec.Cluster, 2, roleParticipant)).To(HaveSuffix(";participant;5"))
+		Expect(quorumMember(rec.Cluster, 2, roleLearner)).To(HaveSuffix(";learner;0"))
+	})
+
+	It("should re-add promoted learners one at a time", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.SetReplica(1, startedReplica())
+		rec.SetReplica(2, startedReplica())
+		rec.SetReplica(3, startedReplica())
+
+		joining, leaving := rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{
+			1: participant, 2: learner, 3: learner,
+		})
+		Expect(joining).To(BeEmpty())
+		Expect(leaving).To(HaveExactElements("2"))
+
+		joining, leaving = rec.planQuorumReconfig(map[v1.KeeperReplicaID]quorumMemberState{1: participant, 3: learner})
+		Expect(joining).To(HaveExactElements(quorumMember(rec.Cluster, 2, roleParticipant)))
+		Expect(leaving).To(BeEmpty())
+	})
+
+	It("should keep persisted roles of the replicas", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](2)
+		rec.Cluster.Spec.Learners = ptr.To[int32](1)
+		rec.SetReplica(1, replicaState{Role: roleLearner})
+		rec.SetReplica(2, replicaState{})
+		rec.SetReplica(3, replicaState{})
+		rec.assignReplicaRoles()
+
+		Expect(rec.isLearner(1)).To(BeTrue())
+		Expect(rec.isLearner(2)).To(BeFalse())
+		Expect(rec.isLearner(3)).To(BeFalse())
+	})
+
+	It("should remove the voter on voting replicas scale down", func() {
+		ctx := context.Background()
+		Expect(rec.GetClient().Create(ctx, rec.Cluster.DeepCopy())).To(Succeed())
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](2)
+		rec.Cluster.Spec.Learners = ptr.To[int32](1)
+		rec.HorizontalScaleAllowed = true
+		rec.SetReplica(1, replicaState{Role: roleParticipant})
+		rec.SetReplica(2, replicaState{Role: roleParticipant})
+		rec.SetReplica(3, replicaState{Role: roleParticipant})
+		rec.SetReplica(4, replicaState{Role: roleLearner})
+
+		_, err := rec.reconcileQuorumMembership(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.ReplicaState).To(HaveLen(3))
+		Expect(rec.ReplicaState).NotTo(HaveKey(v1.KeeperReplicaID(3)))
+		Expect(rec.isLearner(4)).To(BeTrue())
+
+		By("removing the learner on learners scale down")
+		rec.Cluster.Spec.Learners = ptr.To[int32](0)
+
+		_, err = rec.reconcileQuorumMembership(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.ReplicaState).To(HaveLen(2))
+		Expect(rec.ReplicaState).NotTo(HaveKey(v1.KeeperReplicaID(4)))
+	})
+
+	It("should change roles one replica at a time and persist them", func() {
+		ctx := context.Background()
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](1)
+		rec.Cluster.Spec.Learners = ptr.To[int32](2)
+		rec.HorizontalScaleAllowed = true
+
+		for id := range v1.KeeperReplicaID(3) {
+			sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("test-keeper-%d", id)}}
+			Expect(rec.GetClient().Create(ctx, sts)).To(Succeed())
+			rec.SetReplica(id, replicaState{Role: roleParticipant, StatefulSet: sts})
+		}
+
+		_, err := rec.reconcileReplicaRoles(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.isLearner(2)).To(BeTrue())
+		Expect(rec.isLearner(1)).To(BeFalse())
+
+		var sts appsv1.StatefulSet
+		Expect(rec.GetClient().Get(ctx, types.NamespacedName{Namespace: "default", Name: "test-keeper-2"}, &sts)).To(Succeed())
+		Expect(sts.Annotations).To(HaveKeyWithValue(util.AnnotationKeeperRole, string(roleLearner)))
+
+		_, err = rec.reconcileReplicaRoles(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.isLearner(1)).To(BeTrue())
+		Expect(rec.isLearner(0)).To(BeFalse())
+	})
+
+	It("should keep removed replicas until they leave the quorum", func() {
+		rec.SetReplica(1, startedReplica())
+		Expect(rec.leftQuorum(2)).To(BeFalse())
+
+		rec.QuorumMembers = map[v1.KeeperReplicaID]quorumMemberState{1: participant, 2: participant}
+		Expect(rec.leftQuorum(2)).To(BeFalse())
+
+		rec.QuorumMembers = map[v1.KeeperReplicaID]quorumMemberState{1: participant}
+		Expect(rec.leftQuorum(2)).To(BeTrue())
+
+		rec.Cluster.Spec.Settings.DynamicReconfiguration = false
+		rec.QuorumMembers = nil
+		Expect(rec.leftQuorum(2)).To(BeTrue())
+	})
+})
@@ -0,0 +1,219 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+const (
+	// Time to wait for the source replica to form the new quorum before requesting the recovery mode again.
+	quorumRecoveryRetryInterval  = 5 * time.Minute
+	quorumRecoveryRequestTimeout = 10 * time.Second
+)
+
+// hasQuorum reports whether the leader has enough voting followers to serve requests.
+func (r *keeperReconciler) hasQuorum() bool {
+	voters, followers := 0, 0
+	hasLeader := false
+
+	for id, replica := range r.ReplicaState {
+		if r.isLearner(id) {
+			continue
+		}
+
+		voters++
+
+		switch replica.Status.ServerState {
+		case ModeStandalone:
+			return len(r.ReplicaState) == 1
+		case ModeLeader:
+			hasLeader = true
+		case ModeFollower:
+			followers++
+		}
+	}
+
+	// The leader with its followers must form a strict majority of the voters.
+	return hasLeader && followers >= voters/2
+}
+
+// quorumRecoverySource returns the replica marked as the source of the given recovery request.
+func (r *keeperReconciler) quorumRecoverySource(request string) (v1.KeeperReplicaID, bool) {
+	for id, replica := range r.ReplicaState {
+		if replica.StatefulSet != nil && replica.StatefulSet.Annotations[ctrlutil.AnnotationQuorumRecoverySource] == request {
+			return id, true
+		}
+	}
+
+	return -1, false
+}
+
+// chooseRecoverySource returns the surviving voting replica with the highest last log index.
+func (r *keeperReconciler) chooseRecoverySource(ctx context.Context, log ctrlutil.Logger) (v1.KeeperReplicaID, uint64, bool) {
+	source := v1.KeeperReplicaID(-1)
+
+	var sourceIndex uint64
+
+	for _, id := range slices.Sorted(maps.Keys(r.ReplicaState)) {
+		if r.Replica(id).StatefulSet == nil || r.isLearner(id) {
+			continue
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx, quorumRecoveryRequestTimeout)
+		index, err := getLastLogIndex(requestCtx, log, r.Cluster.HostnameByID(id), r.Cluster.Spec.Settings.TLS.Required)
+
+		cancel()
+
+		if err != nil {
+			log.Info("failed to get replica log index", "replica_id", id, "error", err)
+			continue
+		}
+
+		log.Info("surviving replica log index", "replica_id", id, "last_log_idx", index)
+
+		if source == -1 || index > sourceIndex {
+			source, sourceIndex = id, index
+		}
+	}
+
+	return source, sourceIndex, source != -1
+}
+
+// alignReplicasForRecovery adds the lost replicas, so the recovered quorum is formed from the requested replicas.
+// Extra replicas are kept with their StatefulSets and are removed by the regular scale down after the recovery.
+func (r *keeperReconciler) alignReplicasForRecovery(log ctrlutil.Logger) {
+	requested := int(r.Cluster.TotalReplicas())
+
+	for id := v1.KeeperReplicaID(0); len(r.ReplicaState) < requested; id++ {
+		if _, ok := r.ReplicaState[id]; !ok {
+			log.Info("adding replica to the recovered quorum", "replica_id", id)
+			r.SetReplica(id, replicaState{})
+		}
+	}
+
+	r.assignReplicaRoles()
+}
+
+// reconcileQuorumRecovery recovers the quorum after the majority of replicas was lost.
+// Triggered by the quorum recovery annotation, the surviving replica with the most recent log forms the new quorum
+// of the requested replicas in the recovery mode.
+func (r *keeperReconciler) reconcileQuorumRecovery(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	request := r.Cluster.Annotations[ctrlutil.AnnotationQuorumRecovery]
+	if request == "" || request == r.Cluster.Status.LastQuorumRecovery || len(r.ReplicaState) == 0 {
+		return nil, nil
+	}
+
+	log = log.With("recovery_request", request)
+
+	source, found := r.quorumRecoverySource(request)
+	if !found {
+		if r.hasQuorum() {
+			log.Info("cluster has quorum, skipping recovery")
+			r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonQuorumRecoverySkipped,
+				v1.EventActionRecovering, "Quorum recovery %q is not required, the cluster has quorum", request)
+			r.Cluster.Status.LastQuorumRecovery = request
+
+			return nil, nil
+		}
+
+		var lastLogIndex uint64
+
+		source, lastLogIndex, found = r.chooseRecoverySource(ctx, log)
+		if !found {
+			r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonQuorumRecoveryFailed,
+				v1.EventActionRecovering, "No surviving replica responded, waiting to recover the quorum")
+
+			return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+		}
+
+		ctrlutil.AddHashWithKeyToAnnotations(r.Replica(source).StatefulSet, ctrlutil.AnnotationQuorumRecoverySource, request)
+
+		if err := r.Update(ctx, r.Replica(source).StatefulSet, v1.EventActionRecovering); err != nil {
+			return nil, fmt.Errorf("mark replica %q as quorum recovery source: %w", source, err)
+		}
+
+		log.Info("recovering quorum", "replica_id", source, "last_log_idx", lastLogIndex)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonQuorumRecoveryStarted,
+			v1.EventActionRecovering, "Recovering the quorum from replica %q with the last log index %d",
+			r.Cluster.HostnameByID(source), lastLogIndex)
+	}
+
+	r.RecoveryInProgress = true
+	r.alignReplicasForRecovery(log)
+
+	replica := r.Replica(source)
+	hostname := r.Cluster.HostnameByID(source)
+
+	if replica.Status.ServerState == ModeLeader && r.hasQuorum() {
+		delete(replica.StatefulSet.Annotations, ctrlutil.AnnotationQuorumRecoverySource)
+		delete(replica.StatefulSet.Annotations, ctrlutil.AnnotationQuorumRecoveryAt)
+
+		if err := r.Update(ctx, replica.StatefulSet, v1.EventActionRecovering); err != nil {
+			return nil, fmt.Errorf("unmark replica %q as quorum recovery source: %w", source, err)
+		}
+
+		r.RecoveryInProgress = false
+		r.Cluster.Status.LastQuorumRecovery = request
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonQuorumRecovered,
+			v1.EventActionRecovering, "Quorum is recovered from replica %q", hostname)
+
+		return nil, nil
+	}
+
+	// Every recovery request restarts the Raft server, repeat it only if the new quorum is not formed for a long time.
+	if requestedAt, ok := replica.StatefulSet.Annotations[ctrlutil.AnnotationQuorumRecoveryAt]; ok {
+		if ts, err := time.Parse(time.RFC3339, requestedAt); err == nil && time.Since(ts) < quorumRecoveryRetryInterval {
+			log.Debug("waiting for the recovered quorum", "replica_id", source, "requested_at", requestedAt)
+			return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+		}
+	}
+
+	// The source replica forms the new quorum from its configuration, write it before requesting the recovery mode.
+	configMap, err := templateQuorumConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("template quorum config: %w", err)
+	}
+
+	configChanged, err := r.ReconcileConfigMap(ctx, log, configMap, v1.EventActionRecovering)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile recovered quorum config: %w", err)
+	}
+
+	if configChanged {
+		log.Info("quorum config is updated, waiting for it to reach the source replica", "replica_id", source)
+		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, quorumRecoveryRequestTimeout)
+	defer cancel()
+
+	if err := forceRecovery(requestCtx, log, hostname, r.Cluster.Spec.Settings.TLS.Required); err != nil {
+		log.Warn("failed to request recovery mode", "replica_id", source, "error", err)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonQuorumRecoveryFailed,
+			v1.EventActionRecovering, "Failed to switch replica %q to the recovery mode: %v", hostname, err)
+
+		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonQuorumRecoveryStarted,
+		v1.EventActionRecovering, "Switched replica %q to the recovery mode, waiting for %d replicas to join the quorum",
+		hostname, len(r.ReplicaState))
+
+	ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationQuorumRecoveryAt, time.Now().Format(time.RFC3339))
+
+	if err := r.Update(ctx, replica.StatefulSet, v1.EventActionRecovering); err != nil {
+		return nil, fmt.Errorf("mark replica %q recovery request: %w", source, err)
+	}
+
+	return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+}
@@ -0,0 +1,185 @@
+package keeper
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("ParseLastLogIndex", func() {
+	It("should parse the last log index", func() {
+		index, err := parseLastLogIndex([]byte("first_log_idx\t1\nfirst_log_term\t1\nlast_log_idx\t101\n" +
+			"last_log_term\t2\nlast_committed_log_idx\t100\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(index).To(BeEquivalentTo(101))
+	})
+
+	It("should fail on response without the last log index", func() {
+		_, err := parseLastLogIndex([]byte("lgif is not executed because it is not in the whitelist."))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("QuorumRecovery", func() {
+	var (
+		ctx context.Context
+		log util.Logger
+		rec *keeperReconciler
+	)
+
+	replica := func(id v1.KeeperReplicaID, mode string, annotations map[string]string) replicaState {
+		sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   rec.Cluster.Namespace,
+			Name:        rec.Cluster.StatefulSetNameByReplicaID(id),
+			Annotations: annotations,
+		}}
+		Expect(rec.GetClient().Create(ctx, sts)).To(Succeed())
+
+		return replicaState{StatefulSet: sts, Status: serverStatus{ServerState: mode}}
+	}
+
+	BeforeEach(func() {
+		var cancelEvents context.CancelFunc
+		ctx = context.Background()
+		log, rec, cancelEvents = setupReconciler()
+		DeferCleanup(cancelEvents)
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.Cluster.Annotations = map[string]string{util.AnnotationQuorumRecovery: "request-1"}
+	})
+
+	It("should detect the quorum loss", func() {
+		rec.SetReplica(0, replicaState{Status: serverStatus{ServerState: ModeLeader}})
+		rec.SetReplica(1, replicaState{Status: serverStatus{ServerState: ModeFollower}})
+		rec.SetReplica(2, replicaState{})
+		Expect(rec.hasQuorum()).To(BeTrue())
+
+		rec.SetReplica(1, replicaState{})
+		Expect(rec.hasQuorum()).To(BeFalse())
+	})
+
+	It("should require the strict majority of the even number of voters", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](5)
+		rec.Cluster.Spec.Learners = ptr.To[int32](1)
+		rec.SetReplica(0, replicaState{Role: roleParticipant, Status: serverStatus{ServerState: ModeLeader}})
+		rec.SetReplica(1, replicaState{Role: roleParticipant, Status: serverStatus{ServerState: ModeFollower}})
+		rec.SetReplica(2, replicaState{Role: roleParticipant})
+		rec.SetReplica(3, replicaState{Role: roleParticipant})
+		rec.SetReplica(4, replicaState{Role: roleLearner, Status: serverStatus{ServerState: ModeFollower}})
+		Expect(rec.hasQuorum()).To(BeFalse())
+
+		rec.SetReplica(2, replicaState{Role: roleParticipant, Status: serverStatus{ServerState: ModeFollower}})
+		Expect(rec.hasQuorum()).To(BeTrue())
+	})
+
+	It("should recover the quorum with the requested replicas", func() {
+		rec.SetReplica(1, replicaState{})
+		rec.SetReplica(3, replicaState{})
+		rec.SetReplica(4, replicaState{})
+		rec.SetReplica(5, replicaState{})
+
+		By("keeping the extra replicas")
+		rec.alignReplicasForRecovery(log)
+		Expect(rec.ReplicaState).To(HaveLen(4))
+
+		By("adding the lost replicas")
+		rec.Cluster.Spec.Replicas = ptr.To[int32](6)
+		rec.alignReplicasForRecovery(log)
+		Expect(rec.ReplicaState).To(HaveLen(6))
+		Expect(rec.ReplicaState).To(HaveKey(v1.KeeperReplicaID(0)))
+		Expect(rec.ReplicaState).To(HaveKey(v1.KeeperReplicaID(2)))
+	})
+
+	It("should skip recovery without request", func() {
+		rec.Cluster.Annotations = nil
+		rec.SetReplica(0, replica(0, "", nil))
+
+		result, err := rec.reconcileQuorumRecovery(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+		Expect(rec.RecoveryInProgress).To(BeFalse())
+	})
+
+	It("should skip recovery if the cluster has quorum", func() {
+		rec.SetReplica(0, replica(0, ModeLeader, nil))
+		rec.SetReplica(1, replica(1, ModeFollower, nil))
+		rec.SetReplica(2, replica(2, ModeFollower, nil))
+
+		result, err := rec.reconcileQuorumRecovery(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+		Expect(rec.RecoveryInProgress).To(BeFalse())
+		Expect(rec.Cluster.Status.LastQuorumRecovery).To(Equal("request-1"))
+	})
+
+	It("should complete recovery once the new quorum is formed", func() {
+		rec.SetReplica(0, replica(0, ModeLeader, map[string]string{
+			util.AnnotationQuorumRecoverySource: "request-1",
+			util.AnnotationQuorumRecoveryAt:     "2026-01-01T00:00:00Z",
+		}))
+		rec.SetReplica(1, replica(1, ModeFollower, nil))
+		rec.SetReplica(2, replica(2, "", nil))
+
+		result, err := rec.reconcileQuorumRecovery(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+		Expect(rec.RecoveryInProgress).To(BeFalse())
+		Expect(rec.Cluster.Status.LastQuorumRecovery).To(Equal("request-1"))
+
+		var sts appsv1.StatefulSet
+		Expect(rec.GetClient().Get(ctx, client.ObjectKeyFromObject(rec.Replica(0).StatefulSet), &sts)).To(Succeed())
+		Expect(sts.Annotations).NotTo(HaveKey(util.AnnotationQuorumRecoverySource))
+		Expect(sts.Annotations).NotTo(HaveKey(util.AnnotationQuorumRecoveryAt))
+	})
+
+	It("should wait for the recovered quorum and postpone rolling updates", func() {
+		rec.SetReplica(0, replica(0, "", map[string]string{
+			util.AnnotationQuorumRecoverySource: "request-1",
+			util.AnnotationQuorumRecoveryAt:     "2999-01-01T00:00:00Z",
+		}))
+		rec.SetReplica(1, replica(1, "", nil))
+
+		result, err := rec.reconcileQuorumRecovery(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(rec.RecoveryInProgress).To(BeTrue())
+		Expect(rec.ReplicaState).To(HaveLen(3))
+		Expect(rec.Cluster.Status.LastQuorumRecovery).To(BeEmpty())
+	})
+
+	It("should write the quorum config before requesting the recovery mode", func() {
+		rec.Cluster.Spec.Replicas = ptr.To[int32](4)
+		rec.SetReplica(0, replica(0, "", map[string]string{util.AnnotationQuorumRecoverySource: "request-1"}))
+		rec.SetReplica(1, replica(1, "", nil))
+		rec.SetReplica(5, replica(5, "", nil))
+
+		result, err := rec.reconcileQuorumRecovery(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(rec.ReplicaState).To(HaveLen(4))
+
+		var configMap corev1.ConfigMap
+		Expect(rec.GetClient().Get(ctx, types.NamespacedName{
+			Namespace: rec.Cluster.Namespace,
+			Name:      rec.Cluster.QuorumConfigMapName(),
+		}, &configMap)).To(Succeed())
+
+		for _, id := range []v1.KeeperReplicaID{0, 1, 2, 5} {
+			Expect(configMap.Data).To(ContainElement(ContainSubstring(rec.Cluster.HostnameByID(id))))
+		}
+
+		var sts appsv1.StatefulSet
+		Expect(rec.GetClient().Get(ctx, client.ObjectKeyFromObject(rec.Replica(0).StatefulSet), &sts)).To(Succeed())
+		Expect(sts.Annotations).NotTo(HaveKey(util.AnnotationQuorumRecoveryAt))
+	})
+})
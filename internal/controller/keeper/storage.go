@@ -0,0 +1,278 @@
+package keeper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	chctrl "github.com/ClickHouse/clickhouse-operator/internal/controller"
+	ctrlutil "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+const (
+	// Interval between data volume usage checks of a stable cluster.
+	storageCheckInterval = 5 * time.Minute
+	// Minimal interval between snapshots scheduled by the operator on a single replica.
+	snapshotRequestInterval = 30 * time.Minute
+	snapshotRequestTimeout  = 10 * time.Second
+)
+
+// replicaStorageUsage describes the data volume usage of a single replica.
+type replicaStorageUsage struct {
+	ID       v1.KeeperReplicaID
+	Used     int64
+	Capacity int64
+	Storage  storageStatus
+}
+
+// Ratio returns the share of the data volume in use.
+func (u replicaStorageUsage) Ratio() float64 {
+	return float64(u.Used) / float64(u.Capacity)
+}
+
+func (u replicaStorageUsage) String() string {
+	if !u.Storage.Known {
+		return fmt.Sprintf("%d: %.0f%% (%d bytes used)", u.ID, u.Ratio()*100, u.Used)
+	}
+
+	return fmt.Sprintf("%d: %.0f%% (%d bytes used, snapshots %d bytes, logs %d bytes)",
+		u.ID, u.Ratio()*100, u.Used, u.Storage.SnapshotDirSize, u.Storage.LogDirSize)
+}
+
+// loadReplicaVolumes returns data volume PVC of every replica.
+func (r *keeperReconciler) loadReplicaVolumes(ctx context.Context) (map[v1.KeeperReplicaID]corev1.PersistentVolumeClaim, error) {
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := r.GetClient().List(ctx, &pvcs, ctrlutil.AppRequirements(r.Cluster.Namespace, r.Cluster.SpecificName())); err != nil {
+		return nil, fmt.Errorf("list PVCs: %w", err)
+	}
+
+	volumes := map[v1.KeeperReplicaID]corev1.PersistentVolumeClaim{}
+
+	for _, pvc := range pvcs.Items {
+		id, err := v1.KeeperReplicaIDFromLabels(pvc.Labels)
+		if err != nil {
+			continue
+		}
+
+		volumes[id] = pvc
+	}
+
+	return volumes, nil
+}
+
+// loadVolumeStats returns the usage of volumes reported by kubelet on the nodes running the cluster pods.
+// Nodes not reporting stats are skipped, their replicas fall back to the `dirs` command sizes.
+func (r *keeperReconciler) loadVolumeStats(ctx context.Context, log ctrlutil.Logger) map[types.NamespacedName]chctrl.VolumeStats {
+	if r.VolumeStats == nil {
+		return nil
+	}
+
+	var pods corev1.PodList
+	if err := r.GetClient().List(ctx, &pods, ctrlutil.AppRequirements(r.Cluster.Namespace, r.Cluster.SpecificName())); err != nil {
+		log.Info("failed to list cluster pods", "error", err)
+		return nil
+	}
+
+	nodes := map[string]struct{}{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			nodes[pod.Spec.NodeName] = struct{}{}
+		}
+	}
+
+	stats := map[types.NamespacedName]chctrl.VolumeStats{}
+
+	for node := range nodes {
+		nodeStats, err := r.VolumeStats.GetVolumeStats(ctx, node)
+		if err != nil {
+			log.Info("failed to get kubelet volume stats", "node", node, "error", err)
+			continue
+		}
+
+		maps.Copy(stats, nodeStats)
+	}
+
+	return stats
+}
+
+// volumeCapacity returns the capacity of the PVC, or its requested size if it is not bound yet.
+func volumeCapacity(pvc corev1.PersistentVolumeClaim) int64 {
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok {
+		capacity = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+
+	return capacity.Value()
+}
+
+// reconcileStorage checks the share of replicas data volumes in use.
+func (r *keeperReconciler) reconcileStorage(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	if r.Cluster.Spec.DataVolumeClaimSpec == nil || len(r.ReplicaState) == 0 {
+		chctrl.SetKeeperStorageUsage(r.Cluster.NamespacedName(), nil)
+		r.SetCondition(log, r.NewCondition(v1.KeeperConditionTypeStorageHealthy, metav1.ConditionUnknown,
+			v1.KeeperConditionReasonStorageUsageUnknown, "Data volume is not configured"))
+
+		return nil, nil
+	}
+
+	r.updateApproximateDataSize()
+
+	volumes, err := r.loadReplicaVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	volumeStats := r.loadVolumeStats(ctx, log)
+
+	threshold := float64(r.Cluster.Spec.Storage.UsageThresholdPercent) / 100
+	if threshold <= 0 {
+		threshold = float64(v1.DefaultKeeperStorageUsageThresholdPercent) / 100
+	}
+
+	var (
+		exceeded []replicaStorageUsage
+		unknown  []v1.KeeperReplicaID
+	)
+
+	usageMetrics := map[string]float64{}
+
+	for id, replica := range r.ReplicaState {
+		pvc, ok := volumes[id]
+		if !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+
+		// Kubelet stats include all files on the volume, `dirs` sizes are used only if they are not available.
+		usage := replicaStorageUsage{ID: id, Storage: replica.Storage}
+		if stats, ok := volumeStats[types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}]; ok {
+			usage.Used = stats.UsedBytes
+			usage.Capacity = stats.CapacityBytes
+		} else if replica.Storage.Known {
+			usage.Used = replica.Storage.Used()
+			usage.Capacity = volumeCapacity(pvc)
+		}
+
+		if usage.Capacity <= 0 {
+			unknown = append(unknown, id)
+			continue
+		}
+
+		usageMetrics[strconv.FormatInt(int64(id), 10)] = usage.Ratio()
+		log.Debug("replica storage usage", "replica_id", id, "usage", usage.Ratio(),
+			"approximate_data_size", replica.Status.ApproximateDataSize)
+
+		if usage.Ratio() >= threshold {
+			exceeded = append(exceeded, usage)
+		}
+	}
+
+	chctrl.SetKeeperStorageUsage(r.Cluster.NamespacedName(), usageMetrics)
+
+	result := &ctrl.Result{RequeueAfter: storageCheckInterval}
+
+	switch {
+	case len(exceeded) > 0:
+		slices.SortFunc(exceeded, func(a, b replicaStorageUsage) int { return cmp.Compare(a.ID, b.ID) })
+
+		descriptions := make([]string, 0, len(exceeded))
+		for _, usage := range exceeded {
+			descriptions = append(descriptions, usage.String())
+		}
+
+		message := fmt.Sprintf("Data volume usage is above %.0f%% on replicas: %s",
+			threshold*100, strings.Join(descriptions, "; "))
+		if _, err := r.UpsertConditionAndSendEvent(ctx, log,
+			r.NewCondition(v1.KeeperConditionTypeStorageHealthy, metav1.ConditionFalse,
+				v1.KeeperConditionReasonStorageUsageHigh, message),
+			corev1.EventTypeWarning, v1.EventReasonStorageUsageHigh, v1.EventActionReconciling, "%s", message,
+		); err != nil {
+			return nil, fmt.Errorf("update storage healthy condition: %w", err)
+		}
+
+		if r.Cluster.Spec.Storage.CreateSnapshotOnThreshold {
+			for _, usage := range exceeded {
+				if err := r.requestSnapshot(ctx, log, usage.ID); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+	case len(unknown) == len(r.ReplicaState):
+		r.SetCondition(log, r.NewCondition(v1.KeeperConditionTypeStorageHealthy, metav1.ConditionUnknown,
+			v1.KeeperConditionReasonStorageUsageUnknown, "Data volume usage is not available"))
+
+	default:
+		message := ""
+		if len(unknown) > 0 {
+			slices.Sort(unknown)
+			message = fmt.Sprintf("Data volume usage is not available on replicas: %v", unknown)
+		}
+
+		r.SetCondition(log, r.NewCondition(v1.KeeperConditionTypeStorageHealthy, metav1.ConditionTrue,
+			v1.KeeperConditionReasonStorageUsageNormal, message))
+	}
+
+	return result, nil
+}
+
+// updateApproximateDataSize reports the size of the Keeper in-memory data of the leader in the cluster status.
+func (r *keeperReconciler) updateApproximateDataSize() {
+	for _, replica := range r.ReplicaState {
+		if replica.Status.ServerState == ModeLeader || replica.Status.ServerState == ModeStandalone {
+			r.Cluster.Status.ApproximateDataSize = replica.Status.ApproximateDataSize
+			return
+		}
+	}
+}
+
+// requestSnapshot schedules a snapshot on the replica unless one was requested recently.
+func (r *keeperReconciler) requestSnapshot(ctx context.Context, log ctrlutil.Logger, id v1.KeeperReplicaID) error {
+	replica := r.Replica(id)
+	if replica.StatefulSet == nil {
+		return nil
+	}
+
+	if requestedAt, ok := replica.StatefulSet.Annotations[ctrlutil.AnnotationSnapshotRequestedAt]; ok {
+		if ts, err := time.Parse(time.RFC3339, requestedAt); err == nil && time.Since(ts) < snapshotRequestInterval {
+			log.Debug("snapshot was requested recently", "replica_id", id, "requested_at", requestedAt)
+			return nil
+		}
+	}
+
+	hostname := r.Cluster.HostnameByID(id)
+
+	requestCtx, cancel := context.WithTimeout(ctx, snapshotRequestTimeout)
+	defer cancel()
+
+	if err := createSnapshot(requestCtx, log, hostname, r.Cluster.Spec.Settings.TLS.Required); err != nil {
+		log.Warn("failed to schedule snapshot", "replica_id", id, "error", err)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeWarning, v1.EventReasonSnapshotFailed,
+			v1.EventActionReconciling, "Failed to schedule snapshot on %q: %v", hostname, err)
+
+		return nil
+	}
+
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonSnapshotScheduled,
+		v1.EventActionReconciling, "Scheduled snapshot on %q to compact Raft logs", hostname)
+
+	ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationSnapshotRequestedAt,
+		time.Now().Format(time.RFC3339))
+
+	if err := r.Update(ctx, replica.StatefulSet, v1.EventActionReconciling); err != nil {
+		return fmt.Errorf("mark replica %q snapshot request: %w", id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package keeper
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controller"
+	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+var _ = Describe("ParseStorageStatus", func() {
+	It("should parse directory sizes", func() {
+		status, err := parseStorageStatus([]byte("snapshot_dir_size: 1024\nsnapshot_dir: /var/lib/clickhouse/coordination/snapshots\n" +
+			"log_dir_size: 2048\nlog_dir: /var/lib/clickhouse/coordination/logs\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(storageStatus{Known: true, SnapshotDirSize: 1024, LogDirSize: 2048}))
+		Expect(status.Used()).To(BeEquivalentTo(3072))
+	})
+
+	It("should fail on response without sizes", func() {
+		_, err := parseStorageStatus([]byte("dirs is not executed because it is not in the whitelist."))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail on invalid size", func() {
+		_, err := parseStorageStatus([]byte("snapshot_dir_size: many\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// fakeVolumeStats returns the preconfigured kubelet volume stats of every node.
+type fakeVolumeStats map[string]map[types.NamespacedName]controller.VolumeStats
+
+func (f fakeVolumeStats) GetVolumeStats(_ context.Context, nodeName string) (map[types.NamespacedName]controller.VolumeStats, error) {
+	stats, ok := f[nodeName]
+	if !ok {
+		return nil, errors.New("node is not reachable")
+	}
+
+	return stats, nil
+}
+
+var _ = Describe("ReconcileStorage", func() {
+	var (
+		ctx context.Context
+		log util.Logger
+		rec *keeperReconciler
+	)
+
+	pvcName := func(id v1.KeeperReplicaID) types.NamespacedName {
+		return types.NamespacedName{
+			Namespace: rec.Cluster.Namespace,
+			Name:      "data-" + rec.Cluster.StatefulSetNameByReplicaID(id) + "-0",
+		}
+	}
+
+	createPod := func(id v1.KeeperReplicaID, node string) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: rec.Cluster.Namespace,
+				Name:      rec.Cluster.StatefulSetNameByReplicaID(id) + "-0",
+				Labels:    map[string]string{util.LabelAppKey: rec.Cluster.SpecificName()},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+		Expect(rec.GetClient().Create(ctx, pod)).To(Succeed())
+	}
+
+	createPVC := func(id v1.KeeperReplicaID, capacity string) {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: rec.Cluster.Namespace,
+				Name:      "data-" + rec.Cluster.StatefulSetNameByReplicaID(id) + "-0",
+				Labels: map[string]string{
+					util.LabelAppKey:          rec.Cluster.SpecificName(),
+					util.LabelKeeperReplicaID: strconv.FormatInt(int64(id), 10),
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+			},
+		}
+		Expect(rec.GetClient().Create(ctx, pvc)).To(Succeed())
+	}
+
+	storageCondition := func() *metav1.Condition {
+		return meta.FindStatusCondition(rec.Cluster.Status.Conditions, string(v1.KeeperConditionTypeStorageHealthy))
+	}
+
+	BeforeEach(func() {
+		var cancelEvents context.CancelFunc
+		ctx = context.Background()
+		log, rec, cancelEvents = setupReconciler()
+		DeferCleanup(cancelEvents)
+
+		rec.Cluster.Spec.DataVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+		rec.Cluster.Spec.Storage.UsageThresholdPercent = 80
+		Expect(rec.GetClient().Create(ctx, rec.Cluster.DeepCopy())).To(Succeed())
+	})
+
+	It("should report unknown usage without data volume", func() {
+		rec.Cluster.Spec.DataVolumeClaimSpec = nil
+		rec.SetReplica(1, replicaState{Storage: storageStatus{Known: true, SnapshotDirSize: 1}})
+
+		_, err := rec.reconcileStorage(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storageCondition()).NotTo(BeNil())
+		Expect(storageCondition().Status).To(Equal(metav1.ConditionUnknown))
+	})
+
+	It("should report unknown usage if no replica responded", func() {
+		createPVC(1, "1Ki")
+		rec.SetReplica(1, replicaState{})
+
+		result, err := rec.reconcileStorage(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(result.RequeueAfter).To(Equal(storageCheckInterval))
+		Expect(storageCondition().Status).To(Equal(metav1.ConditionUnknown))
+		Expect(storageCondition().Reason).To(BeEquivalentTo(v1.KeeperConditionReasonStorageUsageUnknown))
+	})
+
+	It("should report healthy storage below threshold", func() {
+		createPVC(1, "1Ki")
+		createPVC(2, "1Ki")
+		rec.SetReplica(1, replicaState{Storage: storageStatus{Known: true, SnapshotDirSize: 256, LogDirSize: 256}})
+		rec.SetReplica(2, replicaState{})
+
+		_, err := rec.reconcileStorage(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storageCondition().Status).To(Equal(metav1.ConditionTrue))
+		Expect(storageCondition().Reason).To(BeEquivalentTo(v1.KeeperConditionReasonStorageUsageNormal))
+		Expect(storageCondition().Message).To(ContainSubstring("not available on replicas: [2]"))
+	})
+
+	It("should report high usage above threshold", func() {
+		createPVC(1, "1Ki")
+		createPVC(2, "1Ki")
+		rec.SetReplica(1, replicaState{Storage: storageStatus{Known: true, SnapshotDirSize: 256, LogDirSize: 256}})
+		rec.SetReplica(2, replicaState{Storage: storageStatus{Known: true, SnapshotDirSize: 512, LogDirSize: 512}})
+
+		_, err := rec.reconcileStorage(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storageCondition().Status).To(Equal(metav1.ConditionFalse))
+		Expect(storageCondition().Reason).To(BeEquivalentTo(v1.KeeperConditionReasonStorageUsageHigh))
+		Expect(storageCondition().Message).To(ContainSubstring("2: 100%"))
+		Expect(storageCondition().Message).NotTo(ContainSubstring("1: 50%"))
+	})
+
+	It("should prefer kubelet volume stats over directory sizes", func() {
+		createPVC(1, "1Ki")
+		createPVC(2, "1Ki")
+		createPod(1, "node-a")
+		createPod(2, "node-b")
+		rec.VolumeStats = fakeVolumeStats{
+			"node-a": {pvcName(1): {UsedBytes: 900, CapacityBytes: 1000}},
+		}
+		rec.SetReplica(1, replicaState{Storage: storageStatus{Known: true, SnapshotDirSize: 100, LogDirSize: 100}})
+		rec.SetReplica(2, replicaState{Storage: storageStatus{Known: true, SnapshotDirSize: 100, LogDirSize: 100}})
+
+		_, err := rec.reconcileStorage(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storageCondition().Status).To(Equal(metav1.ConditionFalse))
+		Expect(storageCondition().Message).To(ContainSubstring("1: 90% (900 bytes used"))
+		Expect(storageCondition().Message).NotTo(ContainSubstring("2: "))
+	})
+
+	It("should report approximate data size of the leader", func() {
+		createPVC(1, "1Ki")
+		rec.SetReplica(1, replicaState{Status: serverStatus{ServerState: ModeFollower, ApproximateDataSize: 1024}})
+		rec.SetReplica(2, replicaState{Status: serverStatus{ServerState: ModeLeader, ApproximateDataSize: 2048}})
+
+		_, err := rec.reconcileStorage(ctx, log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.Cluster.Status.ApproximateDataSize).To(BeEquivalentTo(2048))
+	})
+})
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
-	"math"
 	"slices"
 	"strconv"
 	"strings"
@@ -20,6 +19,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
@@ -28,8 +28,13 @@ import (
 )
 
 type replicaState struct {
-	Error       bool `json:"error"`
-	Status      serverStatus
+	Error   bool `json:"error"`
+	Status  serverStatus
+	Storage storageStatus
+	// Zone of the replica pod node, loaded only if the preferred leader zone is set.
+	Zone string
+	// Role of the replica in the quorum, persisted in the StatefulSet annotation.
+	Role        quorumRole
 	StatefulSet *appsv1.StatefulSet
 }
 
@@ -71,6 +76,21 @@ func (r replicaState) HasConfigMapDiff(rec *keeperReconciler) bool {
 	return ctrlutil.GetConfigHashFromObject(r.StatefulSet) != rec.Cluster.Status.ConfigurationRevision
 }
 
+// NeedsRestart reports whether the replica update restarts its Pod.
+func (r replicaState) NeedsRestart(rec *keeperReconciler) bool {
+	if r.StatefulSet == nil || r.HasStatefulSetDiff(rec) {
+		return true
+	}
+
+	if !r.HasConfigMapDiff(rec) {
+		return false
+	}
+
+	restartHash := r.StatefulSet.Annotations[ctrlutil.AnnotationRestartConfigHash]
+
+	return restartHash == "" || restartHash != rec.RestartRevision
+}
+
 func (r replicaState) UpdateStage(rec *keeperReconciler) chctrl.ReplicaUpdateStage {
 	if r.StatefulSet == nil {
 		return chctrl.StageNotExists
@@ -102,8 +122,22 @@ type keeperReconciler struct {
 
 	// Should be populated after reconcileClusterRevisions with parsed extra config.
 	ExtraConfig map[string]any
+	// Computed by reconcileClusterRevisions, changes only if replicas need restart to apply the configuration.
+	RestartRevision string
 	// Computed by reconcileActiveReplicaStatus
 	HorizontalScaleAllowed bool
+	// Computed by reconcileQuorumRecovery, replicas must not be restarted while the quorum is recovered.
+	RecoveryInProgress bool
+	// Computed by reconcileBootstrap, other replicas are created only after the ZooKeeper data import is verified.
+	BootstrapPending bool
+	// Computed by reconcileQuorumReconfiguration if dynamic reconfiguration is enabled.
+	QuorumMembers map[v1.KeeperReplicaID]quorumMemberState
+	// Namespace of the operator, allowed to access Keeper client ports.
+	OperatorNamespace string
+	// Uncached reader for the objects the controller does not watch.
+	APIReader client.Reader
+	// Reads the data volume usage reported by kubelet. Storage usage falls back to the `dirs` command if not set.
+	VolumeStats chctrl.VolumeStatsReader
 }
 type reconcileFunc func(context.Context, ctrlutil.Logger) (*ctrl.Result, error)
 
@@ -113,10 +147,16 @@ func (r *keeperReconciler) sync(ctx context.Context, log ctrlutil.Logger) (ctrl.
 	reconcileSteps := []reconcileFunc{
 		r.reconcileClusterRevisions,
 		r.reconcileActiveReplicaStatus,
+		r.reconcileQuorumRecovery,
+		r.reconcileBootstrap,
 		r.reconcileQuorumMembership,
+		r.reconcileReplicaRoles,
 		r.reconcileCommonResources,
 		r.reconcileReplicaResources,
+		r.reconcileQuorumReconfiguration,
 		r.reconcileCleanUp,
+		r.reconcileStorage,
+		r.reconcileLeaderPlacement,
 		r.reconcileConditions,
 	}
 
@@ -126,7 +166,10 @@ func (r *keeperReconciler) sync(ctx context.Context, log ctrlutil.Logger) (ctrl.
 		stepLog := log.With("reconcile_step", funcName)
 		stepLog.Debug("starting reconcile step")
 
+		stepStart := time.Now()
 		stepResult, err := fn(ctx, stepLog)
+		chctrl.ObserveReconcileStep(chctrl.MetricsControllerKeeper, funcName, time.Since(stepStart), err)
+
 		if err != nil {
 			if k8serrors.IsConflict(err) {
 				stepLog.Error(err, "update conflict for resource, reschedule to retry")
@@ -152,6 +195,8 @@ func (r *keeperReconciler) sync(ctx context.Context, log ctrlutil.Logger) (ctrl.
 				r.NewCondition(v1.ConditionTypeConfigurationInSync, metav1.ConditionUnknown, v1.ConditionReasonStepFailed, errMsg),
 				r.NewCondition(v1.ConditionTypeClusterSizeAligned, metav1.ConditionUnknown, v1.ConditionReasonStepFailed, errMsg),
 				r.NewCondition(v1.KeeperConditionTypeScaleAllowed, metav1.ConditionUnknown, v1.ConditionReasonStepFailed, errMsg),
+				r.NewCondition(v1.KeeperConditionTypeStorageHealthy, metav1.ConditionUnknown, v1.ConditionReasonStepFailed, errMsg),
+				r.NewCondition(v1.KeeperConditionTypeLeaderInPreferredZone, metav1.ConditionUnknown, v1.ConditionReasonStepFailed, errMsg),
 			})
 
 			if updateErr := r.UpsertStatus(ctx, log); updateErr != nil {
@@ -214,6 +259,11 @@ func (r *keeperReconciler) reconcileClusterRevisions(_ context.Context, log ctrl
 		log.Debug(fmt.Sprintf("observed new configuration revision %q", configRevision))
 	}
 
+	r.RestartRevision, err = getRestartRevision(r.Cluster, r.ExtraConfig)
+	if err != nil {
+		return nil, fmt.Errorf("get restart revision: %w", err)
+	}
+
 	stsRevision, err := getStatefulSetRevision(r.Cluster)
 	if err != nil {
 		return nil, fmt.Errorf("get StatefulSet revision: %w", err)
@@ -228,7 +278,7 @@ func (r *keeperReconciler) reconcileClusterRevisions(_ context.Context, log ctrl
 }
 
 func (r *keeperReconciler) reconcileActiveReplicaStatus(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
-	if r.Cluster.Replicas() == 0 {
+	if r.Cluster.TotalReplicas() == 0 {
 		log.Debug("keeper replicaState count is zero")
 		return nil, nil
 	}
@@ -258,12 +308,30 @@ func (r *keeperReconciler) reconcileActiveReplicaStatus(ctx context.Context, log
 
 		status := getServerStatus(ctx, log.With("replica_id", id), r.Cluster.HostnameByID(id), tlsRequired)
 
+		var storage storageStatus
+		if status.ServerState != "" {
+			storage = getStorageStatus(ctx, log.With("replica_id", id), r.Cluster.HostnameByID(id), tlsRequired)
+		}
+
+		var zone string
+		if r.Cluster.Spec.LeaderPlacement.PreferredZone != "" {
+			zone = r.getReplicaZone(ctx, log.With("replica_id", id), &sts)
+		}
+
+		var role quorumRole
+		if value := quorumRole(sts.Annotations[ctrlutil.AnnotationKeeperRole]); value == roleParticipant || value == roleLearner {
+			role = value
+		}
+
 		log.Debug("load replica state done", "replica_id", id, "statefulset", sts.Name)
 
 		return id, replicaState{
 			StatefulSet: &sts,
 			Error:       hasError,
 			Status:      status,
+			Storage:     storage,
+			Zone:        zone,
+			Role:        role,
 		}, nil
 	})
 	for id, res := range execResults {
@@ -279,7 +347,7 @@ func (r *keeperReconciler) reconcileActiveReplicaStatus(ctx context.Context, log
 	}
 
 	// If replica existed before we need to mark it active as quorum expects it.
-	if len(r.ReplicaState) > 0 && len(r.ReplicaState) < int(r.Cluster.Replicas()) {
+	if len(r.ReplicaState) > 0 && len(r.ReplicaState) < int(r.Cluster.TotalReplicas()) {
 		quorumReplicas, err := r.loadQuorumReplicas(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("load quorum replicas: %w", err)
@@ -297,6 +365,10 @@ func (r *keeperReconciler) reconcileActiveReplicaStatus(ctx context.Context, log
 		}
 	}
 
+	// Replicas created before the roles were persisted get the role by the ID order.
+	r.assignReplicaRoles()
+	r.observeLeaderMetrics()
+
 	if err := r.checkHorizontalScalingAllowed(ctx, log); err != nil {
 		return nil, err
 	}
@@ -309,7 +381,7 @@ func (r *keeperReconciler) reconcileActiveReplicaStatus(ctx context.Context, log
 }
 
 func (r *keeperReconciler) reconcileQuorumMembership(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
-	requestedReplicas := int(r.Cluster.Replicas())
+	requestedReplicas := int(r.Cluster.TotalReplicas())
 	activeReplicas := len(r.ReplicaState)
 
 	if activeReplicas == requestedReplicas {
@@ -325,6 +397,18 @@ func (r *keeperReconciler) reconcileQuorumMembership(ctx context.Context, log ct
 		return nil, nil
 	}
 
+	// New cluster bootstrap, creates the replica importing the ZooKeeper data.
+	if requestedReplicas > 0 && activeReplicas == 0 && r.BootstrapPending {
+		log.Info("creating bootstrap replica", "replica_id", bootstrapReplicaID)
+		r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonBootstrapStarted, v1.EventActionBootstrapping,
+			"Initial cluster creation, importing ZooKeeper data into replica %q", r.Cluster.HostnameByID(bootstrapReplicaID))
+		r.SetCondition(log, r.NewCondition(v1.ConditionTypeClusterSizeAligned, metav1.ConditionFalse, v1.ConditionReasonScalingUp,
+			"Waiting for the ZooKeeper data import into the first replica"))
+		r.SetReplica(bootstrapReplicaID, replicaState{})
+
+		return nil, nil
+	}
+
 	// New cluster creation, creates all replicas.
 	if requestedReplicas > 0 && activeReplicas == 0 {
 		log.Debug("creating all replicas")
@@ -382,6 +466,11 @@ func (r *keeperReconciler) reconcileQuorumMembership(ctx context.Context, log ct
 		return &reconcile.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
 	}
 
+	if r.BootstrapPending {
+		log.Info("Delaying horizontal scaling until the ZooKeeper data import is verified")
+		return &reconcile.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+
 	// Add single replica in quorum, allocating the first free id.
 	if activeReplicas < requestedReplicas {
 		for id := v1.KeeperReplicaID(1); ; id++ {
@@ -396,13 +485,18 @@ func (r *keeperReconciler) reconcileQuorumMembership(ctx context.Context, log ct
 		}
 	}
 
-	// Remove single replica from the quorum. Prefer bigger id.
+	// Remove single replica from the quorum. Prefer the role with more replicas than requested and bigger id.
 	if activeReplicas > requestedReplicas {
+		voters, learners := r.replicasByRole()
+
+		candidates := voters
+		if len(learners) > int(r.Cluster.Learners()) || len(voters) == 0 {
+			candidates = learners
+		}
+
 		chosenIndex := v1.KeeperReplicaID(-1)
-		for id := range r.ReplicaState {
-			if chosenIndex == -1 || chosenIndex < id {
-				chosenIndex = id
-			}
+		if len(candidates) > 0 {
+			chosenIndex = slices.Max(candidates)
 		}
 
 		if chosenIndex == -1 {
@@ -426,11 +520,26 @@ func (r *keeperReconciler) reconcileCommonResources(ctx context.Context, log ctr
 		return nil, fmt.Errorf("reconcile service resource: %w", err)
 	}
 
-	pdb := templatePodDisruptionBudget(r.Cluster)
+	pdb := templatePodDisruptionBudget(r.Cluster, r.votingReplicas())
 	if _, err := r.ReconcilePodDisruptionBudget(ctx, log, pdb, v1.EventActionReconciling); err != nil {
 		return nil, fmt.Errorf("reconcile PodDisruptionBudget resource: %w", err)
 	}
 
+	podMonitor := templatePodMonitor(r.Cluster)
+	if _, err := r.ReconcilePodMonitor(ctx, log, podMonitor, r.Cluster.Spec.Monitoring.PodMonitor.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile PodMonitor resource: %w", err)
+	}
+
+	prometheusRule := templatePrometheusRule(r.Cluster)
+	if _, err := r.ReconcilePrometheusRule(ctx, log, prometheusRule, r.Cluster.Spec.Monitoring.Alerts.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile PrometheusRule resource: %w", err)
+	}
+
+	networkPolicy := templateNetworkPolicy(r.Cluster, r.OperatorNamespace)
+	if _, err := r.ReconcileNetworkPolicy(ctx, log, networkPolicy, r.Cluster.Spec.NetworkPolicy.Enabled, v1.EventActionReconciling); err != nil {
+		return nil, fmt.Errorf("reconcile NetworkPolicy resource: %w", err)
+	}
+
 	configMap, err := templateQuorumConfig(r)
 	if err != nil {
 		return nil, fmt.Errorf("template quorum config: %w", err)
@@ -452,8 +561,11 @@ func (r *keeperReconciler) reconcileReplicaResources(ctx context.Context, log ct
 
 	var replicasInStatus []v1.KeeperReplicaID
 
+	stageCounts := map[chctrl.ReplicaUpdateStage]int{}
 	for id, state := range r.ReplicaState {
 		stage := state.UpdateStage(r)
+		stageCounts[stage]++
+
 		if stage == highestStage {
 			replicasInStatus = append(replicasInStatus, id)
 			continue
@@ -465,24 +577,35 @@ func (r *keeperReconciler) reconcileReplicaResources(ctx context.Context, log ct
 		}
 	}
 
+	chctrl.SetReplicaStageCounts(chctrl.MetricsControllerKeeper, r.Cluster.NamespacedName(), stageCounts)
+
 	result := ctrl.Result{}
 
 	switch highestStage {
 	case chctrl.StageUpToDate:
 		log.Info("all replicas are up to date")
 		return nil, nil
-	case chctrl.StageNotReadyUpToDate, chctrl.StageUpdating:
+	case chctrl.StageCatchingUp, chctrl.StageNotReadyUpToDate, chctrl.StageUpdating:
 		log.Info("waiting for updated replicas to become ready", "replicas", replicasInStatus, "priority", highestStage.String())
 
 		result = ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}
 	case chctrl.StageHasDiff:
+		if r.RecoveryInProgress {
+			log.Info("postponing rolling update until the quorum is recovered", "replicas", replicasInStatus)
+			return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+		}
+
 		// Leave one replica to rolling update. replicasInStatus must not be empty.
-		// Prefer replicas with higher id.
-		chosenReplica := replicasInStatus[0]
-		for _, id := range replicasInStatus {
-			if id > chosenReplica {
-				chosenReplica = id
-			}
+		// Prefer followers and replicas with higher id, the leader hands off the leadership before restart.
+		chosenReplica := r.chooseReplicaToUpdate(replicasInStatus)
+
+		handoffResult, err := r.handoffLeadership(ctx, log, chosenReplica)
+		if err != nil {
+			return nil, fmt.Errorf("hand off replica %q leadership: %w", chosenReplica, err)
+		}
+
+		if handoffResult != nil {
+			return handoffResult, nil
 		}
 
 		log.Info(fmt.Sprintf("updating chosen replica %d with priority %s: %v", chosenReplica, highestStage.String(), replicasInStatus))
@@ -504,6 +627,70 @@ func (r *keeperReconciler) reconcileReplicaResources(ctx context.Context, log ct
 	return &result, nil
 }
 
+// reconcileQuorumReconfiguration applies quorum membership changes with the Keeper `reconfig` command
+// and verifies them using the "/keeper/config" node.
+func (r *keeperReconciler) reconcileQuorumReconfiguration(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
+	r.QuorumMembers = nil
+
+	if !r.Cluster.Spec.Settings.DynamicReconfiguration || len(r.ReplicaState) == 0 {
+		return nil, nil
+	}
+
+	var hostnames []string
+	for id, replica := range r.ReplicaState {
+		if replica.Status.ServerState != "" {
+			hostnames = append(hostnames, r.Cluster.HostnameByID(id))
+		}
+	}
+
+	if len(hostnames) == 0 {
+		log.Info("no running replicas to read the quorum configuration from")
+		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+
+	slices.Sort(hostnames)
+
+	conn, err := Connect(ctx, log, r.Cluster, hostnames...)
+	if err != nil {
+		log.Info("failed to connect to keeper to check quorum configuration", "error", err)
+		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+	defer conn.Close()
+
+	members, err := getQuorumMembers(conn)
+	if err != nil {
+		return nil, fmt.Errorf("get quorum members: %w", err)
+	}
+
+	joining, leaving := r.planQuorumReconfig(members)
+	if len(joining) == 0 && len(leaving) == 0 {
+		r.QuorumMembers = members
+		return nil, nil
+	}
+
+	log.Info("reconfiguring keeper quorum", "joining", joining, "leaving", leaving)
+
+	if _, err := conn.IncrementalReconfig(joining, leaving, -1); err != nil {
+		return nil, fmt.Errorf("reconfigure quorum: joining %v, leaving %v: %w", joining, leaving, err)
+	}
+
+	r.GetRecorder().Eventf(r.Cluster, nil, corev1.EventTypeNormal, v1.EventReasonQuorumReconfigured, v1.EventActionScaling,
+		"Quorum reconfigured: joining %v, leaving %v", joining, leaving)
+
+	if members, err = getQuorumMembers(conn); err != nil {
+		return nil, fmt.Errorf("verify quorum members: %w", err)
+	}
+
+	r.QuorumMembers = members
+
+	if joining, leaving = r.planQuorumReconfig(members); len(joining) > 0 || len(leaving) > 0 {
+		log.Info("quorum configuration is not applied yet", "joining", joining, "leaving", leaving)
+		return &ctrl.Result{RequeueAfter: chctrl.RequeueOnRefreshTimeout}, nil
+	}
+
+	return nil, nil
+}
+
 func (r *keeperReconciler) reconcileCleanUp(ctx context.Context, log ctrlutil.Logger) (*ctrl.Result, error) {
 	listOpts := ctrlutil.AppRequirements(r.Cluster.Namespace, r.Cluster.SpecificName())
 
@@ -523,7 +710,7 @@ func (r *keeperReconciler) reconcileCleanUp(ctx context.Context, log ctrlutil.Lo
 			continue
 		}
 
-		if _, ok := r.ReplicaState[id]; !ok {
+		if _, ok := r.ReplicaState[id]; !ok && r.leftQuorum(id) {
 			log.Info("deleting stale ConfigMap", "replica_id", id, "configmap", configMap.Name)
 
 			if err := r.Delete(ctx, &configMap, v1.EventActionReconciling); err != nil {
@@ -545,6 +732,11 @@ func (r *keeperReconciler) reconcileCleanUp(ctx context.Context, log ctrlutil.Lo
 		}
 
 		if _, ok := r.ReplicaState[id]; !ok {
+			if !r.leftQuorum(id) {
+				log.Info("waiting for replica to leave the quorum before deleting", "replica_id", id, "statefuleset", sts.Name)
+				continue
+			}
+
 			log.Info("deleting stale StatefulSet", "replica_id", id, "statefuleset", sts.Name)
 
 			if err := r.Delete(ctx, &sts, v1.EventActionReconciling); err != nil {
@@ -565,17 +757,28 @@ func (r *keeperReconciler) reconcileConditions(ctx context.Context, log ctrlutil
 
 	replicasByMode := map[string][]v1.KeeperReplicaID{}
 
+	voters, votingFollowers := 0, 0
+
 	r.Cluster.Status.ReadyReplicas = 0
 	for id, replica := range r.ReplicaState {
 		if replica.Error {
 			errorReplicas = append(errorReplicas, id)
 		}
 
+		learner := r.isLearner(id)
+		if !learner {
+			voters++
+		}
+
 		if !replica.Ready(r) {
 			notReadyReplicas = append(notReadyReplicas, id)
 		} else {
 			r.Cluster.Status.ReadyReplicas++
 			replicasByMode[replica.Status.ServerState] = append(replicasByMode[replica.Status.ServerState], id)
+
+			if replica.Status.ServerState == ModeFollower && !learner {
+				votingFollowers++
+			}
 		}
 
 		if replica.HasConfigMapDiff(r) || replica.HasStatefulSetDiff(r) || !replica.Updated() {
@@ -608,7 +811,7 @@ func (r *keeperReconciler) reconcileConditions(ctx context.Context, log ctrlutil
 	}
 
 	exists := len(r.ReplicaState)
-	expected := int(r.Cluster.Replicas())
+	expected := int(r.Cluster.TotalReplicas())
 
 	if len(notUpdatedReplicas) == 0 && exists == expected {
 		r.Cluster.Status.CurrentRevision = r.Cluster.Status.UpdateRevision
@@ -643,7 +846,9 @@ func (r *keeperReconciler) reconcileConditions(ctx context.Context, log ctrlutil
 		}
 
 	default:
-		requiredFollowersForQuorum := int(math.Ceil(float64(exists)/2)) - 1
+		// Learners do not vote, so they are not counted in the quorum. The leader with its followers must form
+		// a strict majority of the voters, the number of voters may be even with learners.
+		requiredFollowersForQuorum := voters / 2
 
 		switch {
 		case len(replicasByMode[ModeStandalone]) > 0:
@@ -664,10 +869,10 @@ func (r *keeperReconciler) reconcileConditions(ctx context.Context, log ctrlutil
 			status = metav1.ConditionFalse
 			reason = v1.KeeperConditionReasonNoLeader
 			message = "No leader in the cluster"
-		case len(replicasByMode[ModeFollower]) < requiredFollowersForQuorum:
+		case votingFollowers < requiredFollowersForQuorum:
 			status = metav1.ConditionFalse
 			reason = v1.KeeperConditionReasonNotEnoughFollowers
-			message = fmt.Sprintf("Not enough followers in cluster: %d/%d", len(replicasByMode[ModeFollower]), requiredFollowersForQuorum)
+			message = fmt.Sprintf("Not enough followers in cluster: %d/%d", votingFollowers, requiredFollowersForQuorum)
 		default:
 			status = metav1.ConditionTrue
 			reason = v1.KeeperConditionReasonClusterReady
@@ -715,7 +920,9 @@ func (r *keeperReconciler) updateReplica(ctx context.Context, log ctrlutil.Logge
 	if replica.StatefulSet == nil {
 		log.Info("replica StatefulSet not found, creating", "stateful_set", statefulSet.Name)
 		ctrlutil.AddObjectConfigHash(statefulSet, r.Cluster.Status.ConfigurationRevision)
+		ctrlutil.AddHashWithKeyToAnnotations(statefulSet, ctrlutil.AnnotationRestartConfigHash, r.RestartRevision)
 		ctrlutil.AddHashWithKeyToAnnotations(statefulSet, ctrlutil.AnnotationSpecHash, r.Cluster.Status.StatefulSetRevision)
+		ctrlutil.AddHashWithKeyToAnnotations(statefulSet, ctrlutil.AnnotationKeeperRole, string(r.quorumRole(replicaID)))
 
 		if err := r.Create(ctx, statefulSet, v1.EventActionReconciling); err != nil {
 			return nil, fmt.Errorf("create replica %q: %w", replicaID, err)
@@ -738,19 +945,28 @@ func (r *keeperReconciler) updateReplica(ctx context.Context, log ctrlutil.Logge
 
 	stsNeedsUpdate := replica.HasStatefulSetDiff(r)
 
+	restartedAt, hasRestartedAt := replica.StatefulSet.Spec.Template.Annotations[ctrlutil.AnnotationRestartedAt]
+
 	// Trigger Pod restart if config changed
 	if replica.HasConfigMapDiff(r) {
-		// Use same way as Kubernetes for force restarting Pods one by one
-		// (https://github.com/kubernetes/kubernetes/blob/22a21f974f5c0798a611987405135ab7e62502da/staging/src/k8s.io/kubectl/pkg/polymorphichelpers/objectrestarter.go#L41)
-		// Not included by default in the StatefulSet so that hash-diffs work correctly
-		log.Info("forcing keeper Pod restart, because of config changes")
+		if replica.NeedsRestart(r) {
+			// Use same way as Kubernetes for force restarting Pods one by one
+			// (https://github.com/kubernetes/kubernetes/blob/22a21f974f5c0798a611987405135ab7e62502da/staging/src/k8s.io/kubectl/pkg/polymorphichelpers/objectrestarter.go#L41)
+			// Not included by default in the StatefulSet so that hash-diffs work correctly
+			log.Info("forcing keeper Pod restart, because of config changes")
 
-		statefulSet.Spec.Template.Annotations[ctrlutil.AnnotationRestartedAt] = time.Now().Format(time.RFC3339)
+			restartedAt, hasRestartedAt = time.Now().Format(time.RFC3339), true
+		} else {
+			log.Info("only reloadable settings changed, keeper reloads config without restart")
+		}
 
 		ctrlutil.AddObjectConfigHash(replica.StatefulSet, r.Cluster.Status.ConfigurationRevision)
+		ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationRestartConfigHash, r.RestartRevision)
 
 		stsNeedsUpdate = true
-	} else if restartedAt, ok := replica.StatefulSet.Spec.Template.Annotations[ctrlutil.AnnotationRestartedAt]; ok {
+	}
+
+	if hasRestartedAt {
 		statefulSet.Spec.Template.Annotations[ctrlutil.AnnotationRestartedAt] = restartedAt
 	}
 
@@ -780,6 +996,7 @@ func (r *keeperReconciler) updateReplica(ctx context.Context, log ctrlutil.Logge
 	replica.StatefulSet.Annotations = ctrlutil.MergeMaps(replica.StatefulSet.Annotations, statefulSet.Annotations)
 	replica.StatefulSet.Labels = ctrlutil.MergeMaps(replica.StatefulSet.Labels, statefulSet.Labels)
 	ctrlutil.AddHashWithKeyToAnnotations(replica.StatefulSet, ctrlutil.AnnotationSpecHash, r.Cluster.Status.StatefulSetRevision)
+	delete(replica.StatefulSet.Annotations, ctrlutil.AnnotationLeadershipTransferAt)
 
 	if err := r.Update(ctx, replica.StatefulSet, v1.EventActionReconciling); err != nil {
 		return nil, fmt.Errorf("update replica %q: %w", replicaID, err)
@@ -827,6 +1044,21 @@ func (r *keeperReconciler) loadQuorumReplicas(ctx context.Context) (map[v1.Keepe
 	return replicas, nil
 }
 
+// observeLeaderMetrics reports the current leader and its followers to the operator metrics.
+func (r *keeperReconciler) observeLeaderMetrics() {
+	leader := ""
+	followers := 0
+
+	for id, replica := range r.ReplicaState {
+		if replica.Status.ServerState == ModeLeader || replica.Status.ServerState == ModeStandalone {
+			leader = strconv.FormatInt(int64(id), 10)
+			followers = replica.Status.Followers
+		}
+	}
+
+	chctrl.ObserveKeeperLeader(r.Cluster.NamespacedName(), leader, followers)
+}
+
 func (r *keeperReconciler) checkHorizontalScalingAllowed(ctx context.Context, log ctrlutil.Logger) error {
 	var leader v1.KeeperReplicaID = -1
 
@@ -864,14 +1096,19 @@ func (r *keeperReconciler) checkHorizontalScalingAllowed(ctx context.Context, lo
 
 	updatedReplicas := 0
 
-	readyReplicas := 0
+	// Unavailable learners do not affect the quorum, only voting replicas must be ready.
+	voters, readyVoters := 0, 0
 	for id, replica := range r.ReplicaState {
 		if !replica.HasConfigMapDiff(r) && !replica.HasStatefulSetDiff(r) {
 			updatedReplicas++
 		}
 
-		if replica.Ready(r) {
-			readyReplicas++
+		if !r.isLearner(id) {
+			voters++
+
+			if replica.Ready(r) {
+				readyVoters++
+			}
 		}
 
 		if replica.Status.ServerState == leaderMode {
@@ -906,9 +1143,9 @@ func (r *keeperReconciler) checkHorizontalScalingAllowed(ctx context.Context, lo
 			"Waiting for %d/%d to be updated", updatedReplicas, activeReplicas)
 	}
 
-	if readyReplicas != activeReplicas {
+	if readyVoters != voters {
 		return scaleBlocked(v1.KeeperConditionReasonReplicaNotReady,
-			"Waiting for %d/%d to be Ready", readyReplicas, activeReplicas)
+			"Waiting for %d/%d voting replicas to be Ready", readyVoters, voters)
 	}
 
 	r.HorizontalScaleAllowed = true
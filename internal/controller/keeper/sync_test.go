@@ -105,6 +105,36 @@ var _ = Describe("UpdateReplica", Ordered, func() {
 		sts = mustGet[*appsv1.StatefulSet](ctx, rec.GetClient(), stsKey)
 		Expect(sts.Spec.Template.Annotations[util.AnnotationRestartedAt]).ToNot(BeEmpty())
 	})
+
+	It("should not restart server on reloadable config changes", func(ctx context.Context) {
+		sts := mustGet[*appsv1.StatefulSet](ctx, rec.GetClient(), stsKey)
+		restartedAt := sts.Spec.Template.Annotations[util.AnnotationRestartedAt]
+		sts.Status.ObservedGeneration = sts.Generation
+		sts.Annotations[util.AnnotationRestartConfigHash] = "restart-v1"
+		rec.ReplicaState[replicaID] = replicaState{StatefulSet: sts}
+		rec.RestartRevision = "restart-v1"
+
+		rec.Cluster.Status.ConfigurationRevision = "cfg-v3"
+		result, err := rec.reconcileReplicaResources(ctx, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.IsZero()).To(BeFalse())
+
+		sts = mustGet[*appsv1.StatefulSet](ctx, rec.GetClient(), stsKey)
+		Expect(sts.Spec.Template.Annotations[util.AnnotationRestartedAt]).To(Equal(restartedAt))
+		Expect(util.GetConfigHashFromObject(sts)).To(Equal("cfg-v3"))
+
+		rec.ReplicaState[replicaID] = replicaState{StatefulSet: sts}
+		rec.RestartRevision = "restart-v2"
+		rec.Cluster.Status.ConfigurationRevision = "cfg-v4"
+		restartedAt = "2000-01-01T00:00:00Z"
+		sts.Spec.Template.Annotations[util.AnnotationRestartedAt] = restartedAt
+		_, err = rec.reconcileReplicaResources(ctx, log)
+		Expect(err).ToNot(HaveOccurred())
+
+		sts = mustGet[*appsv1.StatefulSet](ctx, rec.GetClient(), stsKey)
+		Expect(sts.Spec.Template.Annotations[util.AnnotationRestartedAt]).NotTo(Equal(restartedAt))
+		Expect(sts.Annotations[util.AnnotationRestartConfigHash]).To(Equal("restart-v2"))
+	})
 })
 
 func mustGet[T client.Object](ctx context.Context, c client.Client, key types.NamespacedName) T {
@@ -125,7 +155,7 @@ func setupReconciler() (util.Logger, *keeperReconciler, context.CancelFunc) {
 	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
 	Expect(v1.AddToScheme(scheme)).To(Succeed())
 
-	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&v1.KeeperCluster{}).Build()
 	eventRecorder := events.NewFakeRecorder(32)
 	logger := util.NewLogger(zap.NewRaw(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
 
@@ -155,6 +185,7 @@ func setupReconciler() (util.Logger, *keeperReconciler, context.CancelFunc) {
 				},
 			}),
 		ExtraConfig: map[string]any{},
+		APIReader:   fakeClient,
 	}
 
 	eventContext, cancel := context.WithCancel(context.Background())
@@ -9,9 +9,11 @@ import (
 	"strings"
 
 	"dario.cat/mergo"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -23,6 +25,38 @@ import (
 	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
+func templateNetworkPolicy(cr *v1.KeeperCluster, operatorNamespace string) *networkingv1.NetworkPolicy {
+	rules := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: controller.NetworkPolicyPorts(PortInterserver),
+			From:  []networkingv1.NetworkPolicyPeer{controller.AppPeer(cr.SpecificName())},
+		},
+		{
+			Ports: controller.NetworkPolicyPorts(PortPrometheusScrape, PortHTTPControl),
+		},
+	}
+
+	// ClickHouse clusters in the Keeper cluster namespace create their own policies to access client ports.
+	// ClickHouse clusters from other namespaces can't do that, so the allowed namespaces are granted access here.
+	clientPeers := controller.NamespacePeers(operatorNamespace)
+	clientPeers = append(clientPeers, controller.RolePeers(controllerutil.LabelClickHouseValue, cr.Spec.AllowedNamespaces)...)
+	if len(clientPeers) > 0 {
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{
+			Ports: controller.NetworkPolicyPorts(PortNative, PortNativeSecure),
+			From:  clientPeers,
+		})
+	}
+
+	return controller.TemplateNetworkPolicy(metav1.ObjectMeta{
+		Name:      cr.SpecificName(),
+		Namespace: cr.Namespace,
+		Labels: controllerutil.MergeMaps(cr.Spec.Labels, map[string]string{
+			controllerutil.LabelAppKey: cr.SpecificName(),
+		}),
+		Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+	}, cr.SpecificName(), rules)
+}
+
 func templateHeadlessService(cr *v1.KeeperCluster) *corev1.Service {
 	ports := []corev1.ServicePort{
 		{
@@ -76,8 +110,10 @@ func templateHeadlessService(cr *v1.KeeperCluster) *corev1.Service {
 	}
 }
 
-func templatePodDisruptionBudget(cr *v1.KeeperCluster) *policyv1.PodDisruptionBudget {
-	maxUnavailable := intstr.FromInt32(cr.Replicas() / 2)
+// templatePodDisruptionBudget keeps the quorum of the voting replicas available. Learners do not vote,
+// but share the budget, as the pods are selected by the cluster label.
+func templatePodDisruptionBudget(cr *v1.KeeperCluster, voters int32) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt32(voters / 2)
 
 	return &policyv1.PodDisruptionBudget{
 		TypeMeta: metav1.TypeMeta{
@@ -103,12 +139,79 @@ func templatePodDisruptionBudget(cr *v1.KeeperCluster) *policyv1.PodDisruptionBu
 	}
 }
 
+func templatePodMonitor(cr *v1.KeeperCluster) *monitoringv1.PodMonitor {
+	spec := cr.Spec.Monitoring.PodMonitor
+
+	return &monitoringv1.PodMonitor{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       monitoringv1.PodMonitorsKind,
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.SpecificName(),
+			Namespace: cr.Namespace,
+			Labels: controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, map[string]string{
+				controllerutil.LabelAppKey: cr.SpecificName(),
+			}),
+			Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+		},
+		Spec: monitoringv1.PodMonitorSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					controllerutil.LabelAppKey: cr.SpecificName(),
+				},
+			},
+			PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{
+				controller.TemplatePodMetricsEndpoint(spec, []monitoringv1.RelabelConfig{
+					controller.PodLabelRelabeling(controllerutil.LabelKeeperReplicaID, "replica"),
+				}),
+			},
+		},
+	}
+}
+
+func templatePrometheusRule(cr *v1.KeeperCluster) *monitoringv1.PrometheusRule {
+	spec := cr.Spec.Monitoring.Alerts
+	selector := controller.PodMetricsSelector(cr.Namespace, cr.SpecificName()+"-[0-9]+-0")
+
+	return controller.TemplatePrometheusRule(metav1.ObjectMeta{
+		Name:      cr.SpecificName(),
+		Namespace: cr.Namespace,
+		Labels: controllerutil.MergeMaps(cr.Spec.Labels, spec.Labels, map[string]string{
+			controllerutil.LabelAppKey: cr.SpecificName(),
+		}),
+		Annotations: controllerutil.MergeMaps(cr.Spec.Annotations),
+	}, spec.AlertsSpec, cr.SpecificName()+".rules", []controller.AlertRule{
+		{
+			Name:     "KeeperHighLatency",
+			Expr:     fmt.Sprintf("max by (namespace, pod) (ClickHouseAsyncMetrics_KeeperAvgLatency{%s}) > %d", selector, spec.KeeperLatencyMilliseconds),
+			Severity: controller.AlertSeverityWarning,
+			Summary:  "ClickHouse Keeper requests are slow",
+			Description: fmt.Sprintf("Replica {{ $labels.pod }} of KeeperCluster %s/%s average request latency is {{ $value }}ms.",
+				cr.Namespace, cr.Name),
+		},
+		{
+			Name: "KeeperNoLeader",
+			// Single replica cluster runs in standalone mode and has no leader.
+			// Missing series count as zero, so the alert also fires if no replica reports the metrics.
+			Expr: fmt.Sprintf("(sum(ClickHouseAsyncMetrics_KeeperIsLeader{%[1]s}) or vector(0)) + "+
+				"(sum(ClickHouseAsyncMetrics_KeeperIsStandalone{%[1]s}) or vector(0)) < 1", selector),
+			Severity: controller.AlertSeverityCritical,
+			Summary:  "ClickHouse Keeper has no leader",
+			Description: fmt.Sprintf("KeeperCluster %s/%s has no leader and does not serve requests.",
+				cr.Namespace, cr.Name),
+		},
+	})
+}
+
 type quorumConfig []serverConfig
 
 type serverConfig struct {
-	ID       string `yaml:"id"`
-	Hostname string `yaml:"hostname"`
-	Port     uint16 `yaml:"port"`
+	ID              string `yaml:"id"`
+	Hostname        string `yaml:"hostname"`
+	Port            uint16 `yaml:"port"`
+	CanBecomeLeader *bool  `yaml:"can_become_leader,omitempty"`
+	Priority        *int32 `yaml:"priority,omitempty"`
 }
 
 func templateQuorumConfig(r *keeperReconciler) (*corev1.ConfigMap, error) {
@@ -158,18 +261,23 @@ func templateQuorumConfig(r *keeperReconciler) (*corev1.ConfigMap, error) {
 }
 
 func generateQuorumConfig(r *keeperReconciler) quorumConfig {
-	hostnamesByID := map[v1.KeeperReplicaID]string{}
+	quorumConfig := make(quorumConfig, 0, len(r.ReplicaState))
 	for id := range r.ReplicaState {
-		hostnamesByID[id] = r.Cluster.HostnameByID(id)
-	}
-
-	quorumConfig := make(quorumConfig, 0, len(hostnamesByID))
-	for id, hostname := range hostnamesByID {
-		quorumConfig = append(quorumConfig, serverConfig{
+		server := serverConfig{
 			ID:       strconv.FormatInt(int64(id), 10),
-			Hostname: hostname,
+			Hostname: r.Cluster.HostnameByID(id),
 			Port:     PortInterserver,
-		})
+		}
+
+		// Learners never become the leader and do not vote.
+		if r.isLearner(id) {
+			server.CanBecomeLeader = new(false)
+			server.Priority = new(int32(0))
+		} else if priority, ok := r.Cluster.ReplicaPriority(id); ok {
+			server.Priority = &priority
+		}
+
+		quorumConfig = append(quorumConfig, server)
 	}
 
 	slices.SortFunc(quorumConfig, func(a, b serverConfig) int {
@@ -193,15 +301,48 @@ type httpControl struct {
 }
 
 type keeperServer struct {
-	TCPPort              uint16         `yaml:"tcp_port,omitempty"`
-	TCPPortSecure        uint16         `yaml:"tcp_port_secure,omitempty"`
-	ServerID             string         `yaml:"server_id"`
-	StoragePath          string         `yaml:"storage_path"`
-	DigestEnabled        bool           `yaml:"digest_enabled"`
-	LogStoragePath       string         `yaml:"log_storage_path"`
-	SnapshotStoragePath  string         `yaml:"snapshot_storage_path"`
-	CoordinationSettings map[string]any `yaml:"coordination_settings"`
-	HTTPControl          httpControl    `yaml:"http_control"`
+	TCPPort                 uint16         `yaml:"tcp_port,omitempty"`
+	TCPPortSecure           uint16         `yaml:"tcp_port_secure,omitempty"`
+	ServerID                string         `yaml:"server_id"`
+	StoragePath             string         `yaml:"storage_path"`
+	DigestEnabled           bool           `yaml:"digest_enabled"`
+	LogStoragePath          string         `yaml:"log_storage_path"`
+	SnapshotStoragePath     string         `yaml:"snapshot_storage_path"`
+	CoordinationSettings    map[string]any `yaml:"coordination_settings"`
+	HTTPControl             httpControl    `yaml:"http_control"`
+	EnableReconfiguration   bool           `yaml:"enable_reconfiguration,omitempty"`
+	FourLetterWordAllowList string         `yaml:"four_letter_word_white_list,omitempty"`
+}
+
+// coordinationSettings renders typed coordination settings. Unset values are left to the Keeper defaults.
+func coordinationSettings(settings v1.KeeperCoordinationSettings) map[string]any {
+	result := map[string]any{
+		"raft_logs_level": "trace",
+		"compress_logs":   settings.CompressLogs,
+	}
+
+	// The defaults match the Keeper ones, they are skipped to keep the configuration of existing clusters unchanged.
+	if settings.SessionTimeoutMs > 0 && settings.SessionTimeoutMs != v1.DefaultKeeperSessionTimeoutMs {
+		result["session_timeout_ms"] = settings.SessionTimeoutMs
+	}
+
+	if settings.SnapshotDistance > 0 && settings.SnapshotDistance != v1.DefaultKeeperSnapshotDistance {
+		result["snapshot_distance"] = settings.SnapshotDistance
+	}
+
+	if settings.SnapshotsToKeep > 0 && settings.SnapshotsToKeep != v1.DefaultKeeperSnapshotsToKeep {
+		result["snapshots_to_keep"] = settings.SnapshotsToKeep
+	}
+
+	if settings.RotateLogStorageInterval > 0 && settings.RotateLogStorageInterval != v1.DefaultKeeperRotateLogStorageInterval {
+		result["rotate_log_storage_interval"] = settings.RotateLogStorageInterval
+	}
+
+	if settings.ForceSync != nil && !*settings.ForceSync {
+		result["force_sync"] = false
+	}
+
+	return result
 }
 
 func getConfigurationRevision(cr *v1.KeeperCluster, extraConfig map[string]any) (string, error) {
@@ -218,6 +359,54 @@ func getConfigurationRevision(cr *v1.KeeperCluster, extraConfig map[string]any)
 	return hash, nil
 }
 
+// reloadableConfigKeys lists configuration settings applied by the Keeper without restart.
+var reloadableConfigKeys = [][]string{
+	{"logger", "level"},
+	{"keeper_server", "max_memory_usage_soft_limit"},
+}
+
+// getRestartRevision returns the hash of configuration settings requiring replica restart to be applied.
+func getRestartRevision(cr *v1.KeeperCluster, extraConfig map[string]any) (string, error) {
+	config, err := generateConfigForSingleReplica(cr, extraConfig, 0)
+	if err != nil {
+		return "", fmt.Errorf("generate template configuration: %w", err)
+	}
+
+	configMap := map[any]any{}
+	if err := yaml.Unmarshal([]byte(config), &configMap); err != nil {
+		return "", fmt.Errorf("unmarshal template configuration: %w", err)
+	}
+
+	for _, keyPath := range reloadableConfigKeys {
+		parent := configMap
+		for _, key := range keyPath[:len(keyPath)-1] {
+			child, ok := parent[key].(map[any]any)
+			if !ok {
+				parent = nil
+				break
+			}
+
+			parent = child
+		}
+
+		if parent != nil {
+			delete(parent, keyPath[len(keyPath)-1])
+		}
+	}
+
+	restartConfig, err := yaml.Marshal(configMap)
+	if err != nil {
+		return "", fmt.Errorf("marshal restart configuration: %w", err)
+	}
+
+	hash, err := controllerutil.DeepHashObject(string(restartConfig))
+	if err != nil {
+		return "", fmt.Errorf("hash restart configuration: %w", err)
+	}
+
+	return hash, nil
+}
+
 func getStatefulSetRevision(cr *v1.KeeperCluster) (string, error) {
 	sts, err := templateStatefulSet(cr, 0)
 	if err != nil {
@@ -298,7 +487,7 @@ func templateStatefulSet(cr *v1.KeeperCluster, replicaID v1.KeeperReplicaID) (*a
 			},
 			{
 				Protocol:      corev1.ProtocolTCP,
-				Name:          "prometheus",
+				Name:          controller.PrometheusPortName,
 				ContainerPort: PortPrometheusScrape,
 			},
 		},
@@ -363,7 +552,7 @@ func templateStatefulSet(cr *v1.KeeperCluster, replicaID v1.KeeperReplicaID) (*a
 		TopologySpreadConstraints:     cr.Spec.PodTemplate.TopologySpreadConstraints,
 		ImagePullSecrets:              cr.Spec.PodTemplate.ImagePullSecrets,
 		NodeSelector:                  cr.Spec.PodTemplate.NodeSelector,
-		Affinity:                      cr.Spec.PodTemplate.Affinity,
+		Affinity:                      cr.Spec.PodTemplate.Affinity.DeepCopy(),
 		Tolerations:                   cr.Spec.PodTemplate.Tolerations,
 		SchedulerName:                 cr.Spec.PodTemplate.SchedulerName,
 		ServiceAccountName:            cr.Spec.PodTemplate.ServiceAccountName,
@@ -376,6 +565,15 @@ func templateStatefulSet(cr *v1.KeeperCluster, replicaID v1.KeeperReplicaID) (*a
 		},
 	}
 
+	// The data volume is required to keep the imported data, webhook rejects bootstrap without it.
+	// Init containers and the source volume are removed once the import is verified.
+	if cr.Spec.Bootstrap != nil && !cr.Status.Bootstrapped && cr.Spec.DataVolumeClaimSpec != nil &&
+		replicaID == bootstrapReplicaID {
+		initContainers, bootstrapVolumes := buildBootstrapContainers(cr)
+		keeperPodSpec.InitContainers = initContainers
+		keeperPodSpec.Volumes = append(keeperPodSpec.Volumes, bootstrapVolumes...)
+	}
+
 	if cr.Spec.PodTemplate.TopologyZoneKey != nil && *cr.Spec.PodTemplate.TopologyZoneKey != "" {
 		if keeperPodSpec.Affinity == nil {
 			keeperPodSpec.Affinity = &corev1.Affinity{}
@@ -406,6 +604,27 @@ func templateStatefulSet(cr *v1.KeeperCluster, replicaID v1.KeeperReplicaID) (*a
 				},
 			},
 		})
+
+		// Make sure a replica runs in the preferred leader zone.
+		if zone := cr.Spec.LeaderPlacement.PreferredZone; zone != "" {
+			if keeperPodSpec.Affinity.NodeAffinity == nil {
+				keeperPodSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+			}
+
+			keeperPodSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				keeperPodSpec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+				corev1.PreferredSchedulingTerm{
+					Weight: 100,
+					Preference: corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      *cr.Spec.PodTemplate.TopologyZoneKey,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{zone},
+						}},
+					},
+				},
+			)
+		}
 	}
 
 	if cr.Spec.PodTemplate.NodeHostnameKey != nil && *cr.Spec.PodTemplate.NodeHostnameKey != "" {
@@ -499,19 +718,18 @@ func generateConfigForSingleReplica(cr *v1.KeeperCluster, extraConfig map[string
 		Prometheus: controller.DefaultPrometheusConfig(PortPrometheusScrape),
 		Logger:     controller.GenerateLoggerConfig(cr.Spec.Settings.Logger, LogPath, "clickhouse-keeper"),
 		KeeperServer: keeperServer{
-			TCPPort:             PortNative,
-			ServerID:            strconv.FormatInt(int64(replicaID), 10),
-			StoragePath:         internal.KeeperDataPath,
-			DigestEnabled:       true,
-			LogStoragePath:      StorageLogPath,
-			SnapshotStoragePath: StorageSnapshotPath,
-			CoordinationSettings: map[string]any{
-				"raft_logs_level": "trace",
-				"compress_logs":   false,
-			},
+			TCPPort:                 PortNative,
+			ServerID:                strconv.FormatInt(int64(replicaID), 10),
+			StoragePath:             internal.KeeperDataPath,
+			DigestEnabled:           true,
+			LogStoragePath:          StorageLogPath,
+			SnapshotStoragePath:     StorageSnapshotPath,
+			CoordinationSettings:    coordinationSettings(cr.Spec.Settings.Coordination),
+			FourLetterWordAllowList: strings.Join(cr.Spec.Settings.FourLetterWordAllowList, ","),
 			HTTPControl: httpControl{
 				Port: PortHTTPControl,
 			},
+			EnableReconfiguration: cr.Spec.Settings.DynamicReconfiguration,
 		},
 	}
 
@@ -657,3 +875,94 @@ func buildVolumes(cr *v1.KeeperCluster, replicaID v1.KeeperReplicaID) ([]corev1.
 
 	return volumes, volumeMounts, nil
 }
+
+// buildBootstrapContainers returns init containers importing the ZooKeeper data before the first replica start.
+// Containers do nothing if the replica already has Keeper snapshots.
+func buildBootstrapContainers(cr *v1.KeeperCluster) ([]corev1.Container, []corev1.Volume) {
+	bootstrap := cr.Spec.Bootstrap
+	dataMount := corev1.VolumeMount{
+		Name:      internal.PersistentVolumeName,
+		MountPath: internal.KeeperDataPath,
+		SubPath:   "var-lib-clickhouse",
+	}
+	skipIfBootstrapped := fmt.Sprintf(`if [ -e %q ] || [ -n "$(ls -A %q 2>/dev/null)" ]; then `+
+		`echo "ZooKeeper data is already imported, skipping"; exit 0; fi`,
+		BootstrapMarkerPath, StorageSnapshotPath)
+
+	var (
+		containers []corev1.Container
+		volumes    []corev1.Volume
+	)
+
+	sourcePath := BootstrapSourcePath
+	convertMounts := []corev1.VolumeMount{dataMount}
+
+	if bootstrap.PersistentVolumeClaim != nil {
+		source := bootstrap.PersistentVolumeClaim.DeepCopy()
+		source.ReadOnly = true
+		volumes = append(volumes, corev1.Volume{
+			Name:         internal.BootstrapVolumeName,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: source},
+		})
+		convertMounts = append(convertMounts, corev1.VolumeMount{
+			Name:      internal.BootstrapVolumeName,
+			MountPath: BootstrapSourcePath,
+			ReadOnly:  true,
+		})
+	}
+
+	if storage := bootstrap.ObjectStorage; storage != nil {
+		sourcePath = BootstrapDownloadPath
+
+		download := fmt.Sprintf("aws s3 sync %q %q", storage.URL, BootstrapDownloadPath)
+		if storage.Endpoint != "" {
+			download += fmt.Sprintf(" --endpoint-url %q", storage.Endpoint)
+		}
+
+		container := corev1.Container{
+			Name:            BootstrapDownloadContainerName,
+			Image:           storage.Image.String(),
+			ImagePullPolicy: cr.Spec.ContainerTemplate.ImagePullPolicy,
+			Command:         []string{"/bin/sh", "-c", skipIfBootstrapped + "\n" + download},
+			Resources:       cr.Spec.ContainerTemplate.Resources,
+			VolumeMounts:    []corev1.VolumeMount{dataMount},
+		}
+		if storage.CredentialsSecret != nil {
+			container.EnvFrom = []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: *storage.CredentialsSecret},
+			}}
+		}
+
+		containers = append(containers, container)
+	}
+
+	// The snapshot is converted into a separate directory and moved in place with a single rename,
+	// so the interrupted conversion is restarted from scratch.
+	script := []string{
+		"set -e",
+		skipIfBootstrapped,
+		fmt.Sprintf("rm -rf %q", BootstrapConvertPath),
+		fmt.Sprintf("mkdir -p %q", BootstrapConvertPath),
+		fmt.Sprintf("clickhouse-keeper-converter --zookeeper-logs-dir %q --zookeeper-snapshots-dir %q --output-dir %q",
+			path.Join(sourcePath, bootstrap.LogsPath), path.Join(sourcePath, bootstrap.SnapshotsPath), BootstrapConvertPath),
+		fmt.Sprintf("mkdir -p %q", path.Dir(path.Clean(StorageSnapshotPath))),
+		fmt.Sprintf("rm -rf %q", StorageSnapshotPath),
+		fmt.Sprintf("mv %q %q", path.Clean(BootstrapConvertPath), path.Clean(StorageSnapshotPath)),
+		fmt.Sprintf("touch %q", BootstrapMarkerPath),
+	}
+	if bootstrap.ObjectStorage != nil {
+		script = append(script, fmt.Sprintf("rm -rf %q", BootstrapDownloadPath))
+	}
+
+	containers = append(containers, corev1.Container{
+		Name:            BootstrapConvertContainerName,
+		Image:           cr.Spec.ContainerTemplate.Image.String(),
+		ImagePullPolicy: cr.Spec.ContainerTemplate.ImagePullPolicy,
+		Command:         []string{"/bin/bash", "-c", strings.Join(script, "\n")},
+		Resources:       cr.Spec.ContainerTemplate.Resources,
+		VolumeMounts:    convertMounts,
+		SecurityContext: cr.Spec.ContainerTemplate.SecurityContext,
+	})
+
+	return containers, volumes
+}
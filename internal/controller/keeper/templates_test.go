@@ -1,14 +1,18 @@
 package keeper
 
 import (
+	"context"
+
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
 )
 
 type confMap map[any]any
@@ -131,3 +135,375 @@ var _ = Describe("ExtraConfig", func() {
 		Expect(config["keeper_server"].(confMap)["coordination_settings"].(confMap)["compress_logs"]).To(BeTrue())
 	})
 })
+
+var _ = Describe("DynamicReconfiguration", func() {
+	It("should enable reconfiguration in keeper config", func() {
+		cr := &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.KeeperClusterSpec{
+				Replicas: ptr.To[int32](3),
+			},
+		}
+
+		configYAML, err := generateConfigForSingleReplica(cr, nil, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configYAML).NotTo(ContainSubstring("enable_reconfiguration"))
+
+		cr.Spec.Settings.DynamicReconfiguration = true
+		configYAML, err = generateConfigForSingleReplica(cr, nil, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		var config confMap
+		Expect(yaml.Unmarshal([]byte(configYAML), &config)).To(Succeed())
+		//nolint:forcetypeassert
+		Expect(config["keeper_server"].(confMap)["enable_reconfiguration"]).To(BeTrue())
+	})
+})
+
+var _ = Describe("Learners", func() {
+	It("should render learners as non-voting quorum members", func() {
+		log, rec, cancelEvents := setupReconciler()
+		DeferCleanup(cancelEvents)
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.Cluster.Spec.Learners = ptr.To[int32](2)
+		for id := range v1.KeeperReplicaID(5) {
+			rec.SetReplica(id, replicaState{})
+		}
+
+		rec.assignReplicaRoles()
+
+		quorum := generateQuorumConfig(rec)
+		Expect(quorum).To(HaveLen(5))
+
+		for _, server := range quorum[:3] {
+			Expect(server.CanBecomeLeader).To(BeNil())
+			Expect(server.Priority).To(BeNil())
+		}
+
+		for _, server := range quorum[3:] {
+			Expect(server.CanBecomeLeader).To(HaveValue(BeFalse()))
+			Expect(server.Priority).To(HaveValue(BeZero()))
+		}
+
+		By("setting replica priority")
+		rec.Cluster.Spec.LeaderPlacement.Priorities = []v1.KeeperReplicaPriority{{ReplicaID: 0, Priority: 3}, {ReplicaID: 4, Priority: 3}}
+
+		quorum = generateQuorumConfig(rec)
+		Expect(quorum[0].Priority).To(HaveValue(BeEquivalentTo(3)))
+		Expect(quorum[4].Priority).To(HaveValue(BeZero()))
+
+		By("promoting a learner")
+		rec.Cluster.Spec.Replicas = ptr.To[int32](4)
+		rec.Cluster.Spec.Learners = ptr.To[int32](1)
+		rec.HorizontalScaleAllowed = true
+
+		_, err := rec.reconcileReplicaRoles(context.Background(), log)
+		Expect(err).NotTo(HaveOccurred())
+
+		quorum = generateQuorumConfig(rec)
+		Expect(quorum[3].CanBecomeLeader).To(BeNil())
+		Expect(quorum[4].CanBecomeLeader).To(HaveValue(BeFalse()))
+	})
+
+	It("should compute disruption budget from voting replicas", func() {
+		_, rec, cancelEvents := setupReconciler()
+		DeferCleanup(cancelEvents)
+
+		rec.Cluster.Spec.Replicas = ptr.To[int32](3)
+		rec.Cluster.Spec.Learners = ptr.To[int32](2)
+		Expect(templatePodDisruptionBudget(rec.Cluster, rec.votingReplicas()).Spec.MaxUnavailable.IntValue()).To(Equal(1))
+
+		for id := range v1.KeeperReplicaID(5) {
+			rec.SetReplica(id, replicaState{Role: roleLearner})
+		}
+
+		rec.SetReplica(0, replicaState{Role: roleParticipant})
+		Expect(rec.votingReplicas()).To(BeEquivalentTo(1))
+		Expect(templatePodDisruptionBudget(rec.Cluster, rec.votingReplicas()).Spec.MaxUnavailable.IntValue()).To(BeZero())
+	})
+})
+
+var _ = Describe("PrometheusRule", func() {
+	It("should render alerts with thresholds from the spec", func() {
+		cr := &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+			},
+			Spec: v1.KeeperClusterSpec{
+				Monitoring: v1.KeeperMonitoringSpec{
+					Alerts: v1.KeeperAlertsSpec{
+						AlertsSpec:                v1.AlertsSpec{Enabled: true, For: "10m"},
+						KeeperLatencyMilliseconds: 50,
+					},
+				},
+			},
+		}
+
+		rule := templatePrometheusRule(cr)
+		Expect(rule.Spec.Groups).To(HaveLen(1))
+
+		alerts := map[string]string{}
+		for _, alert := range rule.Spec.Groups[0].Rules {
+			alerts[alert.Alert] = alert.Expr.StrVal
+			Expect(alert.Expr.StrVal).To(ContainSubstring(`namespace="default", pod=~"test-keeper-[0-9]+-0"`))
+			Expect(*alert.For).To(BeEquivalentTo("10m"))
+		}
+
+		Expect(alerts).To(HaveKeyWithValue("KeeperHighLatency", HaveSuffix("> 50")))
+		Expect(alerts).To(HaveKeyWithValue("KeeperNoLeader", ContainSubstring("or vector(0)")))
+	})
+})
+
+var _ = Describe("PreferredLeaderZone", func() {
+	It("should prefer scheduling replicas to the preferred zone", func() {
+		cr := &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: v1.KeeperClusterSpec{
+				Replicas: ptr.To[int32](3),
+				PodTemplate: v1.PodTemplateSpec{
+					TopologyZoneKey: ptr.To("topology.kubernetes.io/zone"),
+				},
+			},
+		}
+
+		sts, err := templateStatefulSet(cr, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sts.Spec.Template.Spec.Affinity.NodeAffinity).To(BeNil())
+
+		cr.Spec.LeaderPlacement.PreferredZone = "zone-a"
+		sts, err = templateStatefulSet(cr, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		terms := sts.Spec.Template.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].Preference.MatchExpressions).To(HaveExactElements(corev1.NodeSelectorRequirement{
+			Key:      "topology.kubernetes.io/zone",
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"zone-a"},
+		}))
+	})
+})
+
+var _ = Describe("Bootstrap", func() {
+	newCluster := func(bootstrap v1.KeeperBootstrapSpec) *v1.KeeperCluster {
+		cr := &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: v1.KeeperClusterSpec{
+				Replicas:            ptr.To[int32](3),
+				DataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+				Bootstrap:           &bootstrap,
+			},
+		}
+		cr.Spec.WithDefaults()
+
+		return cr
+	}
+
+	It("should import data from volume only on the first replica", func() {
+		cr := newCluster(v1.KeeperBootstrapSpec{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "zookeeper-data"},
+		})
+
+		sts, err := templateStatefulSet(cr, bootstrapReplicaID)
+		Expect(err).NotTo(HaveOccurred())
+
+		initContainers := sts.Spec.Template.Spec.InitContainers
+		Expect(initContainers).To(HaveLen(1))
+		Expect(initContainers[0].Name).To(Equal(BootstrapConvertContainerName))
+		Expect(initContainers[0].Command[2]).To(ContainSubstring(
+			`clickhouse-keeper-converter --zookeeper-logs-dir "/zookeeper-data/version-2" --zookeeper-snapshots-dir "/zookeeper-data/version-2"`))
+		Expect(initContainers[0].Command[2]).To(ContainSubstring(
+			`mv "/var/lib/clickhouse/zookeeper-bootstrap-snapshots" "/var/lib/clickhouse/coordination/snapshots"`))
+		Expect(initContainers[0].Command[2]).To(HaveSuffix(`touch "/var/lib/clickhouse/.zookeeper-bootstrap-completed"`))
+		Expect(sts.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "clickhouse-keeper-bootstrap-volume",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: "zookeeper-data",
+				ReadOnly:  true,
+			}},
+		}))
+
+		sts, err = templateStatefulSet(cr, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sts.Spec.Template.Spec.InitContainers).To(BeEmpty())
+	})
+
+	It("should remove import containers and source volume once bootstrapped", func() {
+		cr := newCluster(v1.KeeperBootstrapSpec{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "zookeeper-data"},
+		})
+		cr.Status.Bootstrapped = true
+
+		sts, err := templateStatefulSet(cr, bootstrapReplicaID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sts.Spec.Template.Spec.InitContainers).To(BeEmpty())
+		Expect(sts.Spec.Template.Spec.Volumes).NotTo(ContainElement(HaveField("Name", "clickhouse-keeper-bootstrap-volume")))
+	})
+
+	It("should download data from object storage before conversion", func() {
+		cr := newCluster(v1.KeeperBootstrapSpec{
+			ObjectStorage: &v1.KeeperBootstrapObjectStorage{
+				URL:               "s3://bucket/zookeeper",
+				Endpoint:          "https://storage.example.com",
+				CredentialsSecret: &corev1.LocalObjectReference{Name: "s3-credentials"},
+			},
+			SnapshotsPath: "data/version-2",
+			LogsPath:      "logs/version-2",
+		})
+
+		sts, err := templateStatefulSet(cr, bootstrapReplicaID)
+		Expect(err).NotTo(HaveOccurred())
+
+		initContainers := sts.Spec.Template.Spec.InitContainers
+		Expect(initContainers).To(HaveLen(2))
+		Expect(initContainers[0].Name).To(Equal(BootstrapDownloadContainerName))
+		Expect(initContainers[0].Image).To(Equal("docker.io/amazon/aws-cli:" + v1.DefaultKeeperBootstrapDownloadTag))
+		Expect(initContainers[0].Command[2]).To(ContainSubstring(
+			`aws s3 sync "s3://bucket/zookeeper" "/var/lib/clickhouse/zookeeper-bootstrap/" --endpoint-url "https://storage.example.com"`))
+		Expect(initContainers[0].EnvFrom).To(HaveLen(1))
+		Expect(initContainers[1].Command[2]).To(ContainSubstring(
+			`--zookeeper-logs-dir "/var/lib/clickhouse/zookeeper-bootstrap/logs/version-2"`))
+		Expect(initContainers[1].Command[2]).To(ContainSubstring(`rm -rf "/var/lib/clickhouse/zookeeper-bootstrap/"`))
+	})
+})
+
+var _ = Describe("CoordinationSettings", func() {
+	var cr *v1.KeeperCluster
+
+	BeforeEach(func() {
+		cr = &v1.KeeperCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: v1.KeeperClusterSpec{
+				Replicas: ptr.To[int32](3),
+			},
+		}
+		cr.Spec.WithDefaults()
+	})
+
+	It("should render typed coordination settings", func() {
+		cr.Spec.Settings.Coordination.SessionTimeoutMs = 60000
+		cr.Spec.Settings.Coordination.ForceSync = ptr.To(false)
+		cr.Spec.Settings.FourLetterWordAllowList = []string{"mntr", "rqld"}
+
+		configYAML, err := generateConfigForSingleReplica(cr, nil, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		var config confMap
+		Expect(yaml.Unmarshal([]byte(configYAML), &config)).To(Succeed())
+		//nolint:forcetypeassert
+		keeperServer := config["keeper_server"].(confMap)
+		Expect(keeperServer["four_letter_word_white_list"]).To(Equal("mntr,rqld"))
+		//nolint:forcetypeassert
+		coordination := keeperServer["coordination_settings"].(confMap)
+		Expect(coordination["session_timeout_ms"]).To(BeEquivalentTo(60000))
+		Expect(coordination["compress_logs"]).To(BeFalse())
+		Expect(coordination["force_sync"]).To(BeFalse())
+	})
+
+	It("should not render coordination settings equal to the Keeper defaults", func() {
+		Expect(cr.Spec.Settings.Coordination.SnapshotsToKeep).To(BeEquivalentTo(v1.DefaultKeeperSnapshotsToKeep))
+		Expect(cr.Spec.Settings.Coordination.ForceSync).To(Equal(ptr.To(true)))
+
+		configYAML, err := generateConfigForSingleReplica(cr, nil, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		var config confMap
+		Expect(yaml.Unmarshal([]byte(configYAML), &config)).To(Succeed())
+		//nolint:forcetypeassert
+		Expect(config["keeper_server"].(confMap)["coordination_settings"]).To(Equal(confMap{
+			"raft_logs_level": "trace",
+			"compress_logs":   false,
+		}))
+	})
+
+	It("should let extra config override typed settings", func() {
+		configYAML, err := generateConfigForSingleReplica(cr, map[string]any{
+			"keeper_server": confMap{
+				"coordination_settings": confMap{
+					"snapshot_distance": 5000,
+				},
+			},
+		}, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		var config confMap
+		Expect(yaml.Unmarshal([]byte(configYAML), &config)).To(Succeed())
+		//nolint:forcetypeassert
+		Expect(config["keeper_server"].(confMap)["coordination_settings"].(confMap)["snapshot_distance"]).To(BeEquivalentTo(5000))
+	})
+
+	It("should change restart revision only for restart required settings", func() {
+		restartRevision, err := getRestartRevision(cr, nil)
+		Expect(err).NotTo(HaveOccurred())
+		configRevision, err := getConfigurationRevision(cr, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		cr.Spec.Settings.Logger.Level = "information"
+		reloadRestartRevision, err := getRestartRevision(cr, map[string]any{
+			"keeper_server": confMap{"max_memory_usage_soft_limit": 1000000},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloadRestartRevision).To(Equal(restartRevision))
+
+		reloadConfigRevision, err := getConfigurationRevision(cr, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloadConfigRevision).NotTo(Equal(configRevision))
+
+		cr.Spec.Settings.Coordination.SnapshotsToKeep = 5
+		changedRestartRevision, err := getRestartRevision(cr, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changedRestartRevision).NotTo(Equal(restartRevision))
+	})
+})
+
+var _ = Describe("NetworkPolicy", func() {
+	cr := &v1.KeeperCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test-namespace",
+		},
+	}
+
+	It("should allow raft port only from keeper replicas and client ports from operator", func() {
+		policy := templateNetworkPolicy(cr, "operator")
+		Expect(policy.Spec.PodSelector.MatchLabels).To(HaveKeyWithValue(controllerutil.LabelAppKey, cr.SpecificName()))
+		Expect(policy.Spec.Ingress).To(HaveLen(3))
+
+		raft := policy.Spec.Ingress[0]
+		Expect(raft.Ports).To(HaveLen(1))
+		Expect(raft.Ports[0].Port.IntVal).To(BeEquivalentTo(PortInterserver))
+		Expect(raft.From).To(HaveLen(1))
+		Expect(raft.From[0].PodSelector.MatchLabels).To(HaveKeyWithValue(controllerutil.LabelAppKey, cr.SpecificName()))
+
+		Expect(policy.Spec.Ingress[1].From).To(BeEmpty())
+
+		client := policy.Spec.Ingress[2]
+		Expect(client.From).To(HaveLen(1))
+		Expect(client.From[0].NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "operator"))
+	})
+
+	It("should not open client ports if operator namespace is unknown", func() {
+		policy := templateNetworkPolicy(cr, "")
+		Expect(policy.Spec.Ingress).To(HaveLen(2))
+	})
+
+	It("should allow client ports from ClickHouse pods in allowed namespaces", func() {
+		allowed := cr.DeepCopy()
+		allowed.Spec.AllowedNamespaces = []string{"tenant", v1.AllNamespaces}
+
+		policy := templateNetworkPolicy(allowed, "")
+		Expect(policy.Spec.Ingress).To(HaveLen(3))
+
+		client := policy.Spec.Ingress[2]
+		Expect(client.From).To(HaveLen(2))
+		Expect(client.From[0].NamespaceSelector.MatchLabels).To(HaveKeyWithValue(corev1.LabelMetadataName, "tenant"))
+		Expect(client.From[0].PodSelector.MatchLabels).To(HaveKeyWithValue(controllerutil.LabelRoleKey, controllerutil.LabelClickHouseValue))
+		Expect(client.From[1].NamespaceSelector.MatchLabels).To(BeEmpty())
+	})
+})
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const metricsNamespace = "clickhouse_operator"
+
+// Controller names used as the `controller` label value of the operator metrics.
+const (
+	MetricsControllerClickHouse = "clickhouse"
+	MetricsControllerKeeper     = "keeper"
+)
+
+var (
+	reconcileStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_step_duration_seconds",
+		Help:      "Duration of a single reconcile step.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"controller", "reconcile_step", "result"})
+
+	replicasByStage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "cluster_replicas",
+		Help:      "Number of cluster replicas by their update stage.",
+	}, []string{"controller", "namespace", "cluster", "stage"})
+
+	keeperLeaderChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "keeper_leader_changes_total",
+		Help:      "Number of Keeper leader changes observed by the operator.",
+	}, []string{"namespace", "cluster"})
+
+	keeperFollowers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "keeper_followers",
+		Help:      "Number of followers reported by the Keeper leader.",
+	}, []string{"namespace", "cluster"})
+
+	keeperStorageUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "keeper_storage_usage_ratio",
+		Help:      "Share of the Keeper replica data volume in use.",
+	}, []string{"namespace", "cluster", "replica"})
+
+	commanderQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "clickhouse_query_duration_seconds",
+		Help:      "Duration of management queries sent by the operator to ClickHouse replicas.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"namespace", "cluster", "operation"})
+
+	commanderQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "clickhouse_query_errors_total",
+		Help:      "Number of failed management queries sent by the operator to ClickHouse replicas.",
+	}, []string{"namespace", "cluster", "operation"})
+
+	// Last observed leader per KeeperCluster, used to detect leader changes between reconciliations.
+	keeperLeaders sync.Map
+)
+
+// RegisterMetrics registers operator metrics collectors in the given registry.
+func RegisterMetrics(registry prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		reconcileStepDuration,
+		replicasByStage,
+		keeperLeaderChanges,
+		keeperFollowers,
+		keeperStorageUsage,
+		commanderQueryDuration,
+		commanderQueryErrors,
+	}
+
+	var errs []error
+	for _, collector := range collectors {
+		if err := registry.Register(collector); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("register operator metrics: %w", err)
+	}
+
+	return nil
+}
+
+// ObserveReconcileStep records the duration and the outcome of a single reconcile step.
+func ObserveReconcileStep(controllerName string, step string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	reconcileStepDuration.WithLabelValues(controllerName, step, result).Observe(duration.Seconds())
+}
+
+// SetReplicaStageCounts records the number of cluster replicas in each update stage.
+func SetReplicaStageCounts(controllerName string, cluster types.NamespacedName, counts map[ReplicaUpdateStage]int) {
+	for stage, name := range mapStatusText {
+		replicasByStage.WithLabelValues(controllerName, cluster.Namespace, cluster.Name, name).Set(float64(counts[stage]))
+	}
+}
+
+// ObserveKeeperLeader records the current Keeper leader and its followers count.
+// Empty leader means that there is no leader in the cluster at the moment.
+func ObserveKeeperLeader(cluster types.NamespacedName, leader string, followers int) {
+	keeperFollowers.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(followers))
+
+	if leader == "" {
+		return
+	}
+
+	previous, loaded := keeperLeaders.Swap(cluster, leader)
+	if loaded && previous != leader {
+		keeperLeaderChanges.WithLabelValues(cluster.Namespace, cluster.Name).Inc()
+	}
+}
+
+// SetKeeperStorageUsage records the data volume usage ratio of the Keeper replicas.
+// Replicas missing in the usage map are removed from the metric.
+func SetKeeperStorageUsage(cluster types.NamespacedName, usage map[string]float64) {
+	keeperStorageUsage.DeletePartialMatch(prometheus.Labels{"namespace": cluster.Namespace, "cluster": cluster.Name})
+
+	for replica, ratio := range usage {
+		keeperStorageUsage.WithLabelValues(cluster.Namespace, cluster.Name, replica).Set(ratio)
+	}
+}
+
+// ObserveClickHouseQuery records the duration and the outcome of a management query to ClickHouse.
+func ObserveClickHouseQuery(cluster types.NamespacedName, operation string, duration time.Duration, err error) {
+	commanderQueryDuration.WithLabelValues(cluster.Namespace, cluster.Name, operation).Observe(duration.Seconds())
+
+	if err != nil {
+		commanderQueryErrors.WithLabelValues(cluster.Namespace, cluster.Name, operation).Inc()
+	}
+}
+
+// DeleteClusterMetrics removes all per-cluster metrics series of the deleted cluster.
+func DeleteClusterMetrics(controllerName string, cluster types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": cluster.Namespace, "cluster": cluster.Name}
+
+	replicasByStage.DeletePartialMatch(prometheus.Labels{
+		"controller": controllerName,
+		"namespace":  cluster.Namespace,
+		"cluster":    cluster.Name,
+	})
+
+	switch controllerName {
+	case MetricsControllerKeeper:
+		keeperLeaderChanges.DeletePartialMatch(labels)
+		keeperFollowers.DeletePartialMatch(labels)
+		keeperStorageUsage.DeletePartialMatch(labels)
+		keeperLeaders.Delete(cluster)
+	case MetricsControllerClickHouse:
+		commanderQueryDuration.DeletePartialMatch(labels)
+		commanderQueryErrors.DeletePartialMatch(labels)
+	}
+}
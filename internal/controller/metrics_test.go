@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Metrics Suite")
+}
+
+var _ = Describe("Metrics", func() {
+	cluster := types.NamespacedName{Namespace: "metrics", Name: "test"}
+
+	AfterEach(func() {
+		DeleteClusterMetrics(MetricsControllerKeeper, cluster)
+		DeleteClusterMetrics(MetricsControllerClickHouse, cluster)
+	})
+
+	It("should label reconcile steps with the result", func() {
+		ObserveReconcileStep(MetricsControllerKeeper, "reconcileMetricsTest", time.Second, nil)
+		ObserveReconcileStep(MetricsControllerKeeper, "reconcileMetricsTest", time.Second, errors.New("failed"))
+
+		Expect(testutil.CollectAndCount(reconcileStepDuration.MustCurryWith(map[string]string{
+			"controller":     MetricsControllerKeeper,
+			"reconcile_step": "reconcileMetricsTest",
+		}))).To(Equal(2))
+	})
+
+	It("should report every replica stage", func() {
+		SetReplicaStageCounts(MetricsControllerClickHouse, cluster, map[ReplicaUpdateStage]int{StageUpToDate: 2})
+
+		Expect(testutil.ToFloat64(replicasByStage.WithLabelValues(
+			MetricsControllerClickHouse, cluster.Namespace, cluster.Name, mapStatusText[StageUpToDate]))).To(BeEquivalentTo(2))
+		Expect(testutil.ToFloat64(replicasByStage.WithLabelValues(
+			MetricsControllerClickHouse, cluster.Namespace, cluster.Name, mapStatusText[StageError]))).To(BeZero())
+	})
+
+	It("should count Keeper leader changes", func() {
+		ObserveKeeperLeader(cluster, "keeper-0", 2)
+		ObserveKeeperLeader(cluster, "", 0)
+		ObserveKeeperLeader(cluster, "keeper-0", 2)
+		ObserveKeeperLeader(cluster, "keeper-1", 1)
+
+		Expect(testutil.ToFloat64(keeperLeaderChanges.WithLabelValues(cluster.Namespace, cluster.Name))).To(BeEquivalentTo(1))
+		Expect(testutil.ToFloat64(keeperFollowers.WithLabelValues(cluster.Namespace, cluster.Name))).To(BeEquivalentTo(1))
+	})
+
+	It("should remove storage usage of missing replicas", func() {
+		SetKeeperStorageUsage(cluster, map[string]float64{"1": 0.5, "2": 0.7})
+		SetKeeperStorageUsage(cluster, map[string]float64{"2": 0.9})
+
+		Expect(testutil.CollectAndCount(keeperStorageUsage)).To(Equal(1))
+		Expect(testutil.ToFloat64(keeperStorageUsage.WithLabelValues(cluster.Namespace, cluster.Name, "2"))).To(BeEquivalentTo(0.9))
+	})
+
+	It("should count only failed ClickHouse queries as errors", func() {
+		ObserveClickHouseQuery(cluster, "exec", time.Millisecond, nil)
+		ObserveClickHouseQuery(cluster, "exec", time.Millisecond, errors.New("failed"))
+
+		Expect(testutil.CollectAndCount(commanderQueryDuration)).To(Equal(1))
+		Expect(testutil.ToFloat64(commanderQueryErrors.WithLabelValues(cluster.Namespace, cluster.Name, "exec"))).To(BeEquivalentTo(1))
+	})
+
+	It("should delete series of the removed cluster", func() {
+		SetKeeperStorageUsage(cluster, map[string]float64{"1": 0.5})
+		ObserveKeeperLeader(cluster, "keeper-0", 2)
+		SetReplicaStageCounts(MetricsControllerKeeper, cluster, map[ReplicaUpdateStage]int{})
+
+		DeleteClusterMetrics(MetricsControllerKeeper, cluster)
+
+		Expect(testutil.CollectAndCount(keeperStorageUsage)).To(BeZero())
+		Expect(testutil.CollectAndCount(keeperFollowers)).To(BeZero())
+		Expect(testutil.CollectAndCount(replicasByStage.MustCurryWith(map[string]string{
+			"controller": MetricsControllerKeeper,
+		}))).To(BeZero())
+	})
+})
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+const (
+	// PrometheusPortName is the name of the container port serving Prometheus metrics.
+	PrometheusPortName = "prometheus"
+	// PrometheusMetricsPath is the HTTP path serving Prometheus metrics.
+	PrometheusMetricsPath = "/metrics"
+
+	// AlertSeverityWarning is the severity of alerts that require attention, but do not affect availability yet.
+	AlertSeverityWarning = "warning"
+	// AlertSeverityCritical is the severity of alerts affecting the cluster availability.
+	AlertSeverityCritical = "critical"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// TemplatePodMetricsEndpoint returns the PodMonitor endpoint scraping the Prometheus port of the cluster pods.
+func TemplatePodMetricsEndpoint(spec v1.PodMonitorSpec, relabelings []monitoringv1.RelabelConfig) monitoringv1.PodMetricsEndpoint {
+	endpoint := monitoringv1.PodMetricsEndpoint{
+		Port:           ptr.To(PrometheusPortName),
+		Path:           PrometheusMetricsPath,
+		Scheme:         ptr.To(monitoringv1.SchemeHTTP),
+		Interval:       monitoringv1.Duration(spec.Interval),
+		ScrapeTimeout:  monitoringv1.Duration(spec.ScrapeTimeout),
+		RelabelConfigs: relabelings,
+	}
+
+	if spec.TLS != nil {
+		endpoint.Scheme = ptr.To(monitoringv1.SchemeHTTPS)
+		endpoint.TLSConfig = &monitoringv1.SafeTLSConfig{
+			InsecureSkipVerify: ptr.To(spec.TLS.InsecureSkipVerify),
+		}
+
+		if spec.TLS.ServerName != "" {
+			endpoint.TLSConfig.ServerName = ptr.To(spec.TLS.ServerName)
+		}
+
+		if spec.TLS.CABundle != nil {
+			endpoint.TLSConfig.CA = monitoringv1.SecretOrConfigMap{
+				Secret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: spec.TLS.CABundle.Name},
+					Key:                  spec.TLS.CABundle.Key,
+				},
+			}
+		}
+	}
+
+	return endpoint
+}
+
+// PodLabelRelabeling returns the relabeling rule that copies the pod label value into the target label.
+func PodLabelRelabeling(podLabel string, targetLabel string) monitoringv1.RelabelConfig {
+	return monitoringv1.RelabelConfig{
+		Action:       "replace",
+		SourceLabels: []monitoringv1.LabelName{monitoringv1.LabelName("__meta_kubernetes_pod_label_" + invalidLabelChars.ReplaceAllString(podLabel, "_"))},
+		TargetLabel:  targetLabel,
+	}
+}
+
+// AlertRule describes a single alert of the PrometheusRule managed by the operator.
+type AlertRule struct {
+	Name        string
+	Expr        string
+	Severity    string
+	Summary     string
+	Description string
+}
+
+// TemplatePrometheusRule returns the PrometheusRule with a single group containing the given alerts.
+func TemplatePrometheusRule(
+	objectMeta metav1.ObjectMeta,
+	spec v1.AlertsSpec,
+	groupName string,
+	alerts []AlertRule,
+) *monitoringv1.PrometheusRule {
+	rules := make([]monitoringv1.Rule, 0, len(alerts))
+	for _, alert := range alerts {
+		rule := monitoringv1.Rule{
+			Alert: alert.Name,
+			Expr:  intstr.FromString(alert.Expr),
+			Labels: controllerutil.MergeMaps(spec.AlertLabels, map[string]string{
+				"severity": alert.Severity,
+			}),
+			Annotations: map[string]string{
+				"summary":     alert.Summary,
+				"description": alert.Description,
+			},
+		}
+
+		if spec.For != "" {
+			rule.For = ptr.To(monitoringv1.Duration(spec.For))
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return &monitoringv1.PrometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       monitoringv1.PrometheusRuleKind,
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: objectMeta,
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: []monitoringv1.RuleGroup{{
+				Name:  groupName,
+				Rules: rules,
+			}},
+		},
+	}
+}
+
+// PodMetricsSelector returns the PromQL label matchers selecting series scraped from the pods with matching names.
+// Relies on the `namespace` and `pod` target labels set by Prometheus Operator.
+func PodMetricsSelector(namespace string, podNameRegex string) string {
+	return fmt.Sprintf(`namespace=%q, pod=~%q`, namespace, podNameRegex)
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// TemplateNetworkPolicy returns the NetworkPolicy restricting ingress traffic to pods of the given app.
+// Traffic not matching any of the rules is denied.
+func TemplateNetworkPolicy(
+	objectMeta metav1.ObjectMeta,
+	app string,
+	rules []networkingv1.NetworkPolicyIngressRule,
+) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: objectMeta,
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					controllerutil.LabelAppKey: app,
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     rules,
+		},
+	}
+}
+
+// NetworkPolicyPorts returns NetworkPolicy TCP ports for the given port numbers.
+func NetworkPolicyPorts(ports ...int32) []networkingv1.NetworkPolicyPort {
+	result := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		result = append(result, networkingv1.NetworkPolicyPort{
+			Protocol: ptr.To(corev1.ProtocolTCP),
+			Port:     ptr.To(intstr.FromInt32(port)),
+		})
+	}
+
+	return result
+}
+
+// AppPeer returns the NetworkPolicy peer matching pods of the given app in the namespace of the policy.
+func AppPeer(app string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				controllerutil.LabelAppKey: app,
+			},
+		},
+	}
+}
+
+// NamespacePeers returns the NetworkPolicy peers matching all pods in the given namespace.
+// Returns nothing if the namespace is empty.
+func NamespacePeers(namespace string) []networkingv1.NetworkPolicyPeer {
+	if namespace == "" {
+		return nil
+	}
+
+	return []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				corev1.LabelMetadataName: namespace,
+			},
+		},
+	}}
+}
+
+// RolePeers returns the NetworkPolicy peers matching pods with the given role in the given namespaces.
+// Namespace v1.AllNamespaces matches all namespaces.
+func RolePeers(role string, namespaces []string) []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		namespaceSelector := &metav1.LabelSelector{}
+		if namespace != v1.AllNamespaces {
+			namespaceSelector.MatchLabels = map[string]string{
+				corev1.LabelMetadataName: namespace,
+			}
+		}
+
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: namespaceSelector,
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					controllerutil.LabelRoleKey: role,
+				},
+			},
+		})
+	}
+
+	return peers
+}
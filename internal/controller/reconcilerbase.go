@@ -26,6 +26,7 @@ type controller interface {
 	GetClient() client.Client
 	GetScheme() *k8sruntime.Scheme
 	GetRecorder() events.EventRecorder
+	GetCapabilities() Capabilities
 }
 
 // ResourceReconcilerBase provides a base class for cluster reconcilers.
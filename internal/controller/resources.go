@@ -7,15 +7,20 @@ import (
 	"slices"
 
 	gcmp "github.com/google/go-cmp/cmp"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	v1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
 	util "github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
@@ -27,6 +32,8 @@ type ReplicaUpdateStage int
 const (
 	StageUpToDate ReplicaUpdateStage = iota
 	StageHasDiff
+	// StageCatchingUp means the replica is ready, but still replicates data from other replicas.
+	StageCatchingUp
 	StageNotReadyUpToDate
 	StageUpdating
 	StageError
@@ -36,6 +43,7 @@ const (
 var mapStatusText = map[ReplicaUpdateStage]string{
 	StageUpToDate:         "UpToDate",
 	StageHasDiff:          "HasDiff",
+	StageCatchingUp:       "CatchingUp",
 	StageNotReadyUpToDate: "NotReadyUpToDate",
 	StageUpdating:         "Updating",
 	StageError:            "Error",
@@ -194,6 +202,145 @@ func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcileConfigMap(
 	return r.reconcileResource(ctx, log, configMap, []string{"Data", "BinaryData"}, action)
 }
 
+// ReconcilePodMonitor reconciles a Prometheus Operator PodMonitor resource.
+// Removes the previously created PodMonitor if it is disabled. Does nothing if PodMonitor CRD is not installed.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcilePodMonitor(
+	ctx context.Context,
+	log util.Logger,
+	podMonitor *monitoringv1.PodMonitor,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, podMonitor, r.GetCapabilities().PodMonitor, enabled, action)
+}
+
+// ReconcilePrometheusRule reconciles a Prometheus Operator PrometheusRule resource.
+// Removes the previously created PrometheusRule if it is disabled. Does nothing if PrometheusRule CRD is not installed.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcilePrometheusRule(
+	ctx context.Context,
+	log util.Logger,
+	rule *monitoringv1.PrometheusRule,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, rule, r.GetCapabilities().PrometheusRule, enabled, action)
+}
+
+// ReconcileIngress reconciles a networking Ingress resource. Removes the previously created Ingress if it is disabled.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcileIngress(
+	ctx context.Context,
+	log util.Logger,
+	ingress *networkingv1.Ingress,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, ingress, true, enabled, action)
+}
+
+// ReconcileNetworkPolicy reconciles a NetworkPolicy resource. Removes the previously created NetworkPolicy if it is disabled.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcileNetworkPolicy(
+	ctx context.Context,
+	log util.Logger,
+	policy *networkingv1.NetworkPolicy,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, policy, true, enabled, action)
+}
+
+// ReconcileHTTPRoute reconciles a Gateway API HTTPRoute resource.
+// Removes the previously created HTTPRoute if it is disabled. Does nothing if HTTPRoute CRD is not installed.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcileHTTPRoute(
+	ctx context.Context,
+	log util.Logger,
+	route *gatewayv1.HTTPRoute,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, route, r.GetCapabilities().HTTPRoute, enabled, action)
+}
+
+// ReconcileTLSRoute reconciles a Gateway API TLSRoute resource.
+// Removes the previously created TLSRoute if it is disabled. Does nothing if TLSRoute CRD is not installed.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcileTLSRoute(
+	ctx context.Context,
+	log util.Logger,
+	route *gatewayv1alpha2.TLSRoute,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, route, r.GetCapabilities().TLSRoute, enabled, action)
+}
+
+// ReconcileBackendTLSPolicy reconciles a Gateway API BackendTLSPolicy resource.
+// Removes the previously created BackendTLSPolicy if it is disabled. Does nothing if BackendTLSPolicy CRD is not installed.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) ReconcileBackendTLSPolicy(
+	ctx context.Context,
+	log util.Logger,
+	policy *gatewayv1.BackendTLSPolicy,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	return r.reconcileOptionalResource(ctx, log, policy, r.GetCapabilities().BackendTLSPolicy, enabled, action)
+}
+
+// reconcileOptionalResource reconciles the resource of an API that may be not installed in the Kubernetes cluster.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) reconcileOptionalResource(
+	ctx context.Context,
+	log util.Logger,
+	resource client.Object,
+	served bool,
+	enabled bool,
+	action v1.EventAction,
+) (bool, error) {
+	if !served {
+		if enabled {
+			log.Warn("resource is enabled, but its CRD is not installed, skipping",
+				"kind", resource.GetObjectKind().GroupVersionKind().Kind)
+		}
+
+		return false, nil
+	}
+
+	if !enabled {
+		return r.deleteOwned(ctx, log, resource, action)
+	}
+
+	return r.reconcileResource(ctx, log, resource, []string{"Spec"}, action)
+}
+
+// deleteOwned deletes the resource with the same name as the given one if it exists and is controlled by the cluster.
+func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) deleteOwned(
+	ctx context.Context,
+	log util.Logger,
+	resource client.Object,
+	action v1.EventAction,
+) (bool, error) {
+	kind := resource.GetObjectKind().GroupVersionKind().Kind
+	log = log.With(kind, resource.GetName())
+
+	foundResource := resource.DeepCopyObject().(client.Object) //nolint:forcetypeassert // safe cast
+	if err := r.GetClient().Get(ctx, types.NamespacedName{
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}, foundResource); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get %s/%s: %w", kind, resource.GetName(), err)
+	}
+
+	if !metav1.IsControlledBy(foundResource, r.Cluster) {
+		log.Debug("resource is not controlled by the cluster, skipping removal")
+		return false, nil
+	}
+
+	log.Info("removing disabled resource")
+
+	return true, r.Delete(ctx, resource, action)
+}
+
 // Create creates the given Kubernetes resource and emits events on failure.
 func (r *ResourceReconcilerBase[Status, T, ReplicaID, S]) Create(ctx context.Context, resource client.Object, action v1.EventAction) error {
 	recorder := r.GetRecorder()
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// VolumeStats is the usage of a persistent volume mounted into a pod as reported by kubelet.
+type VolumeStats struct {
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// VolumeStatsReader reads the usage of persistent volumes mounted on a node.
+type VolumeStatsReader interface {
+	// GetVolumeStats returns the usage of volumes mounted on the node by PVC name.
+	GetVolumeStats(ctx context.Context, nodeName string) (map[types.NamespacedName]VolumeStats, error)
+}
+
+// kubeletSummary holds fields of the kubelet stats summary used by the operator.
+type kubeletSummary struct {
+	Pods []struct {
+		Volumes []struct {
+			UsedBytes     *uint64 `json:"usedBytes"`
+			CapacityBytes *uint64 `json:"capacityBytes"`
+			PVCRef        *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// KubeletVolumeStatsReader reads the kubelet stats summary through the API server node proxy.
+type KubeletVolumeStatsReader struct {
+	client rest.Interface
+}
+
+// NewKubeletVolumeStatsReader creates the kubelet stats reader for the given API server config.
+func NewKubeletVolumeStatsReader(config *rest.Config) (*KubeletVolumeStatsReader, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes clientset: %w", err)
+	}
+
+	return &KubeletVolumeStatsReader{client: clientset.CoreV1().RESTClient()}, nil
+}
+
+// GetVolumeStats implements VolumeStatsReader.
+func (r *KubeletVolumeStatsReader) GetVolumeStats(ctx context.Context, nodeName string) (map[types.NamespacedName]VolumeStats, error) {
+	data, err := r.client.Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix("stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get node %s stats summary: %w", nodeName, err)
+	}
+
+	return ParseVolumeStats(data)
+}
+
+// ParseVolumeStats extracts the persistent volume usage from the kubelet stats summary.
+func ParseVolumeStats(data []byte) (map[types.NamespacedName]VolumeStats, error) {
+	var summary kubeletSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("parse stats summary: %w", err)
+	}
+
+	stats := map[types.NamespacedName]VolumeStats{}
+
+	for _, pod := range summary.Pods {
+		for _, volume := range pod.Volumes {
+			if volume.PVCRef == nil || volume.UsedBytes == nil || volume.CapacityBytes == nil || *volume.CapacityBytes == 0 {
+				continue
+			}
+
+			stats[types.NamespacedName{Namespace: volume.PVCRef.Namespace, Name: volume.PVCRef.Name}] = VolumeStats{
+				UsedBytes:     int64(*volume.UsedBytes),     //nolint:gosec // volume sizes fit into int64
+				CapacityBytes: int64(*volume.CapacityBytes), //nolint:gosec // volume sizes fit into int64
+			}
+		}
+	}
+
+	return stats, nil
+}
@@ -5,11 +5,36 @@ import (
 )
 
 const (
-	AnnotationSpecHash    = "checksum/spec"
-	AnnotationConfigHash  = "checksum/configuration"
-	AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+	AnnotationSpecHash          = "checksum/spec"
+	AnnotationConfigHash        = "checksum/configuration"
+	AnnotationRestartConfigHash = "checksum/restart-configuration"
+	AnnotationRestartedAt       = "kubectl.kubernetes.io/restartedAt"
 
-	AnnotationStatefulSetVersion = "clickhouse.com/statefulset-version"
+	AnnotationStatefulSetVersion   = "clickhouse.com/statefulset-version"
+	AnnotationLeadershipTransferAt = "clickhouse.com/leadership-transfer-requested-at"
+	AnnotationSnapshotRequestedAt  = "clickhouse.com/snapshot-requested-at"
+
+	// AnnotationCatchUpPending marks the ClickHouse replica restarted by the rolling update until it catches up
+	// the replication.
+	AnnotationCatchUpPending = "clickhouse.com/catch-up-pending"
+	// AnnotationTableSyncPending marks the new ClickHouse replica until the replicated tables of its shard are created
+	// on it and fetched.
+	AnnotationTableSyncPending = "clickhouse.com/table-sync-pending"
+
+	// AnnotationKeeperRoot pins the Keeper root chosen for the ClickHouse cluster on its first reconcile,
+	// so the root does not depend on the status.
+	AnnotationKeeperRoot = "clickhouse.com/keeper-root"
+
+	// AnnotationKeeperRole holds the quorum role of the Keeper replica, so it does not depend on the replica ID order.
+	AnnotationKeeperRole = "clickhouse.com/keeper-role"
+
+	// AnnotationQuorumRecovery is set by the user on the KeeperCluster to request the quorum recovery.
+	// Every new value starts a new recovery.
+	AnnotationQuorumRecovery = "clickhouse.com/quorum-recovery"
+	// AnnotationQuorumRecoverySource marks the replica the quorum is recovered from with the recovery request value.
+	AnnotationQuorumRecoverySource = "clickhouse.com/quorum-recovery-source"
+	// AnnotationQuorumRecoveryAt holds the time the recovery mode was last requested on the source replica.
+	AnnotationQuorumRecoveryAt = "clickhouse.com/quorum-recovery-requested-at"
 )
 
 // AddHashWithKeyToAnnotations adds given spec hash to object's annotations with given key.
@@ -9,8 +9,9 @@ import (
 
 // Environment holds all environment variables for the application.
 type Environment struct {
-	EnableWebhooks bool     `env:"ENABLE_WEBHOOKS, default=true"`
-	WatchNamespace []string `env:"WATCH_NAMESPACE"`
+	EnableWebhooks    bool     `env:"ENABLE_WEBHOOKS, default=true"`
+	WatchNamespace    []string `env:"WATCH_NAMESPACE"`
+	OperatorNamespace string   `env:"OPERATOR_NAMESPACE"`
 }
 
 // GetEnvironment processes environment variables and returns an Environment struct.
@@ -55,4 +55,10 @@ var _ = DescribeTable("Environment variables parsing",
 	}, Environment{
 		EnableWebhooks: true,
 	}),
+	Entry("parse operator namespace", map[string]string{
+		"OPERATOR_NAMESPACE": "operator_namespace",
+	}, Environment{
+		EnableWebhooks:    true,
+		OperatorNamespace: "operator_namespace",
+	}),
 )
@@ -7,9 +7,14 @@ const (
 
 	QuorumConfigVolumeName = "clickhouse-keeper-quorum-config-volume"
 	ConfigVolumeName       = "clickhouse-keeper-config-volume"
+	BootstrapVolumeName    = "clickhouse-keeper-bootstrap-volume"
 
 	KeeperDataPath     = "/var/lib/clickhouse"
 	ClickHouseDataPath = "/var/lib/clickhouse"
+
+	ClickHouseManagementPort       = 9001
+	ClickHouseInterserverPort      = 9009
+	ClickHousePrometheusScrapePort = 9363
 )
 
 var (
@@ -20,11 +25,19 @@ var (
 		CustomCAVolumeName,
 	}
 
+	// ReservedClickHousePorts list of ports used by ClickHouse pods for management, replication and metrics.
+	ReservedClickHousePorts = []int32{
+		ClickHouseManagementPort,
+		ClickHouseInterserverPort,
+		ClickHousePrometheusScrapePort,
+	}
+
 	// ReservedKeeperVolumeNames list of reserved volume names for ClickHouse Keeper pods.
 	ReservedKeeperVolumeNames = []string{
 		QuorumConfigVolumeName,
 		PersistentVolumeName,
 		ConfigVolumeName,
 		TLSVolumeName,
+		BootstrapVolumeName,
 	}
 )
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/ClickHouse/clickhouse-operator/internal"
@@ -17,7 +21,8 @@ import (
 // SetupClickHouseWebhookWithManager registers the webhook for ClickHouseCluster in the manager.
 func SetupClickHouseWebhookWithManager(mgr ctrl.Manager, log controllerutil.Logger) error {
 	wh := &ClickHouseClusterWebhook{
-		Log: log.Named("clickhouse-webhook"),
+		Log:    log.Named("clickhouse-webhook"),
+		Client: mgr.GetClient(),
 	}
 
 	err := ctrl.NewWebhookManagedBy(mgr, &chv1.ClickHouseCluster{}).
@@ -36,6 +41,8 @@ func SetupClickHouseWebhookWithManager(mgr ctrl.Manager, log controllerutil.Logg
 // +kubebuilder:webhook:path=/validate-clickhouse-com-v1alpha1-clickhousecluster,mutating=false,failurePolicy=ignore,sideEffects=None,groups=clickhouse.com,resources=clickhouseclusters,verbs=create;update,versions=v1alpha1,name=vclickhousecluster-v1alpha1.kb.io,admissionReviewVersions=v1
 type ClickHouseClusterWebhook struct {
 	Log controllerutil.Logger
+	// Client is used to check the cluster against other objects. Checks are skipped if it is not set.
+	Client client.Reader
 }
 
 var _ admission.Defaulter[*chv1.ClickHouseCluster] = &ClickHouseClusterWebhook{}
@@ -50,14 +57,27 @@ func (w *ClickHouseClusterWebhook) Default(_ context.Context, cluster *chv1.Clic
 }
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ClickHouseCluster.
-func (w *ClickHouseClusterWebhook) ValidateCreate(_ context.Context, cluster *chv1.ClickHouseCluster) (admission.Warnings, error) {
+func (w *ClickHouseClusterWebhook) ValidateCreate(ctx context.Context, cluster *chv1.ClickHouseCluster) (admission.Warnings, error) {
 	warns, errs := w.validateImpl(cluster)
 
+	conflict, err := w.findKeeperRootConflict(ctx, cluster)
+	if err != nil {
+		errs = append(errs, err)
+	} else if conflict != "" {
+		errs = append(errs, errors.New(conflict))
+	}
+
+	if denied, err := w.checkKeeperNamespaceAccess(ctx, cluster); err != nil {
+		errs = append(errs, err)
+	} else if denied != "" {
+		errs = append(errs, errors.New(denied))
+	}
+
 	return warns, errors.Join(errs...)
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ClickHouseCluster.
-func (w *ClickHouseClusterWebhook) ValidateUpdate(_ context.Context, oldCluster, newCluster *chv1.ClickHouseCluster) (admission.Warnings, error) {
+func (w *ClickHouseClusterWebhook) ValidateUpdate(ctx context.Context, oldCluster, newCluster *chv1.ClickHouseCluster) (admission.Warnings, error) {
 	w.Log.Info("Validate update spec", "name", newCluster.Name, "namespace", newCluster.Namespace)
 
 	warns, errs := w.validateImpl(newCluster)
@@ -66,6 +86,30 @@ func (w *ClickHouseClusterWebhook) ValidateUpdate(_ context.Context, oldCluster,
 		warns = append(warns, "Decreasing the number of shards is a destructive operation. It removes shards with all their data.")
 	}
 
+	if oldCluster.KeeperClusterNamespacedName() != newCluster.KeeperClusterNamespacedName() ||
+		oldCluster.KeeperRoot() != newCluster.KeeperRoot() {
+		warns = append(warns, "Changing keeperClusterRef switches the cluster to another Keeper cluster or Keeper paths. "+
+			"Coordination data is not migrated.")
+	}
+
+	// Existing clusters may already share the root, they are not blocked to keep them manageable.
+	if conflict, err := w.findKeeperRootConflict(ctx, newCluster); err != nil {
+		errs = append(errs, err)
+	} else if conflict != "" {
+		warns = append(warns, conflict)
+	}
+
+	// Access may be revoked after the cluster is created, only switching to a denied Keeper cluster is rejected.
+	if denied, err := w.checkKeeperNamespaceAccess(ctx, newCluster); err != nil {
+		errs = append(errs, err)
+	} else if denied != "" {
+		if oldCluster.KeeperClusterNamespacedName() != newCluster.KeeperClusterNamespacedName() {
+			errs = append(errs, errors.New(denied))
+		} else {
+			warns = append(warns, denied)
+		}
+	}
+
 	if err := validateDataVolumeSpecChanges(
 		oldCluster.Spec.DataVolumeClaimSpec,
 		newCluster.Spec.DataVolumeClaimSpec,
@@ -89,14 +133,34 @@ func (w *ClickHouseClusterWebhook) validateImpl(obj *chv1.ClickHouseCluster) (ad
 		errs  []error
 	)
 
-	if obj.Spec.KeeperClusterRef == nil || obj.Spec.KeeperClusterRef.Name == "" {
-		errs = append(errs, errors.New("keeperClusterRef name must not be empty"))
+	switch external := obj.Spec.Coordination.External; {
+	case external != nil && obj.Spec.KeeperClusterRef != nil:
+		errs = append(errs, errors.New("keeperClusterRef and coordination.external are mutually exclusive"))
+	case external == nil && (obj.Spec.KeeperClusterRef == nil || obj.Spec.KeeperClusterRef.Name == ""):
+		errs = append(errs, errors.New("either keeperClusterRef name or coordination.external must be specified"))
+	}
+
+	if err := obj.Spec.Coordination.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("coordination: %w", err))
 	}
 
 	if err := obj.Spec.Settings.TLS.Validate(); err != nil {
 		errs = append(errs, err)
 	}
 
+	errs = append(errs, validateProtocols(obj.Spec.Settings)...)
+
+	if err := obj.Spec.Services.Cluster.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("services.cluster: %w", err))
+	}
+
+	if err := obj.Spec.Services.Shard.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("services.shard: %w", err))
+	}
+
+	errs = append(errs, validateExposure(obj.Spec)...)
+	warns = append(warns, exposureWarnings(obj.Spec)...)
+
 	volumeWarns, volumeErrs := validateVolumes(
 		obj.Spec.PodTemplate.Volumes,
 		obj.Spec.ContainerTemplate.VolumeMounts,
@@ -117,3 +181,117 @@ func (w *ClickHouseClusterWebhook) validateImpl(obj *chv1.ClickHouseCluster) (ad
 
 	return warns, errs
 }
+
+// findKeeperRootConflict returns the message describing another ClickHouse cluster using the same root
+// of the same KeeperCluster. Returns an empty message if there is no conflict.
+func (w *ClickHouseClusterWebhook) findKeeperRootConflict(ctx context.Context, cluster *chv1.ClickHouseCluster) (string, error) {
+	if w.Client == nil || cluster.Spec.KeeperClusterRef == nil {
+		return "", nil
+	}
+
+	var clusters chv1.ClickHouseClusterList
+	if err := w.Client.List(ctx, &clusters); err != nil {
+		return "", fmt.Errorf("list ClickHouse clusters: %w", err)
+	}
+
+	keeperName := cluster.KeeperClusterNamespacedName()
+	root := cluster.KeeperRoot()
+
+	for i := range clusters.Items {
+		other := &clusters.Items[i]
+		if other.NamespacedName() == cluster.NamespacedName() || other.Spec.KeeperClusterRef == nil ||
+			other.KeeperClusterNamespacedName() != keeperName || other.KeeperRoot() != root {
+			continue
+		}
+
+		return fmt.Sprintf("Keeper root %q of KeeperCluster %s is already used by ClickHouseCluster %s. "+
+			"Clusters sharing the root overwrite each other data, set coordination.root.",
+			root, keeperName, other.NamespacedName()), nil
+	}
+
+	return "", nil
+}
+
+// checkKeeperNamespaceAccess returns the message describing why the referenced KeeperCluster denies access
+// from the cluster namespace. Returns an empty message if access is allowed or the KeeperCluster does not exist yet.
+func (w *ClickHouseClusterWebhook) checkKeeperNamespaceAccess(ctx context.Context, cluster *chv1.ClickHouseCluster) (string, error) {
+	if w.Client == nil || cluster.Spec.KeeperClusterRef == nil || cluster.Spec.KeeperClusterRef.Name == "" {
+		return "", nil
+	}
+
+	var keeper chv1.KeeperCluster
+	if err := w.Client.Get(ctx, cluster.KeeperClusterNamespacedName(), &keeper); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("get keeper cluster: %w", err)
+	}
+
+	if keeper.AllowsNamespace(cluster.Namespace) {
+		return "", nil
+	}
+
+	return fmt.Sprintf("KeeperCluster %s does not allow ClickHouse clusters from namespace %q, add it to allowedNamespaces.",
+		keeper.NamespacedName(), cluster.Namespace), nil
+}
+
+// exposureWarnings reports the extra configuration required if Ingress or HTTPRoute routes to the httpSecure port.
+func exposureWarnings(spec chv1.ClickHouseClusterSpec) admission.Warnings {
+	if _, ok := spec.Settings.Protocols.EnabledPorts(spec.Settings.TLS)["http"]; ok {
+		return nil
+	}
+
+	var warns admission.Warnings
+
+	services := spec.Services
+	if services.Ingress.Enabled {
+		warns = append(warns, "services.ingress routes requests to the httpSecure port. "+
+			"The backend protocol annotation is set for ingress-nginx only, configure other controllers with services.ingress.annotations.")
+	}
+
+	if services.Gateway.Enabled && services.Gateway.TLSMode != chv1.GatewayTLSModePassthrough {
+		warns = append(warns, "services.gateway routes requests to the httpSecure port with BackendTLSPolicy. "+
+			"The server certificate must be valid for services.gateway.backendHostname.")
+	}
+
+	return warns
+}
+
+func validateProtocols(settings chv1.ClickHouseSettings) []error {
+	if err := settings.Protocols.Validate(settings.TLS); err != nil {
+		return []error{fmt.Errorf("settings.protocols: %w", err)}
+	}
+
+	var errs []error
+
+	ports := settings.Protocols.EnabledPorts(settings.TLS)
+	for _, name := range slices.Sorted(maps.Keys(ports)) {
+		if slices.Contains(internal.ReservedClickHousePorts, ports[name]) {
+			errs = append(errs, fmt.Errorf("settings.protocols: %s port %d is reserved", name, ports[name]))
+		}
+	}
+
+	return errs
+}
+
+func validateExposure(spec chv1.ClickHouseClusterSpec) []error {
+	var errs []error
+
+	services := spec.Services
+	if (services.Ingress.Enabled || services.Gateway.Enabled) && services.Cluster.Enabled != nil && !*services.Cluster.Enabled {
+		errs = append(errs, errors.New("services.ingress and services.gateway require services.cluster to be enabled"))
+	}
+
+	if err := services.Gateway.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("services.gateway: %w", err))
+	}
+
+	if services.Gateway.Enabled && services.Gateway.TLSMode == chv1.GatewayTLSModePassthrough {
+		if _, ok := spec.Settings.Protocols.EnabledPorts(spec.Settings.TLS)["httpSecure"]; !ok {
+			errs = append(errs, errors.New("services.gateway: Passthrough TLS mode requires httpSecure protocol to be enabled"))
+		}
+	}
+
+	return errs
+}
@@ -23,7 +23,7 @@ var _ = Describe("ClickHouseCluster Webhook", func() {
 					Name:      "test-default",
 				},
 				Spec: chv1.ClickHouseClusterSpec{
-					KeeperClusterRef: &corev1.LocalObjectReference{
+					KeeperClusterRef: &chv1.KeeperClusterReference{
 						Name: "some-keeper-cluster",
 					},
 				},
@@ -46,7 +46,7 @@ var _ = Describe("ClickHouseCluster Webhook", func() {
 					Name:      "test-default",
 				},
 				Spec: chv1.ClickHouseClusterSpec{
-					KeeperClusterRef: &corev1.LocalObjectReference{
+					KeeperClusterRef: &chv1.KeeperClusterReference{
 						Name: "some-keeper-cluster",
 					},
 					DataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
@@ -72,7 +72,7 @@ var _ = Describe("ClickHouseCluster Webhook", func() {
 				Name:      "test-validate",
 			},
 			Spec: chv1.ClickHouseClusterSpec{
-				KeeperClusterRef: &corev1.LocalObjectReference{
+				KeeperClusterRef: &chv1.KeeperClusterReference{
 					Name: "some-keeper-cluster",
 				},
 			},
@@ -105,6 +105,24 @@ var _ = Describe("ClickHouseCluster Webhook", func() {
 			Expect(err.Error()).To(ContainSubstring("serverCertSecret must be specified"))
 		})
 
+		It("Should require exactly one coordination service", func(ctx context.Context) {
+			By("Rejecting both keeperClusterRef and external coordination")
+
+			cluster := chCluster.DeepCopy()
+			cluster.Spec.Coordination.External = &chv1.ExternalKeeperSpec{Nodes: []string{"zookeeper:2181"}}
+			err := k8sClient.Create(ctx, cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mutually exclusive"))
+
+			By("Rejecting invalid external node")
+
+			cluster.Spec.KeeperClusterRef = nil
+			cluster.Spec.Coordination.External.Nodes = []string{"zookeeper"}
+			err = k8sClient.Create(ctx, cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid node"))
+		})
+
 		It("Should check default password fields if set", func(ctx context.Context) {
 			cluster := chCluster.DeepCopy()
 
@@ -234,5 +252,57 @@ var _ = Describe("ClickHouseCluster Webhook", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("cannot be removed"))
 		})
+
+		It("Should reject new cluster sharing the Keeper root", func(ctx context.Context) {
+			cluster := chCluster.DeepCopy()
+			cluster.Spec.Coordination.Root = "/tenants/shared"
+			Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+			deferCleanup(cluster)
+
+			By("Rejecting another cluster with the same root")
+
+			other := chCluster.DeepCopy()
+			other.Name = "test-validate-shared-root"
+			other.Spec.Coordination.Root = "/tenants/shared"
+			Eventually(func() error {
+				return k8sClient.Create(ctx, other.DeepCopy())
+			}).Should(MatchError(ContainSubstring("is already used by ClickHouseCluster default/test-validate")))
+
+			By("Accepting another cluster with the default root")
+
+			other.Spec.Coordination.Root = ""
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+			deferCleanup(other)
+		})
+
+		It("Should reject cluster using KeeperCluster that does not allow its namespace", func(ctx context.Context) {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "keeper-tenant"}}
+			Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+
+			keeper := &chv1.KeeperCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "shared-keeper",
+				},
+			}
+			Expect(k8sClient.Create(ctx, keeper)).To(Succeed())
+			deferCleanup(keeper)
+
+			cluster := chCluster.DeepCopy()
+			cluster.Namespace = namespace.Name
+			cluster.Spec.KeeperClusterRef = &chv1.KeeperClusterReference{Name: keeper.Name, Namespace: keeper.Namespace}
+			Eventually(func() error {
+				return k8sClient.Create(ctx, cluster.DeepCopy())
+			}).Should(MatchError(ContainSubstring("does not allow ClickHouse clusters from namespace")))
+
+			By("Accepting the cluster once the namespace is allowed")
+
+			keeper.Spec.AllowedNamespaces = []string{namespace.Name}
+			Expect(k8sClient.Update(ctx, keeper)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Create(ctx, cluster.DeepCopy())
+			}).Should(Succeed())
+			deferCleanup(cluster)
+		})
 	})
 })
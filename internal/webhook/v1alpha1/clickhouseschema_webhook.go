@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	chv1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+	"github.com/ClickHouse/clickhouse-operator/internal/controllerutil"
+)
+
+// ClickHouseSchemaWebhook implements a validating webhook for ClickHouseSchema.
+// +kubebuilder:webhook:path=/validate-clickhouse-com-v1alpha1-clickhouseschema,mutating=false,failurePolicy=ignore,sideEffects=None,groups=clickhouse.com,resources=clickhouseschemas,verbs=create;update,versions=v1alpha1,name=vclickhouseschema.kb.io,admissionReviewVersions=v1
+type ClickHouseSchemaWebhook struct {
+	Log controllerutil.Logger
+}
+
+var _ admission.Validator[*chv1.ClickHouseSchema] = &ClickHouseSchemaWebhook{}
+
+// SetupClickHouseSchemaWebhookWithManager registers the webhook for ClickHouseSchema in the manager.
+func SetupClickHouseSchemaWebhookWithManager(mgr ctrl.Manager, log controllerutil.Logger) error {
+	wh := &ClickHouseSchemaWebhook{
+		Log: log.Named("clickhouse-schema-webhook"),
+	}
+
+	err := ctrl.NewWebhookManagedBy(mgr, &chv1.ClickHouseSchema{}).
+		WithValidator(wh).
+		Complete()
+	if err != nil {
+		return fmt.Errorf("setup ClickHouseSchema webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (w *ClickHouseSchemaWebhook) ValidateCreate(_ context.Context, schema *chv1.ClickHouseSchema) (warnings admission.Warnings, err error) {
+	return nil, w.validateImpl(schema)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (w *ClickHouseSchemaWebhook) ValidateUpdate(_ context.Context, _, newSchema *chv1.ClickHouseSchema) (warnings admission.Warnings, err error) {
+	return nil, w.validateImpl(newSchema)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (w *ClickHouseSchemaWebhook) ValidateDelete(context.Context, *chv1.ClickHouseSchema) (warnings admission.Warnings, err error) {
+	return nil, nil
+}
+
+func (w *ClickHouseSchemaWebhook) validateImpl(schema *chv1.ClickHouseSchema) error {
+	w.Log.Info("Validating spec", "name", schema.Name, "namespace", schema.Namespace)
+
+	if err := schema.Spec.Validate(); err != nil {
+		return fmt.Errorf("invalid ClickHouseSchema spec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	chv1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
+)
+
+var _ = Describe("ClickHouseSchema Webhook", func() {
+	schema := &chv1.ClickHouseSchema{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-schema",
+		},
+		Spec: chv1.ClickHouseSchemaSpec{
+			ClusterRef: corev1.LocalObjectReference{Name: "sample"},
+			Objects: []chv1.ClickHouseSchemaObject{{
+				Kind:     chv1.SchemaObjectKindDatabase,
+				Database: "analytics",
+				Query:    "CREATE DATABASE IF NOT EXISTS analytics",
+			}},
+		},
+	}
+
+	It("Should accept valid schema", func(ctx context.Context) {
+		valid := schema.DeepCopy()
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		deferCleanup(valid)
+	})
+
+	It("Should reject queries not creating the declared object", func(ctx context.Context) {
+		invalid := schema.DeepCopy()
+		invalid.Spec.Objects[0].Query = "DROP DATABASE analytics"
+
+		err := k8sClient.Create(ctx, invalid)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("query must be a CREATE DATABASE statement"))
+	})
+
+	It("Should reject ON CLUSTER queries", func(ctx context.Context) {
+		invalid := schema.DeepCopy()
+		invalid.Spec.Objects[0].Query = "CREATE DATABASE analytics ON CLUSTER default"
+
+		err := k8sClient.Create(ctx, invalid)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("ON CLUSTER"))
+	})
+
+	It("Should reject invalid update", func(ctx context.Context) {
+		valid := schema.DeepCopy()
+		valid.Name = "test-schema-update"
+		Expect(k8sClient.Create(ctx, valid)).To(Succeed())
+		deferCleanup(valid)
+
+		valid.Spec.Objects = append(valid.Spec.Objects, valid.Spec.Objects[0])
+		err := k8sClient.Update(ctx, valid)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("declared more than once"))
+	})
+})
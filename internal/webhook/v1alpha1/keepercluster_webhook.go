@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -66,6 +67,10 @@ func (w *KeeperClusterWebhook) ValidateUpdate(_ context.Context, oldCluster, new
 		errs = append(errs, err)
 	}
 
+	if oldCluster.Spec.Bootstrap == nil && newCluster.Spec.Bootstrap != nil {
+		errs = append(errs, errors.New("bootstrap can be set only on cluster creation"))
+	}
+
 	return warns, errors.Join(errs...)
 }
 
@@ -96,5 +101,70 @@ func (w *KeeperClusterWebhook) validateImpl(obj *chv1.KeeperCluster) (admission.
 		errs = append(errs, err)
 	}
 
+	if err := obj.Spec.Settings.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("settings: %w", err))
+	}
+
+	if obj.Learners() > 0 && obj.Replicas() == 0 {
+		errs = append(errs, errors.New("learners require at least one voting replica"))
+	}
+
+	if bootstrap := obj.Spec.Bootstrap; bootstrap != nil {
+		if err := bootstrap.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("bootstrap: %w", err))
+		}
+
+		if obj.Spec.DataVolumeClaimSpec == nil {
+			errs = append(errs, errors.New("bootstrap requires dataVolumeClaimSpec to keep the imported data"))
+		}
+
+		if obj.Replicas() == 0 {
+			errs = append(errs, errors.New("bootstrap requires at least one voting replica"))
+		}
+	}
+
+	if obj.Spec.LeaderPlacement.PreferredZone != "" &&
+		(obj.Spec.PodTemplate.TopologyZoneKey == nil || *obj.Spec.PodTemplate.TopologyZoneKey == "") {
+		errs = append(errs, errors.New("leaderPlacement.preferredZone requires podTemplate.topologyZoneKey to be set"))
+	}
+
+	// Learners always have zero priority, so at least one voting replica must keep a non-zero one to become the leader.
+	zeroPriority := map[chv1.KeeperReplicaID]struct{}{}
+	for _, priority := range obj.Spec.LeaderPlacement.Priorities {
+		if priority.Priority == 0 {
+			zeroPriority[priority.ReplicaID] = struct{}{}
+		}
+	}
+
+	if voters := obj.Replicas(); voters > 0 && len(zeroPriority) >= int(voters) {
+		errs = append(errs, fmt.Errorf("leaderPlacement.priorities sets zero priority to %d replicas, "+
+			"at least one of %d voting replicas must be able to become the leader", len(zeroPriority), voters))
+	}
+
+	if allowList := obj.Spec.Settings.FourLetterWordAllowList; len(allowList) > 0 &&
+		!slices.Contains(allowList, chv1.AllFourLetterWords) {
+		if !slices.Contains(allowList, chv1.FourLetterWordRequestLeadership) {
+			warns = append(warns, fmt.Sprintf(".spec.settings.fourLetterWordAllowList does not include %q, "+
+				"the leader is restarted without leadership handoff during rolling updates", chv1.FourLetterWordRequestLeadership))
+		}
+
+		if !slices.Contains(allowList, chv1.FourLetterWordDirectories) {
+			warns = append(warns, fmt.Sprintf(".spec.settings.fourLetterWordAllowList does not include %q, "+
+				"data volume usage is monitored only if the operator reads kubelet volume stats", chv1.FourLetterWordDirectories))
+		}
+
+		if obj.Spec.Storage.CreateSnapshotOnThreshold && !slices.Contains(allowList, chv1.FourLetterWordCreateSnapshot) {
+			warns = append(warns, fmt.Sprintf(".spec.settings.fourLetterWordAllowList does not include %q, "+
+				"snapshots can not be scheduled on usage threshold", chv1.FourLetterWordCreateSnapshot))
+		}
+
+		for _, command := range []string{chv1.FourLetterWordLogInfo, chv1.FourLetterWordRecovery} {
+			if !slices.Contains(allowList, command) {
+				warns = append(warns, fmt.Sprintf(".spec.settings.fourLetterWordAllowList does not include %q, "+
+					"the lost quorum can not be recovered", command))
+			}
+		}
+	}
+
 	return warns, errs
 }
@@ -8,6 +8,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	chv1 "github.com/ClickHouse/clickhouse-operator/api/v1alpha1"
 )
@@ -103,6 +104,111 @@ var _ = Describe("KeeperCluster Webhook", func() {
 			Expect(err.Error()).To(ContainSubstring("serverCertSecret must be specified"))
 		})
 
+		It("Should check four letter word allow list includes mntr", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					Settings: chv1.KeeperSettings{
+						FourLetterWordAllowList: []string{"ruok"},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, &cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`must include "mntr"`))
+		})
+
+		It("Should warn about four letter words used by the operator", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					Settings: chv1.KeeperSettings{
+						FourLetterWordAllowList: []string{"mntr", "rqld", "dirs", "lgif"},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			deferCleanup(&cluster)
+
+			Expect(warnings).To(ContainElement(ContainSubstring(`does not include "rcvr"`)))
+			Expect(warnings).NotTo(ContainElement(ContainSubstring(`does not include "lgif"`)))
+			Expect(warnings).NotTo(ContainElement(ContainSubstring(`does not include "rqld"`)))
+		})
+
+		It("Should check learners have voting replicas", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					Replicas: ptr.To[int32](0),
+					Learners: ptr.To[int32](1),
+				},
+			}
+
+			err := k8sClient.Create(ctx, &cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("learners require at least one voting replica"))
+		})
+
+		It("Should reject zero priority of every voting replica", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					Replicas: ptr.To[int32](3),
+					Learners: ptr.To[int32](1),
+					LeaderPlacement: chv1.KeeperLeaderPlacementSpec{
+						Priorities: []chv1.KeeperReplicaPriority{
+							{ReplicaID: 0, Priority: 0},
+							{ReplicaID: 1, Priority: 0},
+							{ReplicaID: 2, Priority: 0},
+						},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, &cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("at least one of 3 voting replicas must be able to become the leader"))
+
+			By("Allowing a single voting replica with non-zero priority")
+			cluster.Spec.LeaderPlacement.Priorities[2].Priority = 1
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			deferCleanup(&cluster)
+		})
+
+		It("Should check topology zone key is set for preferred leader zone", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					LeaderPlacement: chv1.KeeperLeaderPlacementSpec{
+						PreferredZone: "us-east-1a",
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, &cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requires podTemplate.topologyZoneKey"))
+		})
+
+		It("Should check bootstrap has a single source and data volume", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					Bootstrap: &chv1.KeeperBootstrapSpec{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "zookeeper-data"},
+						ObjectStorage:         &chv1.KeeperBootstrapObjectStorage{URL: "s3://bucket/zookeeper"},
+					},
+				},
+			}
+
+			err := k8sClient.Create(ctx, &cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exactly one of persistentVolumeClaim or objectStorage"))
+			Expect(err.Error()).To(ContainSubstring("bootstrap requires dataVolumeClaimSpec"))
+		})
+
 		It("Should check that all volumes from volume mounts are exists", func(ctx context.Context) {
 			cluster := chv1.KeeperCluster{
 				ObjectMeta: meta,
@@ -221,5 +327,28 @@ var _ = Describe("KeeperCluster Webhook", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("cannot be removed"))
 		})
+
+		It("Should check that bootstrap cannot be added after creation", func(ctx context.Context) {
+			cluster := chv1.KeeperCluster{
+				ObjectMeta: meta,
+				Spec: chv1.KeeperClusterSpec{
+					DataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse("1Gi"),
+						},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, &cluster)).To(Succeed())
+			deferCleanup(&cluster)
+
+			cluster.Spec.Bootstrap = &chv1.KeeperBootstrapSpec{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "zookeeper-data"},
+			}
+
+			err := k8sClient.Update(ctx, &cluster)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("bootstrap can be set only on cluster creation"))
+		})
 	})
 })
@@ -101,6 +101,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(SetupKeeperWebhookWithManager(mgr, zapLogger)).To(Succeed())
 	Expect(SetupClickHouseWebhookWithManager(mgr, zapLogger)).To(Succeed())
+	Expect(SetupClickHouseSchemaWebhookWithManager(mgr, zapLogger)).To(Succeed())
 
 	// +kubebuilder:scaffold:webhook
 
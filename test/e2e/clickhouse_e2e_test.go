@@ -72,7 +72,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 						},
 					},
 					DataVolumeClaimSpec: &defaultStorage,
-					KeeperClusterRef: &corev1.LocalObjectReference{
+					KeeperClusterRef: &v1.KeeperClusterReference{
 						Name: keeper.Name,
 					},
 				},
@@ -127,7 +127,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 						},
 					},
 					DataVolumeClaimSpec: &defaultStorage,
-					KeeperClusterRef: &corev1.LocalObjectReference{
+					KeeperClusterRef: &v1.KeeperClusterReference{
 						Name: keeper.Name,
 					},
 				},
@@ -174,7 +174,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 				Spec: v1.ClickHouseClusterSpec{
 					Replicas:            ptr.To[int32](1),
 					DataVolumeClaimSpec: nil, // Diskless configuration
-					KeeperClusterRef: &corev1.LocalObjectReference{
+					KeeperClusterRef: &v1.KeeperClusterReference{
 						Name: keeper.Name,
 					},
 					PodTemplate: v1.PodTemplateSpec{
@@ -276,7 +276,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 				},
 				Spec: v1.ClickHouseClusterSpec{
 					Replicas: ptr.To[int32](2),
-					KeeperClusterRef: &corev1.LocalObjectReference{
+					KeeperClusterRef: &v1.KeeperClusterReference{
 						Name: keeperCR.Name,
 					},
 					ContainerTemplate: v1.ContainerTemplateSpec{
@@ -406,7 +406,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 			},
 			Spec: v1.ClickHouseClusterSpec{
 				Replicas: ptr.To[int32](2),
-				KeeperClusterRef: &corev1.LocalObjectReference{
+				KeeperClusterRef: &v1.KeeperClusterReference{
 					Name: keeperCR.Name,
 				},
 				ContainerTemplate: v1.ContainerTemplateSpec{
@@ -521,7 +521,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 			},
 			Spec: v1.ClickHouseClusterSpec{
 				Replicas: ptr.To[int32](2),
-				KeeperClusterRef: &corev1.LocalObjectReference{
+				KeeperClusterRef: &v1.KeeperClusterReference{
 					Name: keeperCR.Name,
 				},
 				PodTemplate: v1.PodTemplateSpec{
@@ -644,7 +644,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 			Spec: v1.ClickHouseClusterSpec{
 				Replicas:            ptr.To[int32](3),
 				DataVolumeClaimSpec: &defaultStorage,
-				KeeperClusterRef: &corev1.LocalObjectReference{
+				KeeperClusterRef: &v1.KeeperClusterReference{
 					Name: keeperName,
 				},
 				PodTemplate: v1.PodTemplateSpec{
@@ -761,7 +761,7 @@ var _ = Describe("ClickHouse controller", Label("clickhouse"), func() {
 			Spec: v1.ClickHouseClusterSpec{
 				Replicas: ptr.To[int32](2),
 				Shards:   ptr.To[int32](2),
-				KeeperClusterRef: &corev1.LocalObjectReference{
+				KeeperClusterRef: &v1.KeeperClusterReference{
 					Name: keeper.Name,
 				},
 				ContainerTemplate: v1.ContainerTemplateSpec{
@@ -8,7 +8,6 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
 	"k8s.io/utils/ptr"
@@ -56,7 +55,7 @@ var _ = Context("Compatibility", Label("compatibility"), func() {
 			Spec: v1.ClickHouseClusterSpec{
 				Replicas:            new(int32(3)),
 				DataVolumeClaimSpec: &defaultStorage,
-				KeeperClusterRef: &corev1.LocalObjectReference{
+				KeeperClusterRef: &v1.KeeperClusterReference{
 					Name: keeper.Name,
 				},
 				ContainerTemplate: v1.ContainerTemplateSpec{
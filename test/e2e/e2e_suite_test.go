@@ -192,7 +192,7 @@ var _ = BeforeSuite(func(ctx context.Context) {
 			return errors.New("unexpected success creating object, webhook not engaged yet")
 		}
 
-		if !strings.Contains(err.Error(), "spec.keeperClusterRef") {
+		if !strings.Contains(err.Error(), "keeperClusterRef") {
 			return fmt.Errorf("webhook not ready or different error: %w", err)
 		}
 